@@ -0,0 +1,527 @@
+// Package broker is a small embedded MQTT 3.1.1 broker, enough to run the MQTT
+// transport fully locally: QoS 0 to 2, retained messages, wills, keep alive
+// and topic wildcards. Sessions are always clean; nothing is kept for a client
+// once it disconnects. A subscriber that falls behind loses QoS 0 messages and
+// is disconnected rather than lose QoS 1 or 2 ones.
+package broker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// connectTimeout is how long a new connection has to send CONNECT
+const connectTimeout = 10 * time.Second
+
+// Outbound flow limits. A client with maxInflight QoS 1 or 2 messages it hasn't
+// acknowledged, or whose queue is full when a packet it must receive is sent, is
+// disconnected rather than holding up the publisher or losing those messages.
+const (
+	outboundQueue = 1024
+	maxInflight   = 1024
+)
+
+// dropLogInterval is how often QoS 0 messages dropped for a lagging client are
+// logged, as a count
+const dropLogInterval = 10 * time.Second
+
+// Message is an application message
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// Broker routes messages between MQTT clients
+type Broker struct {
+	host     string
+	port     int
+	listener net.Listener
+	clients  map[string]*client
+	retained map[string]Message
+	closed   bool
+	nextID   int
+	mu       sync.RWMutex
+}
+
+// NewBroker creates a broker that listens on host:port once started
+func NewBroker(host string, port int) *Broker {
+	return &Broker{
+		host:     host,
+		port:     port,
+		clients:  make(map[string]*client),
+		retained: make(map[string]Message),
+	}
+}
+
+// Start accepts connections until ctx is cancelled
+func (b *Broker) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", b.host, b.port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	b.mu.Lock()
+	b.listener = listener
+	b.mu.Unlock()
+
+	log.Printf("MQTT broker listening on %s", b.GetAddress())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+
+	<-ctx.Done()
+	return b.Shutdown()
+}
+
+// Shutdown stops accepting connections and disconnects every client without
+// publishing their wills
+func (b *Broker) Shutdown() error {
+	b.mu.Lock()
+	b.closed = true
+	listener := b.listener
+	clients := make([]*client, 0, len(b.clients))
+	for _, c := range b.clients {
+		clients = append(clients, c)
+	}
+	b.mu.Unlock()
+
+	for _, c := range clients {
+		c.close()
+	}
+	if listener != nil {
+		return listener.Close()
+	}
+	return nil
+}
+
+// GetAddress returns the broker URL
+func (b *Broker) GetAddress() string {
+	return fmt.Sprintf("tcp://%s:%d", b.host, b.port)
+}
+
+// GetClientCount returns the number of connected clients
+func (b *Broker) GetClientCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.clients)
+}
+
+// serve runs one client connection from CONNECT to disconnect
+func (b *Broker) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(connectTimeout))
+	p, err := readPacket(r)
+	if err != nil || p.kind != packetConnect {
+		return
+	}
+	req, err := decodeConnect(p.body)
+	if err != nil {
+		return
+	}
+	if !(req.protocol == "MQTT" && req.level == 4) && !(req.protocol == "MQIsdp" && req.level == 3) {
+		conn.Write(encodePacket(packetConnack, 0, []byte{0, connBadProtocolVersion}))
+		return
+	}
+	if req.clientID == "" && !req.cleanSession {
+		conn.Write(encodePacket(packetConnack, 0, []byte{0, connIdentifierRejected}))
+		return
+	}
+	if req.will != nil && (!validTopic(req.will.Topic) || req.will.QoS > 2) {
+		return
+	}
+
+	c := &client{
+		id:       req.clientID,
+		conn:     conn,
+		out:      make(chan []byte, outboundQueue),
+		done:     make(chan struct{}),
+		subs:     make(map[string]byte),
+		will:     req.will,
+		pending:  make(map[uint16]bool),
+		inflight: make(map[uint16]bool),
+	}
+	if !b.register(c) {
+		return
+	}
+	go c.writePump()
+	c.send(encodePacket(packetConnack, 0, []byte{0, connAccepted}))
+
+	graceful := b.readLoop(c, r, time.Duration(req.keepAlive)*time.Second)
+
+	b.unregister(c)
+	c.close()
+	b.mu.RLock()
+	closed := b.closed
+	b.mu.RUnlock()
+	if !graceful && c.will != nil && !closed {
+		log.Printf("MQTT client %s disconnected unexpectedly, publishing its will to %s", c.id, c.will.Topic)
+		b.publish(*c.will)
+	}
+}
+
+// register adds a client, naming it if it sent no ID and disconnecting any
+// other client with the same ID
+func (b *Broker) register(c *client) bool {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return false
+	}
+	if c.id == "" {
+		b.nextID++
+		c.id = fmt.Sprintf("synheart-broker-%d", b.nextID)
+	}
+	previous := b.clients[c.id]
+	b.clients[c.id] = c
+	count := len(b.clients)
+	b.mu.Unlock()
+
+	if previous != nil {
+		previous.close()
+	}
+	log.Printf("MQTT client %s connected from %s (total: %d)", c.id, c.conn.RemoteAddr(), count)
+	return true
+}
+
+func (b *Broker) unregister(c *client) {
+	b.mu.Lock()
+	if b.clients[c.id] == c {
+		delete(b.clients, c.id)
+	}
+	count := len(b.clients)
+	b.mu.Unlock()
+	log.Printf("MQTT client %s disconnected (total: %d)", c.id, count)
+}
+
+// readLoop handles a client's packets until it disconnects, reporting whether
+// it did so with DISCONNECT. A client silent for one and a half keep alive
+// periods is disconnected.
+func (b *Broker) readLoop(c *client, r *bufio.Reader, keepAlive time.Duration) bool {
+	for {
+		deadline := time.Time{}
+		if keepAlive > 0 {
+			deadline = time.Now().Add(keepAlive * 3 / 2)
+		}
+		c.conn.SetReadDeadline(deadline)
+
+		p, err := readPacket(r)
+		if err != nil {
+			return false
+		}
+		switch p.kind {
+		case packetPublish:
+			m, id, err := decodePublish(p)
+			if err != nil || !validTopic(m.Topic) {
+				return false
+			}
+			switch m.QoS {
+			case 0:
+				b.publish(m)
+			case 1:
+				b.publish(m)
+				c.send(encodeAck(packetPuback, 0, id))
+			case 2:
+				// Delivered on receipt; a resent PUBLISH before PUBREL is not
+				// delivered again
+				if !c.pending[id] {
+					c.pending[id] = true
+					b.publish(m)
+				}
+				c.send(encodeAck(packetPubrec, 0, id))
+			}
+		case packetPubrel:
+			id, err := (&decoder{data: p.body}).uint16()
+			if err != nil {
+				return false
+			}
+			delete(c.pending, id)
+			c.send(encodeAck(packetPubcomp, 0, id))
+		case packetPubrec:
+			// Outbound QoS 2 received; it stays in flight until PUBCOMP
+			id, err := (&decoder{data: p.body}).uint16()
+			if err != nil {
+				return false
+			}
+			c.send(encodeAck(packetPubrel, 0x02, id))
+		case packetPuback, packetPubcomp:
+			// Outbound delivery complete
+			id, err := (&decoder{data: p.body}).uint16()
+			if err != nil {
+				return false
+			}
+			c.acknowledge(id)
+		case packetSubscribe:
+			id, subs, err := decodeSubscribe(p.body, false)
+			if err != nil {
+				return false
+			}
+			b.subscribe(c, id, subs)
+		case packetUnsubscribe:
+			id, subs, err := decodeSubscribe(p.body, true)
+			if err != nil {
+				return false
+			}
+			b.mu.Lock()
+			for _, sub := range subs {
+				delete(c.subs, sub.filter)
+			}
+			b.mu.Unlock()
+			c.send(encodeAck(packetUnsuback, 0, id))
+		case packetPingreq:
+			c.send(encodePacket(packetPingresp, 0, nil))
+		case packetDisconnect:
+			return true
+		default:
+			// A second CONNECT, or a packet only servers send
+			return false
+		}
+	}
+}
+
+// subscribe adds subscriptions and sends the retained messages they match
+func (b *Broker) subscribe(c *client, id uint16, subs []subscription) {
+	codes := make([]byte, len(subs))
+	var retained []Message
+
+	b.mu.Lock()
+	for i, sub := range subs {
+		if sub.qos > 2 || !validFilter(sub.filter) {
+			codes[i] = 0x80
+			continue
+		}
+		c.subs[sub.filter] = sub.qos
+		codes[i] = sub.qos
+		for _, m := range b.retained {
+			if match(sub.filter, m.Topic) {
+				m.QoS = min(m.QoS, sub.qos)
+				retained = append(retained, m)
+			}
+		}
+	}
+	b.mu.Unlock()
+
+	body := append([]byte{byte(id >> 8), byte(id)}, codes...)
+	c.send(encodePacket(packetSuback, 0, body))
+
+	sort.SliceStable(retained, func(i, j int) bool { return retained[i].Topic < retained[j].Topic })
+	for _, m := range retained {
+		c.deliver(m, m.QoS)
+	}
+}
+
+// publish stores a retained message and sends a message to every client with
+// a matching subscription, once per client at the highest QoS it asked for
+// up to the message's own
+func (b *Broker) publish(m Message) {
+	type target struct {
+		client *client
+		qos    byte
+	}
+	var targets []target
+
+	b.mu.Lock()
+	if m.Retain {
+		if len(m.Payload) == 0 {
+			delete(b.retained, m.Topic)
+		} else {
+			b.retained[m.Topic] = m
+		}
+	}
+	for _, c := range b.clients {
+		matched := false
+		var qos byte
+		for filter, subQoS := range c.subs {
+			if match(filter, m.Topic) {
+				matched = true
+				qos = max(qos, subQoS)
+			}
+		}
+		if matched {
+			targets = append(targets, target{client: c, qos: min(qos, m.QoS)})
+		}
+	}
+	b.mu.Unlock()
+
+	// Retain is only set on messages sent for a new subscription
+	m.Retain = false
+	for _, t := range targets {
+		t.client.deliver(m, t.qos)
+	}
+}
+
+// client is a connected MQTT client
+type client struct {
+	id      string
+	conn    net.Conn
+	out     chan []byte
+	done    chan struct{}
+	once    sync.Once
+	subs    map[string]byte // topic filter to QoS, guarded by the broker's mu
+	will    *Message
+	pending map[uint16]bool // QoS 2 packet IDs awaiting PUBREL, read loop only
+
+	mu       sync.Mutex
+	inflight map[uint16]bool // outbound QoS 1 and 2 packet IDs awaiting PUBACK or PUBCOMP
+	lastID   uint16
+	dropped  int // QoS 0 messages dropped since the last log
+	loggedAt time.Time
+}
+
+// send queues a packet the client must receive: an acknowledgement or a QoS 1
+// or 2 message. A client too far behind to take it is disconnected, since
+// dropping the packet would break the protocol or the delivery guarantee.
+func (c *client) send(pkt []byte) {
+	select {
+	case c.out <- pkt:
+	case <-c.done:
+	default:
+		log.Printf("MQTT client %s is not keeping up, disconnecting it", c.id)
+		c.close()
+	}
+}
+
+// sendLossy queues a QoS 0 message, dropping it when the client has fallen
+// behind rather than holding up the publisher
+func (c *client) sendLossy(pkt []byte) {
+	select {
+	case c.out <- pkt:
+	case <-c.done:
+	default:
+		c.mu.Lock()
+		c.dropped++
+		if time.Since(c.loggedAt) >= dropLogInterval {
+			log.Printf("MQTT client %s is not keeping up, dropped %d QoS 0 message(s)", c.id, c.dropped)
+			c.dropped = 0
+			c.loggedAt = time.Now()
+		}
+		c.mu.Unlock()
+	}
+}
+
+// deliver sends a message at the given QoS. QoS 1 and 2 messages are tracked
+// until acknowledged. Sessions are always clean, so there is never a session
+// to resend unacknowledged messages into; a client that stops acknowledging
+// is disconnected once maxInflight messages are outstanding.
+func (c *client) deliver(m Message, qos byte) {
+	if qos == 0 {
+		c.sendLossy(encodePublish(m, 0, 0))
+		return
+	}
+	id, ok := c.track()
+	if !ok {
+		log.Printf("MQTT client %s has %d unacknowledged messages, disconnecting it", c.id, maxInflight)
+		c.close()
+		return
+	}
+	c.send(encodePublish(m, qos, id))
+}
+
+// track assigns a free packet ID to an outbound message and marks it in flight,
+// reporting false if the client already has maxInflight messages outstanding
+func (c *client) track() (uint16, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.inflight) >= maxInflight {
+		return 0, false
+	}
+	for {
+		// Packet IDs run from 1 to 65535
+		c.lastID++
+		if c.lastID == 0 {
+			c.lastID = 1
+		}
+		if !c.inflight[c.lastID] {
+			c.inflight[c.lastID] = true
+			return c.lastID, true
+		}
+	}
+}
+
+// acknowledge completes the delivery of an outbound message
+func (c *client) acknowledge(id uint16) {
+	c.mu.Lock()
+	delete(c.inflight, id)
+	c.mu.Unlock()
+}
+
+func (c *client) writePump() {
+	for {
+		select {
+		case pkt := <-c.out:
+			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if _, err := c.conn.Write(pkt); err != nil {
+				c.close()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// match reports whether a topic matches a filter, where + matches one level
+// and a trailing # any number of levels, including none. Wildcards at the
+// start don't match topics beginning with $.
+func match(filter, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	levels := strings.Split(topic, "/")
+	patterns := strings.Split(filter, "/")
+	for i, pattern := range patterns {
+		if pattern == "#" {
+			return true
+		}
+		if i >= len(levels) || (pattern != "+" && pattern != levels[i]) {
+			return false
+		}
+	}
+	return len(patterns) == len(levels)
+}
+
+// validTopic reports whether a topic can be published to
+func validTopic(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#\x00")
+}
+
+// validFilter reports whether a subscription filter is well formed: + and #
+// must fill a whole level, and # must be the last
+func validFilter(filter string) bool {
+	if filter == "" || strings.ContainsRune(filter, 0) {
+		return false
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.ContainsAny(level, "+#") && len(level) > 1 {
+			return false
+		}
+		if level == "#" && i != len(levels)-1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,283 @@
+package broker
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+func startBroker(t *testing.T, port int) *Broker {
+	t.Helper()
+	b := NewBroker("127.0.0.1", port)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go b.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+	return b
+}
+
+func connectClient(t *testing.T, b *Broker, id string) mqtt.Client {
+	t.Helper()
+	opts := mqtt.NewClientOptions().AddBroker(b.GetAddress()).SetClientID(id)
+	c := mqtt.NewClient(opts)
+	if token := c.Connect(); !token.WaitTimeout(time.Second) || token.Error() != nil {
+		t.Fatalf("failed to connect %s: %v", id, token.Error())
+	}
+	t.Cleanup(func() { c.Disconnect(100) })
+	return c
+}
+
+// subscribe collects the messages received on a filter
+func subscribe(t *testing.T, c mqtt.Client, filter string, qos byte) <-chan mqtt.Message {
+	t.Helper()
+	received := make(chan mqtt.Message, 16)
+	token := c.Subscribe(filter, qos, func(_ mqtt.Client, m mqtt.Message) { received <- m })
+	if !token.WaitTimeout(time.Second) || token.Error() != nil {
+		t.Fatalf("failed to subscribe to %s: %v", filter, token.Error())
+	}
+	return received
+}
+
+func expectMessage(t *testing.T, received <-chan mqtt.Message, topic, payload string) mqtt.Message {
+	t.Helper()
+	select {
+	case m := <-received:
+		if m.Topic() != topic || string(m.Payload()) != payload {
+			t.Errorf("expected %s %q, got %s %q", topic, payload, m.Topic(), m.Payload())
+		}
+		return m
+	case <-time.After(time.Second):
+		t.Fatalf("no message on %s", topic)
+		return nil
+	}
+}
+
+func expectNone(t *testing.T, received <-chan mqtt.Message) {
+	t.Helper()
+	select {
+	case m := <-received:
+		t.Errorf("unexpected message on %s: %q", m.Topic(), m.Payload())
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	b := startBroker(t, 19890)
+	sub := connectClient(t, b, "sub")
+	pub := connectClient(t, b, "pub")
+
+	hr := subscribe(t, sub, "synheart/+/+/ppg.hr_bpm", 0)
+	all := subscribe(t, sub, "synheart/run-1/#", 0)
+
+	for _, qos := range []byte{0, 1, 2} {
+		pub.Publish("synheart/run-1/watch/ppg.hr_bpm", qos, false, "72").Wait()
+		expectMessage(t, hr, "synheart/run-1/watch/ppg.hr_bpm", "72")
+		expectMessage(t, all, "synheart/run-1/watch/ppg.hr_bpm", "72")
+	}
+	pub.Publish("synheart/run-1/watch/accel.xyz_mps2", 1, false, "[0,0,9.8]").Wait()
+	expectMessage(t, all, "synheart/run-1/watch/accel.xyz_mps2", "[0,0,9.8]")
+	expectNone(t, hr)
+
+	if b.GetClientCount() != 2 {
+		t.Errorf("expected 2 clients, got %d", b.GetClientCount())
+	}
+}
+
+func TestBroker_Retained(t *testing.T) {
+	b := startBroker(t, 19891)
+	pub := connectClient(t, b, "pub")
+
+	pub.Publish("synheart/run-1/watch/ppg.hr_bpm", 1, true, "71").Wait()
+	pub.Publish("synheart/run-1/watch/ppg.hr_bpm", 1, true, "72").Wait()
+	pub.Publish("synheart/run-1/status", 1, true, "running").Wait()
+
+	sub := connectClient(t, b, "sub")
+	received := subscribe(t, sub, "synheart/run-1/+/ppg.hr_bpm", 1)
+	if m := expectMessage(t, received, "synheart/run-1/watch/ppg.hr_bpm", "72"); m != nil && !m.Retained() {
+		t.Error("expected the retained flag on the last value")
+	}
+	expectNone(t, received)
+
+	// Live messages are not flagged, and an empty retained message clears the topic
+	pub.Publish("synheart/run-1/watch/ppg.hr_bpm", 1, true, "73").Wait()
+	if m := expectMessage(t, received, "synheart/run-1/watch/ppg.hr_bpm", "73"); m != nil && m.Retained() {
+		t.Error("expected no retained flag on a live message")
+	}
+	pub.Publish("synheart/run-1/watch/ppg.hr_bpm", 1, true, "").Wait()
+	expectMessage(t, received, "synheart/run-1/watch/ppg.hr_bpm", "")
+
+	late := subscribe(t, connectClient(t, b, "late"), "synheart/#", 0)
+	expectMessage(t, late, "synheart/run-1/status", "running")
+	expectNone(t, late)
+}
+
+func TestBroker_Will(t *testing.T) {
+	b := startBroker(t, 19892)
+	sub := connectClient(t, b, "sub")
+	received := subscribe(t, sub, "synheart/+/status", 1)
+
+	// A client that disconnects cleanly leaves no will
+	opts := mqtt.NewClientOptions().AddBroker(b.GetAddress()).SetClientID("clean").
+		SetWill("synheart/run-1/status", "lost", 1, true)
+	clean := mqtt.NewClient(opts)
+	if token := clean.Connect(); !token.WaitTimeout(time.Second) || token.Error() != nil {
+		t.Fatalf("failed to connect: %v", token.Error())
+	}
+	clean.Disconnect(100)
+	expectNone(t, received)
+
+	// One whose connection drops has its will published
+	conn, err := net.Dial("tcp", "127.0.0.1:19892")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, 0x02|0x04|0x08|0x20, 0, 60) // clean session, will at QoS 1, retained
+	body = appendString(body, "dropped")
+	body = appendString(body, "synheart/run-2/status")
+	body = appendString(body, "lost")
+	conn.Write(encodePacket(packetConnect, 0, body))
+	ack := make([]byte, 4)
+	if _, err := conn.Read(ack); err != nil || ack[0] != packetConnack<<4 || ack[3] != connAccepted {
+		t.Fatalf("expected CONNACK, got %v (%v)", ack, err)
+	}
+	conn.Close()
+
+	expectMessage(t, received, "synheart/run-2/status", "lost")
+	late := subscribe(t, connectClient(t, b, "late"), "synheart/run-2/status", 0)
+	expectMessage(t, late, "synheart/run-2/status", "lost")
+}
+
+func TestBroker_RejectsBadProtocol(t *testing.T) {
+	startBroker(t, 19893)
+
+	conn, err := net.Dial("tcp", "127.0.0.1:19893")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	body := appendString(nil, "MQTT")
+	body = append(body, 5, 0x02, 0, 60)
+	body = appendString(body, "v5")
+	conn.Write(encodePacket(packetConnect, 0, body))
+
+	ack := make([]byte, 4)
+	if _, err := conn.Read(ack); err != nil || ack[3] != connBadProtocolVersion {
+		t.Errorf("expected an unacceptable protocol version CONNACK, got %v (%v)", ack, err)
+	}
+}
+
+// rawSubscriber connects with a bare TCP connection and subscribes to a filter,
+// so the test controls which packets are acknowledged
+func rawSubscriber(t *testing.T, b *Broker, id, filter string, qos byte) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(b.GetAddress(), "tcp://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	r := bufio.NewReader(conn)
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, 0x02, 0, 0)
+	body = appendString(body, id)
+	conn.Write(encodePacket(packetConnect, 0, body))
+	body = appendString([]byte{0, 1}, filter)
+	conn.Write(encodePacket(packetSubscribe, 0x02, append(body, qos)))
+	for _, kind := range []byte{packetConnack, packetSuback} {
+		if p, err := readPacket(r); err != nil || p.kind != kind {
+			t.Fatalf("expected packet type %d, got %d (%v)", kind, p.kind, err)
+		}
+	}
+	return conn, r
+}
+
+func TestBroker_Inflight(t *testing.T) {
+	b := startBroker(t, 19897)
+	acking, ackingReader := rawSubscriber(t, b, "acking", "synheart/#", 1)
+	_, silentReader := rawSubscriber(t, b, "silent", "synheart/#", 2)
+
+	// The acknowledging subscriber keeps up; the silent one is disconnected
+	// once maxInflight messages are outstanding
+	silentClosed := make(chan int)
+	go func() {
+		n := 0
+		for {
+			if _, err := readPacket(silentReader); err != nil {
+				silentClosed <- n
+				return
+			}
+			n++
+		}
+	}()
+	for i := 0; i <= maxInflight; i++ {
+		b.publish(Message{Topic: "synheart/run-1/status", Payload: []byte("running"), QoS: 2})
+		p, err := readPacket(ackingReader)
+		if err != nil || p.kind != packetPublish || p.flags>>1&0x03 != 1 {
+			t.Fatalf("expected a QoS 1 PUBLISH, got %+v (%v)", p, err)
+		}
+		_, id, _ := decodePublish(p)
+		acking.Write(encodeAck(packetPuback, 0, id))
+	}
+
+	select {
+	case n := <-silentClosed:
+		if n > maxInflight {
+			t.Errorf("expected at most %d messages before the disconnect, got %d", maxInflight, n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the silent subscriber to be disconnected")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if b.GetClientCount() != 1 {
+		t.Errorf("expected only the acknowledging subscriber to remain, got %d clients", b.GetClientCount())
+	}
+}
+
+func TestClient_SendLossy(t *testing.T) {
+	c := &client{id: "slow", out: make(chan []byte, 1), done: make(chan struct{})}
+	for i := 0; i < 10; i++ {
+		c.sendLossy([]byte{byte(i)})
+	}
+	// The first drop is logged; the rest are counted towards the next log
+	if len(c.out) != 1 || c.dropped != 8 || c.loggedAt.IsZero() {
+		t.Errorf("expected 1 queued and 8 drops pending a log, got %d and %d", len(c.out), c.dropped)
+	}
+	select {
+	case <-c.done:
+		t.Error("dropping QoS 0 messages must not disconnect the client")
+	default:
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"synheart/run/watch/ppg.hr_bpm", "synheart/run/watch/ppg.hr_bpm", true},
+		{"synheart/+/+/ppg.hr_bpm", "synheart/run/watch/ppg.hr_bpm", true},
+		{"synheart/+/ppg.hr_bpm", "synheart/run/watch/ppg.hr_bpm", false},
+		{"synheart/#", "synheart/run/watch/ppg.hr_bpm", true},
+		{"synheart/#", "synheart", true},
+		{"synheart/run/+", "synheart/run", false},
+		{"#", "$SYS/uptime", false},
+		{"$SYS/#", "$SYS/uptime", true},
+	}
+	for _, tt := range tests {
+		if got := match(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("match(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+
+	for filter, want := range map[string]bool{"a/+/b": true, "a/#": true, "#": true, "a/b+": false, "a/#/b": false, "": false} {
+		if got := validFilter(filter); got != want {
+			t.Errorf("validFilter(%q) = %v, want %v", filter, got, want)
+		}
+	}
+}
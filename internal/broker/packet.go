@@ -0,0 +1,265 @@
+package broker
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MQTT 3.1.1 control packet types
+const (
+	packetConnect     = 1
+	packetConnack     = 2
+	packetPublish     = 3
+	packetPuback      = 4
+	packetPubrec      = 5
+	packetPubrel      = 6
+	packetPubcomp     = 7
+	packetSubscribe   = 8
+	packetSuback      = 9
+	packetUnsubscribe = 10
+	packetUnsuback    = 11
+	packetPingreq     = 12
+	packetPingresp    = 13
+	packetDisconnect  = 14
+)
+
+// CONNACK return codes
+const (
+	connAccepted           = 0
+	connBadProtocolVersion = 1
+	connIdentifierRejected = 2
+)
+
+// maxPacketSize bounds the remaining length the broker accepts, well below the
+// protocol's 256MB so a corrupt length can't become a huge allocation
+const maxPacketSize = 16 << 20
+
+var errMalformed = errors.New("malformed packet")
+
+// packet is a control packet split into its fixed header and body
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	var length, shift int
+	for i := 0; ; i++ {
+		if i == 4 {
+			return packet{}, fmt.Errorf("%w: remaining length exceeds four bytes", errMalformed)
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length |= int(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if length > maxPacketSize {
+		return packet{}, fmt.Errorf("packet of %d bytes exceeds the %d byte limit", length, maxPacketSize)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+// encodePacket adds the fixed header to a packet body
+func encodePacket(kind, flags byte, body []byte) []byte {
+	out := make([]byte, 0, len(body)+5)
+	out = append(out, kind<<4|flags)
+	length := len(body)
+	for {
+		b := byte(length & 0x7f)
+		length >>= 7
+		if length > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(out, body...)
+}
+
+// encodeAck encodes the packets whose body is just a packet identifier
+func encodeAck(kind, flags byte, id uint16) []byte {
+	return encodePacket(kind, flags, binary.BigEndian.AppendUint16(nil, id))
+}
+
+func encodePublish(m Message, qos byte, id uint16) []byte {
+	flags := qos << 1
+	if m.Retain {
+		flags |= 0x01
+	}
+	body := appendString(nil, m.Topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	return encodePacket(packetPublish, flags, append(body, m.Payload...))
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// decoder reads the fields of a packet body
+type decoder struct {
+	data []byte
+}
+
+func (d *decoder) byte() (byte, error) {
+	if len(d.data) < 1 {
+		return 0, errMalformed
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b, nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	if len(d.data) < 2 {
+		return 0, errMalformed
+	}
+	v := binary.BigEndian.Uint16(d.data)
+	d.data = d.data[2:]
+	return v, nil
+}
+
+// bytes reads a field prefixed with its two-byte length
+func (d *decoder) bytes() ([]byte, error) {
+	n, err := d.uint16()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.data) < int(n) {
+		return nil, errMalformed
+	}
+	b := d.data[:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+func (d *decoder) string() (string, error) {
+	b, err := d.bytes()
+	return string(b), err
+}
+
+// connect is a decoded CONNECT packet
+type connect struct {
+	protocol     string
+	level        byte
+	cleanSession bool
+	keepAlive    uint16
+	clientID     string
+	will         *Message
+}
+
+func decodeConnect(body []byte) (connect, error) {
+	var c connect
+	d := decoder{data: body}
+	var err error
+	if c.protocol, err = d.string(); err != nil {
+		return c, err
+	}
+	if c.level, err = d.byte(); err != nil {
+		return c, err
+	}
+	flags, err := d.byte()
+	if err != nil {
+		return c, err
+	}
+	if c.keepAlive, err = d.uint16(); err != nil {
+		return c, err
+	}
+	c.cleanSession = flags&0x02 != 0
+	if c.clientID, err = d.string(); err != nil {
+		return c, err
+	}
+	if flags&0x04 != 0 {
+		topic, err := d.string()
+		if err != nil {
+			return c, err
+		}
+		payload, err := d.bytes()
+		if err != nil {
+			return c, err
+		}
+		c.will = &Message{
+			Topic:   topic,
+			Payload: append([]byte(nil), payload...),
+			QoS:     flags >> 3 & 0x03,
+			Retain:  flags&0x20 != 0,
+		}
+	}
+	// Username and password are accepted and ignored
+	return c, nil
+}
+
+// decodePublish returns the message and, for QoS 1 and 2, its packet identifier
+func decodePublish(p packet) (Message, uint16, error) {
+	m := Message{QoS: p.flags >> 1 & 0x03, Retain: p.flags&0x01 != 0}
+	if m.QoS > 2 {
+		return m, 0, fmt.Errorf("%w: QoS 3", errMalformed)
+	}
+	d := decoder{data: p.body}
+	var err error
+	if m.Topic, err = d.string(); err != nil {
+		return m, 0, err
+	}
+	var id uint16
+	if m.QoS > 0 {
+		if id, err = d.uint16(); err != nil {
+			return m, 0, err
+		}
+	}
+	m.Payload = d.data
+	return m, id, nil
+}
+
+// subscription is a topic filter and its requested QoS
+type subscription struct {
+	filter string
+	qos    byte
+}
+
+// decodeSubscribe returns the packet identifier and the requested
+// subscriptions, or with unsubscribe the filters to remove (with QoS 0)
+func decodeSubscribe(body []byte, unsubscribe bool) (uint16, []subscription, error) {
+	d := decoder{data: body}
+	id, err := d.uint16()
+	if err != nil {
+		return 0, nil, err
+	}
+	var subs []subscription
+	for len(d.data) > 0 {
+		var sub subscription
+		if sub.filter, err = d.string(); err != nil {
+			return 0, nil, err
+		}
+		if !unsubscribe {
+			if sub.qos, err = d.byte(); err != nil {
+				return 0, nil, err
+			}
+		}
+		subs = append(subs, sub)
+	}
+	if len(subs) == 0 {
+		return 0, nil, fmt.Errorf("%w: no topic filters", errMalformed)
+	}
+	return id, subs, nil
+}
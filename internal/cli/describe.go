@@ -3,15 +3,17 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/synheart/synheart-cli/internal/scenario"
 )
 
 var describeCmd = &cobra.Command{
-	Use:   "describe <scenario>",
+	Use:   "describe <scenario|file>",
 	Short: "Describe a scenario in detail",
-	Long:  `Shows detailed information about a scenario including signals, phases, and typical ranges.`,
+	Long:  `Shows detailed information about a scenario including signals, phases, and typical ranges. Scenarios are shown flattened: inherited signals are included and phase templates and repeats are expanded.`,
 	Aliases: []string{
 		"scenario",
 		"scen",
@@ -24,33 +26,46 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 	scenarioName := args[0]
 
 	// Load scenarios
-	registry := scenario.NewRegistry()
-	if err := registry.LoadFromDir(getScenarioDir()); err != nil {
-		return fmt.Errorf("failed to load scenarios: %w", err)
+	registry, err := loadScenarioRegistry()
+	if err != nil {
+		return err
 	}
+	warnScenarioFailures(registry)
 
 	// Get scenario
-	scen, err := registry.Get(scenarioName)
+	scen, err := registry.Resolve(scenarioName)
 	if err != nil {
 		return fmt.Errorf("scenario not found: %w", err)
 	}
 
 	if globalOpts.Format == "json" {
 		type outScenario struct {
-			Name        string                            `json:"name"`
-			Description string                            `json:"description"`
-			Duration    string                            `json:"duration"`
-			DefaultRate string                            `json:"default_rate"`
-			Signals     map[string]*scenario.SignalConfig `json:"signals"`
-			Phases      []scenario.Phase                  `json:"phases"`
+			Name         string                            `json:"name"`
+			Description  string                            `json:"description"`
+			Extends      string                            `json:"extends,omitempty"`
+			Includes     []string                          `json:"includes,omitempty"`
+			Duration     string                            `json:"duration"`
+			DefaultRate  string                            `json:"default_rate"`
+			Signals      map[string]*scenario.SignalConfig `json:"signals"`
+			Phases       []scenario.Phase                  `json:"phases"`
+			Sources      []scenario.SourceConfig           `json:"sources,omitempty"`
+			Events       []scenario.EventConfig            `json:"events,omitempty"`
+			Correlations []scenario.CorrelationRule        `json:"correlations"`
+			Faults       []scenario.FaultConfig            `json:"faults,omitempty"`
 		}
 		payload := outScenario{
-			Name:        scen.Name,
-			Description: scen.Description,
-			Duration:    scen.Duration,
-			DefaultRate: scen.DefaultRate,
-			Signals:     scen.Signals,
-			Phases:      scen.Phases,
+			Name:         scen.Name,
+			Description:  scen.Description,
+			Extends:      scen.Extends,
+			Includes:     scen.Includes,
+			Duration:     scen.Duration,
+			DefaultRate:  scen.DefaultRate,
+			Signals:      scen.Signals,
+			Phases:       scen.Phases,
+			Sources:      scen.Sources,
+			Events:       scen.Events,
+			Correlations: scen.CorrelationRules(),
+			Faults:       scen.Faults,
 		}
 		if ui != nil {
 			return ui.PrintJSON(payload)
@@ -65,12 +80,24 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 		ui.Header("Scenario")
 		ui.KV("Name", scen.Name)
 		ui.KV("Description", scen.Description)
+		if scen.Extends != "" {
+			ui.KV("Extends", scen.Extends)
+		}
+		if len(scen.Includes) > 0 {
+			ui.KV("Includes", strings.Join(scen.Includes, ", "))
+		}
 		ui.KV("Duration", scen.Duration)
 		ui.KV("Default rate", scen.DefaultRate)
 		ui.Println()
 	} else {
 		fmt.Fprintf(cmd.OutOrStdout(), "Scenario: %s\n", scen.Name)
 		fmt.Fprintf(cmd.OutOrStdout(), "Description: %s\n", scen.Description)
+		if scen.Extends != "" {
+			fmt.Fprintf(cmd.OutOrStdout(), "Extends: %s\n", scen.Extends)
+		}
+		if len(scen.Includes) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "Includes: %s\n", strings.Join(scen.Includes, ", "))
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "Duration: %s\n", scen.Duration)
 		fmt.Fprintf(cmd.OutOrStdout(), "Default Rate: %s\n\n", scen.DefaultRate)
 	}
@@ -81,7 +108,8 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Fprintln(cmd.OutOrStdout(), "Signals:")
 	}
-	for name, config := range scen.Signals {
+	for _, name := range sortedKeys(scen.Signals) {
+		config := scen.Signals[name]
 		fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", name)
 		if config.Baseline != nil {
 			fmt.Fprintf(cmd.OutOrStdout(), "    Baseline: %v\n", config.Baseline)
@@ -95,6 +123,36 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 		if config.Unit != "" {
 			fmt.Fprintf(cmd.OutOrStdout(), "    Unit: %s\n", config.Unit)
 		}
+		if config.Block > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "    Block: %d samples\n", config.Block)
+		}
+		if config.Morphology != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "    Morphology:%s\n", describeMorphology(config.Morphology))
+		}
+		if config.Markov != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "    Markov:%s\n", describeMarkov(config.Markov))
+		}
+	}
+
+	// Print sources
+	if len(scen.Sources) > 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "\nSources:")
+		for _, source := range scen.Sources {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s (%s", source.ID, source.Type)
+			if source.Side != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), ", %s", source.Side)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ")")
+			if len(source.Signals) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "    Signals: %s\n", strings.Join(source.Signals, ", "))
+			}
+			if source.Rate != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "    Rate: %s\n", source.Rate)
+			}
+			if source.Skew != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "    Skew: %s\n", source.Skew)
+			}
+		}
 	}
 
 	// Print phases
@@ -102,31 +160,169 @@ func runDescribe(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(cmd.OutOrStdout(), "\nPhases:")
 		for i, phase := range scen.Phases {
 			fmt.Fprintf(cmd.OutOrStdout(), "  %d. %s (duration: %s)\n", i+1, phase.Name, phase.Duration)
+			if phase.SleepStage != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "     Sleep stage: %s\n", phase.SleepStage)
+			}
 			if len(phase.Overrides) > 0 {
 				fmt.Fprintln(cmd.OutOrStdout(), "     Overrides:")
-				for signal, override := range phase.Overrides {
-					fmt.Fprintf(cmd.OutOrStdout(), "       %s:", signal)
-					if override.Add != 0 {
-						fmt.Fprintf(cmd.OutOrStdout(), " add=%.1f", override.Add)
-					}
-					if override.Multiply != 0 {
-						fmt.Fprintf(cmd.OutOrStdout(), " multiply=%.1f", override.Multiply)
-					}
-					if override.Value != "" {
-						fmt.Fprintf(cmd.OutOrStdout(), " value=%s", override.Value)
-					}
-					if override.Baseline != nil {
-						fmt.Fprintf(cmd.OutOrStdout(), " baseline=%v", override.Baseline)
-					}
-					if override.Noise != nil {
-						fmt.Fprintf(cmd.OutOrStdout(), " noise=%v", override.Noise)
-					}
-					fmt.Fprintln(cmd.OutOrStdout())
+				for _, signal := range sortedKeys(phase.Overrides) {
+					override := phase.Overrides[signal]
+					fmt.Fprintf(cmd.OutOrStdout(), "       %s:%s\n", signal, describeModifiers(override))
+				}
+			}
+			if len(phase.Faults) > 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "     Faults:")
+				for _, fault := range phase.Faults {
+					fmt.Fprintf(cmd.OutOrStdout(), "       %s\n", fault.Label())
 				}
 			}
 		}
 	}
 
+	// Print events
+	if len(scen.Events) > 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "\nEvents:")
+		for _, event := range scen.Events {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s, lasting %s\n", event.Name, event.Schedule(), event.Duration)
+			if len(event.Phases) > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "     Phases: %s\n", strings.Join(event.Phases, ", "))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "     Effects:")
+			for _, signal := range sortedKeys(event.Effects) {
+				fmt.Fprintf(cmd.OutOrStdout(), "       %s:%s\n", signal, describeModifiers(event.Effects[signal]))
+			}
+		}
+	}
+
+	// Print faults
+	if len(scen.Faults) > 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "\nFaults:")
+		for _, fault := range scen.Faults {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", fault.Label())
+		}
+	}
+
+	// Print correlations
+	if rules := scen.CorrelationRules(); len(rules) > 0 {
+		if scen.Correlations == nil {
+			fmt.Fprintln(cmd.OutOrStdout(), "\nCorrelations (default):")
+		} else if scen.IncludeDefaultCorrelations {
+			fmt.Fprintln(cmd.OutOrStdout(), "\nCorrelations (defaults included):")
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), "\nCorrelations:")
+		}
+		for _, rule := range rules {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s -> %s (%s)%s\n", rule.Label(), rule.Source, rule.Target, rule.Transform, describeCorrelation(rule))
+		}
+	}
+
 	fmt.Fprintln(cmd.OutOrStdout())
 	return nil
 }
+
+// describeCorrelation formats the parameters set on a correlation rule
+func describeCorrelation(rule scenario.CorrelationRule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, " coefficient=%g", rule.Coefficient)
+	if rule.Offset != 0 {
+		fmt.Fprintf(&b, " offset=%g", rule.Offset)
+	}
+	if rule.Transform == scenario.TransformThreshold {
+		fmt.Fprintf(&b, " threshold=%g", rule.Threshold)
+	}
+	if rule.Delay != "" {
+		fmt.Fprintf(&b, " delay=%s", rule.Delay)
+	}
+	if rule.Effect != "" {
+		fmt.Fprintf(&b, " effect=%s", rule.Effect)
+	}
+	for _, bound := range []struct {
+		name  string
+		value *float64
+	}{
+		{"effect_min", rule.EffectMin},
+		{"effect_max", rule.EffectMax},
+		{"min", rule.Min},
+		{"max", rule.Max},
+	} {
+		if bound.value != nil {
+			fmt.Fprintf(&b, " %s=%g", bound.name, *bound.value)
+		}
+	}
+	return b.String()
+}
+
+// sortedKeys returns the signal names of a config map in sorted order
+func sortedKeys(configs map[string]*scenario.SignalConfig) []string {
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describeModifiers formats the modifiers set on an override or event effect
+func describeModifiers(config *scenario.SignalConfig) string {
+	var b strings.Builder
+	if config.Add != 0 {
+		fmt.Fprintf(&b, " add=%.1f", config.Add)
+	}
+	if config.Multiply != 0 {
+		fmt.Fprintf(&b, " multiply=%.1f", config.Multiply)
+	}
+	if config.Value != "" {
+		fmt.Fprintf(&b, " value=%s", config.Value)
+	}
+	if config.Baseline != nil {
+		fmt.Fprintf(&b, " baseline=%v", config.Baseline)
+	}
+	if config.Noise != nil {
+		fmt.Fprintf(&b, " noise=%v", config.Noise)
+	}
+	if config.Ramp != "" {
+		fmt.Fprintf(&b, " ramp=%s", config.Ramp)
+	}
+	if config.RampToBaseline != "" {
+		fmt.Fprintf(&b, " ramp_to_baseline=%s", config.RampToBaseline)
+	}
+	if config.Morphology != nil {
+		fmt.Fprintf(&b, " morphology={%s}", strings.TrimSpace(describeMorphology(config.Morphology)))
+	}
+	if config.Markov != nil {
+		fmt.Fprintf(&b, " markov={%s}", strings.TrimSpace(describeMarkov(config.Markov)))
+	}
+	return b.String()
+}
+
+// describeMorphology formats the pulse shape fields that are set
+func describeMorphology(m *scenario.Morphology) string {
+	var b strings.Builder
+	for _, field := range []struct {
+		name  string
+		value float64
+	}{
+		{"amplitude", m.Amplitude},
+		{"dicrotic", m.Dicrotic},
+		{"notch", m.Notch},
+		{"wander", m.Wander},
+		{"motion", m.Motion},
+	} {
+		if field.value != 0 {
+			fmt.Fprintf(&b, " %s=%g", field.name, field.value)
+		}
+	}
+	return b.String()
+}
+
+// describeMarkov formats a state chain as each state's mean dwell
+func describeMarkov(m *scenario.Markov) string {
+	var b strings.Builder
+	for _, state := range m.States() {
+		fmt.Fprintf(&b, " %s=%s", state, m.Dwell[state])
+	}
+	if m.Initial != "" {
+		fmt.Fprintf(&b, " initial=%s", m.Initial)
+	}
+	return b.String()
+}
@@ -6,9 +6,9 @@ import (
 	"net"
 	"os"
 	"runtime"
+	"sort"
 
 	"github.com/spf13/cobra"
-	"github.com/synheart/synheart-cli/internal/scenario"
 )
 
 var (
@@ -37,6 +37,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		Arch         string   `json:"arch"`
 		ScenariosDir string   `json:"scenarios_dir"`
 		Scenarios    []string `json:"scenarios"`
+		Skipped      []string `json:"skipped_scenarios,omitempty"`
 		Port         int      `json:"port"`
 		Host         string   `json:"host"`
 		PortFree     bool     `json:"port_free"`
@@ -65,38 +66,51 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Check scenarios directory
+	// Check scenarios: built-ins are always available, the directory is optional
 	scenariosDir := getScenarioDir()
 	scenarios := []string(nil)
-	if _, err := os.Stat(scenariosDir); err == nil {
-		// Count scenarios
-		registry := scenario.NewRegistry()
-		if err := registry.LoadFromDir(scenariosDir); err == nil {
-			scenarios = registry.List()
+	skipped := []string(nil)
+	if registry, err := loadScenarioRegistry(); err == nil {
+		scenarios = registry.List()
+		sort.Strings(scenarios)
+		for _, failure := range registry.Failures() {
+			skipped = append(skipped, failure.Path+": "+oneLine(failure.Err))
 		}
-		if globalOpts.Format == "text" {
-			if ui != nil {
+	} else if globalOpts.Format == "text" {
+		if ui != nil {
+			ui.Errorf("failed to load scenarios: %v", err)
+		} else {
+			fmt.Fprintf(out, "Failed to load scenarios: %v\n", err)
+		}
+	}
+	if globalOpts.Format == "text" {
+		_, statErr := os.Stat(scenariosDir)
+		if ui != nil {
+			if statErr == nil {
 				ui.Successf("scenarios directory found: %s", scenariosDir)
-				if len(scenarios) > 0 {
-					ui.KV("Scenarios", scenarios)
-				}
-				ui.Println()
 			} else {
-				fmt.Fprintf(out, "Scenarios directory: %s\n", scenariosDir)
-				if len(scenarios) > 0 {
-					fmt.Fprintf(out, "Scenarios: %v\n", scenarios)
-				}
-				fmt.Fprintln(out)
+				ui.Warnf("scenarios directory not found: %s (using built-in scenarios)", scenariosDir)
 			}
-		}
-	} else {
-		if globalOpts.Format == "text" {
-			if ui != nil {
-				ui.Errorf("scenarios directory not found: %s", scenariosDir)
-				ui.Println()
+			if len(scenarios) > 0 {
+				ui.KV("Scenarios", scenarios)
+			}
+			for _, s := range skipped {
+				ui.Warnf("skipped %s", s)
+			}
+			ui.Println()
+		} else {
+			if statErr == nil {
+				fmt.Fprintf(out, "Scenarios directory: %s\n", scenariosDir)
 			} else {
-				fmt.Fprintf(out, "Scenarios directory missing: %s\n\n", scenariosDir)
+				fmt.Fprintf(out, "Scenarios directory missing: %s (using built-in scenarios)\n", scenariosDir)
+			}
+			if len(scenarios) > 0 {
+				fmt.Fprintf(out, "Scenarios: %v\n", scenarios)
 			}
+			for _, s := range skipped {
+				fmt.Fprintf(out, "Skipped %s\n", s)
+			}
+			fmt.Fprintln(out)
 		}
 	}
 
@@ -168,6 +182,7 @@ func runDoctor(cmd *cobra.Command, args []string) error {
 		Arch:         runtime.GOARCH,
 		ScenariosDir: scenariosDir,
 		Scenarios:    scenarios,
+		Skipped:      skipped,
 		Host:         doctorHost,
 		Port:         doctorPort,
 		PortFree:     portFree,
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	"github.com/synheart/synheart-cli/internal/flux"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// fluxFlags configures the Flux engine of start and record
+type fluxFlags struct {
+	baselineDays int
+	timezone     string
+	deviceID     string
+	load         string
+	save         string
+}
+
+func (f *fluxFlags) register(flags *pflag.FlagSet) {
+	flags.IntVar(&f.baselineDays, "flux-baseline-days", 14, "Days in the Flux processor's rolling baselines")
+	flags.StringVar(&f.timezone, "flux-timezone", "UTC", "IANA timezone Flux interprets vendor payloads in")
+	flags.StringVar(&f.deviceID, "flux-device-id", "", "Device ID in HSI provenance (default: the first source's ID)")
+	flags.StringVar(&f.load, "flux-baselines", "", "Load Flux baselines from a snapshot file before starting")
+	flags.StringVar(&f.save, "flux-save-baselines", "", "Write a snapshot of the Flux baselines to this file when the session ends")
+}
+
+// open creates the Flux engine and loads any baselines snapshot
+func (f *fluxFlags) open(ctx context.Context, sources []scenario.SourceConfig) (*flux.Engine, error) {
+	opts := flux.Options{
+		BaselineDays: f.baselineDays,
+		Timezone:     f.timezone,
+		DeviceID:     f.deviceID,
+	}
+	if opts.DeviceID == "" && len(sources) > 0 {
+		opts.DeviceID = sources[0].ID
+	}
+	if f.baselineDays <= 0 {
+		return nil, fmt.Errorf("invalid --flux-baseline-days %d: must be positive", f.baselineDays)
+	}
+
+	engine, err := flux.NewEngineWithOptions(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize flux engine: %w", err)
+	}
+	if f.load != "" {
+		snapshot, err := os.ReadFile(f.load)
+		if err == nil {
+			err = engine.LoadBaselines(ctx, string(snapshot))
+		}
+		if err != nil {
+			engine.Close(ctx)
+			return nil, fmt.Errorf("failed to load flux baselines from %s: %w", f.load, err)
+		}
+	}
+	return engine, nil
+}
+
+// saveBaselines writes the baselines snapshot if requested
+func (f *fluxFlags) saveBaselines(ctx context.Context, engine *flux.Engine) error {
+	if f.save == "" {
+		return nil
+	}
+	snapshot, err := engine.Baselines(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot flux baselines: %w", err)
+	}
+	if err := os.WriteFile(f.save, []byte(snapshot+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write flux baselines: %w", err)
+	}
+	return nil
+}
+
+// describe formats the Flux settings for startup banners
+func (f *fluxFlags) describe(engine *flux.Engine) string {
+	opts := engine.Options()
+	desc := fmt.Sprintf("%d day baselines, %s, device %s", opts.BaselineDays, opts.Timezone, opts.DeviceID)
+	if f.load != "" {
+		desc += ", baselines from " + f.load
+	}
+	return desc
+}
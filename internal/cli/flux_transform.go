@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/synheart/synheart-cli/internal/flux"
+)
+
+var (
+	fluxTransformVendor string
+	fluxTransformOut    string
+
+	fluxTransformFlags fluxFlags
+)
+
+var fluxCmd = &cobra.Command{
+	Use:   "flux",
+	Short: "Run the embedded Flux engine",
+	Long:  `Run the embedded Synheart Flux Wasm module directly, outside of mock sessions.`,
+}
+
+var fluxTransformCmd = &cobra.Command{
+	Use:   "transform [file|dir|-]...",
+	Short: "Transform vendor JSON into HSI",
+	Long: `Transforms Whoop and Garmin payloads into HSI records with the embedded Flux engine.
+
+Each file holds either one JSON payload or NDJSON, one payload per line.
+Directories are searched for .json, .ndjson and .jsonl files. With no
+arguments, or "-", NDJSON is read from stdin. HSI records are written as
+NDJSON to --out or stdout; payloads are transformed in order by one engine,
+so baselines build up across them.
+
+A payload that fails is reported with its file and line and the rest are
+still transformed. The summary goes to stderr (JSON with --format json) and
+the command exits non-zero if any payload failed.
+
+Examples:
+  synheart flux transform export.json
+  synheart flux transform exports/ --vendor garmin --out hsi.ndjson
+  cat payloads.ndjson | synheart flux transform --format json`,
+	RunE: runFluxTransform,
+}
+
+func init() {
+	fluxCmd.AddCommand(fluxTransformCmd)
+
+	fluxTransformCmd.Flags().StringVar(&fluxTransformVendor, "vendor", "auto", "Payload format: auto|"+strings.Join(flux.Vendors(), "|")+" (auto detects each payload)")
+	fluxTransformCmd.Flags().StringVar(&fluxTransformOut, "out", "", "Write HSI records to this file instead of stdout")
+	fluxTransformFlags.register(fluxTransformCmd.Flags())
+}
+
+// maxStdinPayload bounds one NDJSON line read from stdin
+const maxStdinPayload = 16 << 20
+
+// transformError is a payload that could not be transformed
+type transformError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// transformResult summarizes one input
+type transformResult struct {
+	File        string           `json:"file"`
+	Records     int              `json:"records"`
+	Transformed int              `json:"transformed"`
+	Failed      int              `json:"failed"`
+	Errors      []transformError `json:"errors"`
+}
+
+func runFluxTransform(cmd *cobra.Command, args []string) error {
+	if fluxTransformVendor != "auto" {
+		if err := flux.CheckVendor(fluxTransformVendor); err != nil {
+			return fmt.Errorf("invalid --vendor: %w", err)
+		}
+	}
+	inputs, err := transformInputs(args)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	engine, err := fluxTransformFlags.open(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer engine.Close(ctx)
+
+	out := cmd.OutOrStdout()
+	if fluxTransformOut != "" {
+		file, err := os.Create(fluxTransformOut)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	writer := bufio.NewWriter(out)
+
+	results := make([]transformResult, 0, len(inputs))
+	failed := 0
+	for _, input := range inputs {
+		result, err := transformInput(ctx, engine, input, cmd.InOrStdin(), writer)
+		if err != nil {
+			return err
+		}
+		failed += result.Failed
+		results = append(results, result)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write HSI records: %w", err)
+	}
+	if err := fluxTransformFlags.saveBaselines(ctx, engine); err != nil {
+		return err
+	}
+
+	summary := cmd.ErrOrStderr()
+	if globalOpts.Format == "json" {
+		enc := json.NewEncoder(summary)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			for _, e := range result.Errors {
+				fmt.Fprintf(summary, "error: %s:%d: %s\n", result.File, e.Line, e.Error)
+			}
+			fmt.Fprintf(summary, "%s: %d of %d payload(s) transformed\n", result.File, result.Transformed, result.Records)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d payload(s) failed to transform", failed)
+	}
+	return nil
+}
+
+// transformInput transforms the payloads of one file, or of stdin for "-",
+// writing HSI records to w
+func transformInput(ctx context.Context, engine *flux.Engine, input string, stdin io.Reader, w *bufio.Writer) (transformResult, error) {
+	result := transformResult{File: input, Errors: []transformError{}}
+	if input == "-" {
+		result.File = "<stdin>"
+		return result, transformStream(ctx, engine, stdin, w, &result)
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", input, err)
+	}
+	for _, p := range splitPayloads(data) {
+		if err := transformRecord(ctx, engine, p, w, &result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// transformStream transforms NDJSON as it arrives, flushing each HSI record
+// so the output can be piped into another command without waiting for EOF
+func transformStream(ctx context.Context, engine *flux.Engine, r io.Reader, w *bufio.Writer, result *transformResult) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStdinPayload)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		if err := transformRecord(ctx, engine, payload{line: line, data: data}, w, result); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to write HSI records: %w", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return nil
+}
+
+// transformRecord transforms one payload, recording a failure in result
+// rather than returning it
+func transformRecord(ctx context.Context, engine *flux.Engine, p payload, w io.Writer, result *transformResult) error {
+	result.Records++
+	hsi, err := transformPayload(ctx, engine, p.data)
+	if err != nil {
+		result.Failed++
+		result.Errors = append(result.Errors, transformError{Line: p.line, Error: err.Error()})
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", hsi); err != nil {
+		return fmt.Errorf("failed to write HSI records: %w", err)
+	}
+	result.Transformed++
+	return nil
+}
+
+// transformPayload runs one payload through Flux, detecting its vendor unless
+// --vendor names one, and compacts the HSI record onto a single line
+func transformPayload(ctx context.Context, engine *flux.Engine, payload []byte) ([]byte, error) {
+	vendor := fluxTransformVendor
+	if vendor == "auto" {
+		var err error
+		if vendor, err = flux.DetectVendor(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	hsi, err := engine.Transform(ctx, vendor, string(payload))
+	if err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(hsi)); err != nil {
+		return nil, fmt.Errorf("flux returned invalid JSON: %w", err)
+	}
+	return compact.Bytes(), nil
+}
+
+// payload is a JSON document and the line it starts on
+type payload struct {
+	line int
+	data []byte
+}
+
+// splitPayloads returns a file holding a single JSON document as one payload,
+// and anything else as NDJSON, one payload per non-blank line
+func splitPayloads(data []byte) []payload {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	if json.Valid(trimmed) {
+		line := 1 + bytes.Count(data[:bytes.Index(data, trimmed)], []byte("\n"))
+		return []payload{{line: line, data: trimmed}}
+	}
+
+	var payloads []payload
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		payloads = append(payloads, payload{line: i + 1, data: line})
+	}
+	return payloads
+}
+
+// transformInputs expands the given paths into payload files, reading stdin
+// when there are none
+func transformInputs(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var files []string
+	for _, path := range paths {
+		if path == "-" {
+			files = append(files, path)
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || (ext != ".json" && ext != ".ndjson" && ext != ".jsonl") {
+				continue
+			}
+			found = append(found, filepath.Join(path, entry.Name()))
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no payload files found")
+	}
+	return files, nil
+}
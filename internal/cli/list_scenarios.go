@@ -6,13 +6,12 @@ import (
 	"sort"
 
 	"github.com/spf13/cobra"
-	"github.com/synheart/synheart-cli/internal/scenario"
 )
 
 var listScenariosCmd = &cobra.Command{
 	Use:   "list-scenarios",
 	Short: "List available scenarios",
-	Long:  `Lists all built-in scenarios with their descriptions.`,
+	Long:  `Lists all available scenarios with their descriptions and where each was loaded from: built into the binary, or a file from ./scenarios, the scenarios directory next to the executable, or SYNHEART_SCENARIO_PATH.`,
 	Aliases: []string{
 		"scenarios",
 		"ls",
@@ -22,13 +21,14 @@ var listScenariosCmd = &cobra.Command{
 
 func runListScenarios(cmd *cobra.Command, args []string) error {
 	// Load scenarios
-	registry := scenario.NewRegistry()
-	if err := registry.LoadFromDir(getScenarioDir()); err != nil {
-		return fmt.Errorf("failed to load scenarios: %w", err)
+	registry, err := loadScenarioRegistry()
+	if err != nil {
+		return err
 	}
 
 	scenarios := registry.ListWithDescriptions()
-	if len(scenarios) == 0 {
+	failures := registry.Failures()
+	if len(scenarios) == 0 && len(failures) == 0 {
 		fmt.Fprintln(cmd.OutOrStdout(), "No scenarios found")
 		return nil
 	}
@@ -37,6 +37,8 @@ func runListScenarios(cmd *cobra.Command, args []string) error {
 		type row struct {
 			Name        string `json:"name"`
 			Description string `json:"description"`
+			Origin      string `json:"origin"`
+			Error       string `json:"error,omitempty"`
 		}
 		names := make([]string, 0, len(scenarios))
 		for name := range scenarios {
@@ -45,7 +47,10 @@ func runListScenarios(cmd *cobra.Command, args []string) error {
 		sort.Strings(names)
 		out := make([]row, 0, len(names))
 		for _, name := range names {
-			out = append(out, row{Name: name, Description: scenarios[name]})
+			out = append(out, row{Name: name, Description: scenarios[name], Origin: registry.Origin(name)})
+		}
+		for _, failure := range failures {
+			out = append(out, row{Name: failure.Name, Origin: failure.Path, Error: failure.Err.Error()})
 		}
 		// UI may be nil if called in tests; fall back to stdout encoder.
 		if ui != nil {
@@ -71,9 +76,27 @@ func runListScenarios(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(cmd.OutOrStdout())
 	}
 	for _, name := range names {
-		fmt.Fprintf(cmd.OutOrStdout(), "  %-20s %s\n", name, scenarios[name])
+		origin := "(" + registry.Origin(name) + ")"
+		if ui != nil {
+			origin = ui.dim(origin)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  %-20s %s %s\n", name, scenarios[name], origin)
 	}
 	fmt.Fprintln(cmd.OutOrStdout())
 
+	if len(failures) > 0 {
+		if ui != nil {
+			ui.Header("Skipped")
+			ui.Println()
+		} else {
+			fmt.Fprintln(cmd.OutOrStdout(), "Skipped:")
+			fmt.Fprintln(cmd.OutOrStdout())
+		}
+		for _, failure := range failures {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s\n", failure.Path, oneLine(failure.Err))
+		}
+		fmt.Fprintln(cmd.OutOrStdout())
+	}
+
 	return nil
 }
@@ -16,4 +16,5 @@ func init() {
 	mockCmd.AddCommand(replayCmd)
 	mockCmd.AddCommand(listScenariosCmd)
 	mockCmd.AddCommand(describeCmd)
+	mockCmd.AddCommand(validateCmd)
 }
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/flux"
+	"github.com/synheart/synheart-cli/internal/generator"
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/transport"
+)
+
+// outputModes selects which record streams a mock session emits
+type outputModes struct {
+	Events bool // raw per-signal HSI input events
+	Vendor bool // aggregated vendor payloads (Whoop, Garmin, ...)
+	HSI    bool // vendor payloads transformed by Flux
+}
+
+// parseOutputModes parses a comma-separated --output value such as "events,vendor".
+// An empty value keeps the legacy behaviour: vendor payloads, or Flux HSI when --flux is set.
+// An explicit value must include hsi when --flux is set, so Flux is never ignored.
+func parseOutputModes(value string, fluxEnabled bool) (outputModes, error) {
+	var modes outputModes
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		if fluxEnabled {
+			modes.HSI = true
+		} else {
+			modes.Vendor = true
+		}
+		return modes, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "events":
+			modes.Events = true
+		case "vendor":
+			modes.Vendor = true
+		case "hsi":
+			modes.HSI = true
+		case "all":
+			modes = outputModes{Events: true, Vendor: true, HSI: true}
+		default:
+			return modes, fmt.Errorf("invalid --output %q (expected: events|vendor|hsi|all, comma-separated)", part)
+		}
+	}
+	if fluxEnabled && !modes.HSI {
+		return modes, fmt.Errorf("invalid --output %q: --flux emits HSI, add hsi to the streams (e.g. %s,hsi)", value, value)
+	}
+	return modes, nil
+}
+
+// NeedsVendor reports whether vendor aggregation must run to satisfy the modes
+func (m outputModes) NeedsVendor() bool {
+	return m.Vendor || m.HSI
+}
+
+func (m outputModes) String() string {
+	var parts []string
+	if m.Events {
+		parts = append(parts, "events")
+	}
+	if m.Vendor {
+		parts = append(parts, "vendor")
+	}
+	if m.HSI {
+		parts = append(parts, "hsi")
+	}
+	return strings.Join(parts, ",")
+}
+
+// resolveVendor looks up the --vendor format, rejecting formats Flux cannot
+// transform when the modes include HSI
+func resolveVendor(name string, modes outputModes) (generator.VendorFormatter, error) {
+	formatter, err := generator.LookupVendor(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --vendor: %w", err)
+	}
+	if modes.HSI {
+		if err := flux.CheckVendor(name); err != nil {
+			return nil, fmt.Errorf("invalid --vendor with HSI output: %w", err)
+		}
+	}
+	return formatter, nil
+}
+
+// outputPipeline turns generator output into the final records for the transports
+type outputPipeline struct {
+	modes       outputModes
+	encoder     encoding.Encoder
+	fluxEngine  *flux.Engine
+	vendor      generator.VendorFormatter
+	fluxVerbose bool
+}
+
+// Run encodes raw events and transforms vendor payloads into out until both inputs
+// are closed or ctx is cancelled, then closes out. Either input may be nil. Inputs are
+// handled on a single goroutine, so records keep the order in which they were produced
+// when the generator sends on unbuffered channels.
+func (p *outputPipeline) Run(ctx context.Context, events <-chan models.Event, payloads <-chan []byte, out chan<- transport.Record) {
+	defer close(out)
+
+	for events != nil || payloads != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if !p.handleEvent(ctx, event, out) {
+				return
+			}
+		case payload, ok := <-payloads:
+			if !ok {
+				payloads = nil
+				continue
+			}
+			if !p.handlePayload(ctx, payload, out) {
+				return
+			}
+		}
+	}
+}
+
+// handleEvent encodes a raw event, returning false if ctx was cancelled
+func (p *outputPipeline) handleEvent(ctx context.Context, event models.Event, out chan<- transport.Record) bool {
+	data, err := p.encoder.Encode(event)
+	if err != nil {
+		log.Printf("Encoding error: %v", err)
+		return true
+	}
+	return sendRecord(ctx, out, transport.Record{Data: data, Event: &event})
+}
+
+// handlePayload forwards a vendor payload and/or its Flux transform, returning false if ctx was cancelled
+func (p *outputPipeline) handlePayload(ctx context.Context, payload []byte, out chan<- transport.Record) bool {
+	if p.fluxVerbose {
+		ui := NewUI(os.Stdout, os.Stderr, false, false, false)
+		ui.Printf("\n%s\n", ui.bold(fmt.Sprintf("--- Raw %s payload (%s) ---", strings.ToUpper(p.vendor.Name()), p.vendor.ContentType())))
+		ui.Printf("%s\n\n", string(payload))
+	}
+
+	if p.modes.Vendor {
+		if !sendRecord(ctx, out, transport.Record{Data: payload}) {
+			return false
+		}
+	}
+
+	if p.modes.HSI {
+		hsi, err := p.fluxEngine.Transform(ctx, p.vendor.Name(), string(payload))
+		if err != nil {
+			log.Printf("Flux error: %v", err)
+			return true
+		}
+		return sendRecord(ctx, out, transport.Record{Data: []byte(hsi)})
+	}
+	return true
+}
+
+func sendRecord(ctx context.Context, out chan<- transport.Record, record transport.Record) bool {
+	select {
+	case out <- record:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -30,7 +30,9 @@ var receiverCmd = &cobra.Command{
 from Synheart Life app over a local network.
 
 The server validates incoming payloads against the HSI Export Schema v1,
-handles idempotency, and outputs received data to stdout or files.
+handles idempotency, and outputs received data to stdout or files. Payloads
+may be sent as application/json, application/cbor or application/msgpack,
+with the same field names; they are always written out as JSON.
 
 Examples:
   synheart receiver
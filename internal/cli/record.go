@@ -6,14 +6,19 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/synheart/synheart-cli/internal/clock"
+	"github.com/synheart/synheart-cli/internal/encoding"
 	"github.com/synheart/synheart-cli/internal/flux"
 	"github.com/synheart/synheart-cli/internal/generator"
+	"github.com/synheart/synheart-cli/internal/models"
 	"github.com/synheart/synheart-cli/internal/recorder"
 	"github.com/synheart/synheart-cli/internal/scenario"
+	"github.com/synheart/synheart-cli/internal/transport"
 )
 
 var (
@@ -24,74 +29,157 @@ var (
 	recordRate     string
 	recordVendor   string
 	recordFlux     bool
+	recordOutput   string
+	recordVirtual  bool
+	recordEpoch    string
+	recordSources  []string
+	recordFaults   []string
+	recordWindow   string
+	recordBlock    int
+	recordEncoding string
+
+	recordFluxFlags fluxFlags
 )
 
 var recordCmd = &cobra.Command{
 	Use:   "record",
 	Short: "Record mock data to a file",
-	Long:  `Generate and record HSI records or raw wearable sensor signals in vendor-specific formats (Whoop/Garmin) to an NDJSON file.`,
+	Long:  `Generate and record HSI records or raw wearable sensor signals in vendor-specific formats (Whoop, Garmin, Oura, Fitbit, Apple Health, Polar) to an NDJSON file, or raw events to a length-delimited protobuf, CBOR or MessagePack file with --encoding.`,
 	RunE:  runRecord,
 }
 
 func init() {
-	recordCmd.Flags().StringVar(&recordScenario, "scenario", "baseline", "Scenario to run")
+	recordCmd.Flags().StringVar(&recordScenario, "scenario", "baseline", "Scenario name or path to a scenario YAML file")
 	recordCmd.Flags().StringVar(&recordDuration, "duration", "5m", "Duration to record")
 	recordCmd.Flags().StringVar(&recordOut, "out", "", "Output file (required)")
 	recordCmd.Flags().Int64Var(&recordSeed, "seed", time.Now().UnixNano(), "Random seed")
 	recordCmd.Flags().StringVar(&recordRate, "rate", "50hz", "Global tick rate")
-	recordCmd.Flags().StringVar(&recordVendor, "vendor", "whoop", "Vendor data format: whoop|garmin")
+	recordCmd.Flags().StringVar(&recordVendor, "vendor", "whoop", "Vendor data format: "+strings.Join(generator.VendorNames(), "|")+" (Flux transforms "+strings.Join(flux.Vendors(), " and ")+" only)")
+	recordCmd.Flags().StringVar(&recordWindow, "window", "1m", "Vendor payload window: a duration, phase or day, with optional update=..., partial=true|false, min_events=N")
 	recordCmd.Flags().BoolVar(&recordFlux, "flux", false, "Enable Synheart Flux Wasm transformation (defaults to raw vendor JSON)")
+	recordCmd.Flags().StringArrayVar(&recordSources, "source", nil, "Device to emit, repeatable: type=phone|wearable,id=...,side=left|right,signals=a+b,rate=...,skew=... (overrides scenario sources)")
+	recordCmd.Flags().IntVar(&recordBlock, "block", 0, "Batch accel, gyro and other signals at 10hz or faster into events of N samples (0 for one event per sample)")
+	recordCmd.Flags().StringArrayVar(&recordFaults, "fault", nil, "Inject a fault for the whole run, repeatable: type=dropout|stuck|saturate|duplicate|time_jump|skew|reorder,signals=a+b,every=...,duration=...,probability=...,offset=...,limit=high|low,delay=...,quality=...")
+	recordCmd.Flags().StringVar(&recordEncoding, "encoding", "json", "Recording encoding: json (NDJSON) | protobuf|cbor|msgpack (length-delimited events, requires --output events)")
+	recordCmd.Flags().StringVar(&recordOutput, "output", "", "Output streams: events|vendor|hsi|all, comma-separated (default: vendor, or hsi with --flux)")
+	recordCmd.Flags().BoolVar(&recordVirtual, "virtual-time", false, "Render the scenario on a virtual clock as fast as possible with reproducible output")
+	recordCmd.Flags().BoolVar(&recordVirtual, "as-fast-as-possible", false, "Alias for --virtual-time")
+	recordCmd.Flags().StringVar(&recordEpoch, "epoch", "2025-01-01T00:00:00Z", "Start timestamp (RFC3339) for --virtual-time recordings")
+	recordFluxFlags.register(recordCmd.Flags())
 	recordCmd.MarkFlagRequired("out")
 }
 
 func runRecord(cmd *cobra.Command, args []string) error {
-	registry := scenario.NewRegistry()
-	if err := registry.LoadFromDir(getScenarioDir()); err != nil {
-		return fmt.Errorf("failed to load scenarios: %w", err)
+	modes, err := parseOutputModes(recordOutput, recordFlux)
+	if err != nil {
+		return err
+	}
+	vendor, err := resolveVendor(recordVendor, modes)
+	if err != nil {
+		return err
+	}
+	recordFormat, err := encoding.ParseFormat(recordEncoding)
+	if err != nil {
+		return fmt.Errorf("invalid --encoding: %w", err)
+	}
+	if recordFormat.Binary() && (modes.Vendor || modes.HSI) {
+		return fmt.Errorf("invalid --encoding: %s recordings hold raw events only; use --output events", recordFormat)
+	}
+
+	registry, err := loadScenarioRegistry()
+	if err != nil {
+		return err
 	}
+	warnScenarioFailures(registry)
 
-	scen, err := registry.Get(recordScenario)
+	scen, err := registry.Resolve(recordScenario)
 	if err != nil {
 		return fmt.Errorf("failed to load scenario '%s': %w", recordScenario, err)
 	}
 	scen.Duration = recordDuration
 
-	scenarioEngine := scenario.NewEngine(scen)
+	sources, err := resolveSources(scen, recordSources)
+	if err != nil {
+		return err
+	}
 
-	tickRate, err := parseTickRate(recordRate)
+	// Virtual time renders against a manually advanced clock starting at the epoch
+	var virtualClock *clock.Virtual
+	var clk clock.Clock = clock.Real()
+	if recordVirtual {
+		epoch, err := time.Parse(time.RFC3339, recordEpoch)
+		if err != nil {
+			return fmt.Errorf("invalid --epoch %q: %w", recordEpoch, err)
+		}
+		virtualClock = clock.NewVirtual(epoch.UTC())
+		clk = virtualClock
+	}
+
+	scenarioEngine := scenario.NewEngineWithClock(scen, clk)
+
+	tickRate, err := scenario.ParseRate(recordRate)
 	if err != nil {
-		return fmt.Errorf("invalid rate: %w", err)
+		return fmt.Errorf("invalid --rate: %w", err)
+	}
+	if recordBlock < 0 {
+		return fmt.Errorf("invalid block size %d: must not be negative", recordBlock)
+	}
+	faults, err := parseFaults(recordFaults)
+	if err != nil {
+		return err
+	}
+	window, err := generator.ParseWindowSpec(recordWindow)
+	if err != nil {
+		return fmt.Errorf("invalid --window %q: %w", recordWindow, err)
 	}
 
 	genConfig := generator.Config{
-		Seed:        recordSeed,
-		DefaultRate: tickRate,
-		SourceType:  "wearable",
-		SourceID:    "mock-watch-01",
-		Vendor:      recordVendor,
+		Seed:          recordSeed,
+		DefaultRate:   tickRate,
+		SourceType:    "wearable",
+		SourceID:      "mock-watch-01",
+		Sources:       sources,
+		Vendor:        vendor,
+		Window:        window,
+		Block:         recordBlock,
+		Faults:        faults,
+		Clock:         clk,
+		Deterministic: recordVirtual,
 	}
 	gen := generator.NewGenerator(scenarioEngine, genConfig)
 
 	// Setup Flux Engine (Optional HSI Engine)
 	var fluxEngine *flux.Engine
-	if recordFlux {
+	if modes.HSI {
 		var err error
-		fluxEngine, err = flux.NewEngine(context.Background())
+		fluxEngine, err = recordFluxFlags.open(context.Background(), sources)
 		if err != nil {
-			return fmt.Errorf("failed to initialize flux engine: %w", err)
+			return err
 		}
 		defer fluxEngine.Close(context.Background())
 	}
 
-	rec, err := recorder.NewRecorder(recordOut)
+	rec, err := recorder.NewRecorderWithEncoding(recordOut, recordFormat)
 	if err != nil {
 		return fmt.Errorf("failed to create recorder: %w", err)
 	}
 	defer rec.Close()
 
-	// Channels
-	vendorPayloads := make(chan []byte, 100)
-	finalRecords := make(chan []byte, 100)
+	// Channels. Virtual time uses unbuffered inputs so the pipeline preserves
+	// generation order and the output is byte-identical for a given seed.
+	eventBuffer, payloadBuffer := 1000, 100
+	if recordVirtual {
+		eventBuffer, payloadBuffer = 0, 0
+	}
+	var rawEvents chan models.Event
+	if modes.Events {
+		rawEvents = make(chan models.Event, eventBuffer)
+	}
+	var vendorPayloads chan []byte
+	if modes.NeedsVendor() {
+		vendorPayloads = make(chan []byte, payloadBuffer)
+	}
+	finalRecords := make(chan transport.Record, 1000)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -105,9 +193,21 @@ func runRecord(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("📼 Recording Session Started\n\n")
 	fmt.Printf("Scenario:   %s\n", scen.Name)
+	fmt.Printf("Sources:    %s\n", describeSources(sources))
+	fmt.Printf("Faults:     %s\n", describeFaults(scen, faults))
 	fmt.Printf("Output:     %s\n", recordOut)
 	fmt.Printf("Vendor:     %s\n", recordVendor)
-	fmt.Printf("Flux:       %v\n\n", recordFlux)
+	fmt.Printf("Window:     %s\n", window)
+	fmt.Printf("Flux:       %v\n", modes.HSI)
+	if fluxEngine != nil {
+		fmt.Printf("Flux setup: %s\n", recordFluxFlags.describe(fluxEngine))
+	}
+	fmt.Printf("Streams:    %s\n", modes)
+	fmt.Printf("Encoding:   %s\n", recordFormat)
+	if recordVirtual {
+		fmt.Printf("Clock:      virtual from %s\n", virtualClock.Now().Format(time.RFC3339))
+	}
+	fmt.Println()
 
 	eventCount := 0
 	progressCallback := func() {
@@ -118,50 +218,49 @@ func runRecord(cmd *cobra.Command, args []string) error {
 	}
 
 	// Recording thread
+	recordDone := make(chan struct{})
 	go func() {
+		defer close(recordDone)
 		if err := rec.RecordFromChannel(ctx, finalRecords, progressCallback); err != nil && err != context.Canceled {
 			log.Printf("Recording error: %v", err)
 		}
 	}()
 
 	// Transformation Pipeline
-	go func() {
-		defer close(finalRecords)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case payload, ok := <-vendorPayloads:
-				if !ok {
-					return
-				}
-				if recordFlux {
-					var hsi string
-					var err error
-					if recordVendor == "garmin" {
-						hsi, err = fluxEngine.GarminToHSI(ctx, string(payload), "UTC", "mock-watch-01")
-					} else {
-						hsi, err = fluxEngine.WhoopToHSI(ctx, string(payload), "UTC", "mock-watch-01")
-					}
-					if err == nil {
-						finalRecords <- []byte(hsi)
-					}
-				} else {
-					finalRecords <- payload
-				}
-			}
-		}
-	}()
+	pipeline := &outputPipeline{
+		modes:      modes,
+		encoder:    encoding.NewEncoder(recordFormat),
+		fluxEngine: fluxEngine,
+		vendor:     vendor,
+	}
+	go pipeline.Run(ctx, rawEvents, vendorPayloads, finalRecords)
 
 	// Generation
-	ticker := time.NewTicker(tickRate)
-	defer ticker.Stop()
-	if err := gen.Generate(ctx, ticker, nil, vendorPayloads); err != nil && err != context.Canceled {
-		return fmt.Errorf("generator error: %w", err)
+	var genErr error
+	if recordVirtual {
+		genErr = gen.GenerateVirtual(ctx, virtualClock, tickRate, rawEvents, vendorPayloads)
+	} else {
+		ticker := time.NewTicker(tickRate)
+		defer ticker.Stop()
+		genErr = gen.Generate(ctx, ticker, rawEvents, vendorPayloads)
+	}
+	if genErr != nil && genErr != context.Canceled {
+		return fmt.Errorf("generator error: %w", genErr)
 	}
 
-	close(vendorPayloads)
-	time.Sleep(100 * time.Millisecond) // Let recording finish
+	if rawEvents != nil {
+		close(rawEvents)
+	}
+	if vendorPayloads != nil {
+		close(vendorPayloads)
+	}
+	<-recordDone // Let recording finish
+
+	if fluxEngine != nil {
+		if err := recordFluxFlags.saveBaselines(context.Background(), fluxEngine); err != nil {
+			return err
+		}
+	}
 
 	fmt.Printf("\n\n✅ Recording complete: %s\n", recordOut)
 	return nil
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/synheart/synheart-cli/internal/encoding"
 	"github.com/synheart/synheart-cli/internal/recorder"
 	"github.com/synheart/synheart-cli/internal/transport"
 )
@@ -20,16 +21,20 @@ var (
 	replayLoop  bool
 	replayHost  string
 	replayPort  int
+	replayEnc   string
 )
 
 var replayCmd = &cobra.Command{
 	Use:   "replay",
 	Short: "Replay recorded events",
-	Long: `Replay events from a previously recorded NDJSON file.
+	Long: `Replay events from a previously recorded NDJSON file, or a binary recording
+of length-delimited protobuf, CBOR or MessagePack events (record --encoding),
+detected from its content.
 
 Examples:
   synheart mock replay --in workout.ndjson
-  synheart mock replay --in test.ndjson --speed 2.0 --loop`,
+  synheart mock replay --in test.ndjson --speed 2.0 --loop
+  synheart mock replay --in test.ndjson --encoding protobuf`,
 	RunE: runReplay,
 }
 
@@ -39,10 +44,16 @@ func init() {
 	replayCmd.Flags().BoolVar(&replayLoop, "loop", false, "Loop playback continuously")
 	replayCmd.Flags().StringVar(&replayHost, "host", "127.0.0.1", "Host to bind to")
 	replayCmd.Flags().IntVar(&replayPort, "port", 8787, "Port to listen on")
+	replayCmd.Flags().StringVar(&replayEnc, "encoding", "json", "Wire encoding of raw events: json|protobuf|cbor|msgpack (clients can override it with a subprotocol)")
 	replayCmd.MarkFlagRequired("in")
 }
 
 func runReplay(cmd *cobra.Command, args []string) error {
+	wireEncoding, err := encoding.ParseFormat(replayEnc)
+	if err != nil {
+		return fmt.Errorf("invalid --encoding: %w", err)
+	}
+
 	// Create replayer
 	rep := recorder.NewReplayer(replayIn, replaySpeed, replayLoop)
 
@@ -51,12 +62,17 @@ func runReplay(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to read recording: %w", err)
 	}
+	fileFormat, err := rep.Format()
+	if err != nil {
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
 
 	// Create record channel
-	records := make(chan []byte, 100)
+	records := make(chan transport.Record, 100)
 
 	// Create WebSocket server
 	wsServer := transport.NewWebSocketServer(replayHost, replayPort)
+	wsServer.SetEncoding(wireEncoding)
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -82,10 +98,11 @@ func runReplay(cmd *cobra.Command, args []string) error {
 	// Give server time to start
 	time.Sleep(100 * time.Millisecond)
 
-	fmt.Printf("File:         %s\n", replayIn)
+	fmt.Printf("File:         %s (%s)\n", replayIn, fileFormat)
 	fmt.Printf("Records:      %d\n", count)
 	fmt.Printf("Speed:        %.1fx\n", replaySpeed)
 	fmt.Printf("Loop:         %v\n", replayLoop)
+	fmt.Printf("Encoding:     %s\n", describeEncoding(wireEncoding))
 	fmt.Printf("WebSocket:    %s\n\n", wsServer.GetAddress())
 
 	// Start broadcasting
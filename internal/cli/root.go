@@ -25,6 +25,7 @@ providing repeatable scenarios for QA and demos.`,
 synheart mock start
 synheart mock list-scenarios
 synheart receiver
+synheart flux transform export.json
 synheart doctor
 synheart version
 `),
@@ -75,6 +76,7 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(receiverCmd)
+	rootCmd.AddCommand(fluxCmd)
 }
 
 func initRootFlags() {
@@ -11,8 +11,12 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/synheart/synheart-cli/internal/broker"
+	"github.com/synheart/synheart-cli/internal/control"
+	"github.com/synheart/synheart-cli/internal/encoding"
 	"github.com/synheart/synheart-cli/internal/flux"
 	"github.com/synheart/synheart-cli/internal/generator"
+	"github.com/synheart/synheart-cli/internal/models"
 	"github.com/synheart/synheart-cli/internal/recorder"
 	"github.com/synheart/synheart-cli/internal/scenario"
 	"github.com/synheart/synheart-cli/internal/transport"
@@ -29,37 +33,68 @@ var (
 	startFlux        bool
 	startFluxVerbose bool
 	startVendor      string
+	startOutput      string
+	startSources     []string
+	startFaults      []string
+	startWindow      string
+	startBlock       int
+	startEncoding    string
+	startMQTT        string
+	startMQTTQoS     int
+	startMQTTRetain  bool
+
+	startFluxFlags fluxFlags
 )
 
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start generating and broadcasting sensor data",
-	Long:  `Starts generating raw sensor events, aggregates them into vendor-specific payloads, and optionally transforms them into HSI using the Flux engine.`,
+	Long:  `Starts generating raw sensor events, aggregates them into vendor-specific payloads, and optionally transforms them into HSI using the Flux engine. Raw per-signal HSI events can be streamed alongside or instead of the vendor payloads with --output.`,
 	RunE:  runStart,
 }
 
 func init() {
 	startCmd.Flags().StringVar(&startHost, "host", "127.0.0.1", "Host to bind to")
 	startCmd.Flags().IntVar(&startPort, "port", 8787, "Port to listen on")
-	startCmd.Flags().StringVar(&startScenario, "scenario", "baseline", "Scenario to run")
+	startCmd.Flags().StringVar(&startScenario, "scenario", "baseline", "Scenario name or path to a scenario YAML file")
 	startCmd.Flags().StringVar(&startDuration, "duration", "", "Duration to run (e.g., 5m, 1h)")
 	startCmd.Flags().StringVar(&startRate, "rate", "50hz", "Global tick rate")
 	startCmd.Flags().Int64Var(&startSeed, "seed", time.Now().UnixNano(), "Random seed for deterministic output")
 	startCmd.Flags().StringVar(&startOut, "out", "", "Record events to file")
 	startCmd.Flags().BoolVar(&startFlux, "flux", false, "Enable Synheart Flux Wasm transformation (defaults to raw vendor JSON)")
 	startCmd.Flags().BoolVar(&startFluxVerbose, "flux-verbose", false, "Log raw vendor data before Flux transformation")
-	startCmd.Flags().StringVar(&startVendor, "vendor", "whoop", "Vendor data format: whoop|garmin")
+	startCmd.Flags().StringVar(&startVendor, "vendor", "whoop", "Vendor data format: "+strings.Join(generator.VendorNames(), "|")+" (Flux transforms "+strings.Join(flux.Vendors(), " and ")+" only)")
+	startCmd.Flags().StringVar(&startWindow, "window", "1m", "Vendor payload window: a duration, phase or day, with optional update=..., partial=true|false, min_events=N")
+	startCmd.Flags().StringArrayVar(&startSources, "source", nil, "Device to emit, repeatable: type=phone|wearable,id=...,side=left|right,signals=a+b,rate=...,skew=... (overrides scenario sources)")
+	startCmd.Flags().IntVar(&startBlock, "block", 0, "Batch accel, gyro and other signals at 10hz or faster into events of N samples (0 for one event per sample)")
+	startCmd.Flags().StringArrayVar(&startFaults, "fault", nil, "Inject a fault for the whole run, repeatable: type=dropout|stuck|saturate|duplicate|time_jump|skew|reorder,signals=a+b,every=...,duration=...,probability=...,offset=...,limit=high|low,delay=...,quality=...")
+	startCmd.Flags().StringVar(&startEncoding, "encoding", "json", "Wire encoding of raw events on WebSocket, UDP and MQTT: json|protobuf|cbor|msgpack (WebSocket clients can override it with a subprotocol)")
+	startCmd.Flags().StringVar(&startMQTT, "mqtt", "", "Publish records to an MQTT broker: a broker URL such as tcp://localhost:1883, or \"embedded\" to run one on --port+3")
+	startCmd.Flags().IntVar(&startMQTTQoS, "mqtt-qos", 0, "MQTT QoS level: 0|1|2")
+	startCmd.Flags().BoolVar(&startMQTTRetain, "mqtt-retain", false, "Publish records as retained messages, so new subscribers get the last value of each topic")
+	startCmd.Flags().StringVar(&startOutput, "output", "", "Output streams: events|vendor|hsi|all, comma-separated (default: vendor, or hsi with --flux)")
+	startFluxFlags.register(startCmd.Flags())
 }
 
 func runStart(cmd *cobra.Command, args []string) error {
+	modes, err := parseOutputModes(startOutput, startFlux)
+	if err != nil {
+		return err
+	}
+	vendor, err := resolveVendor(startVendor, modes)
+	if err != nil {
+		return err
+	}
+
 	// Load scenarios
-	registry := scenario.NewRegistry()
-	if err := registry.LoadFromDir(getScenarioDir()); err != nil {
-		return fmt.Errorf("failed to load scenarios: %w", err)
+	registry, err := loadScenarioRegistry()
+	if err != nil {
+		return err
 	}
+	warnScenarioFailures(registry)
 
 	// Get scenario
-	scen, err := registry.Get(startScenario)
+	scen, err := registry.Resolve(startScenario)
 	if err != nil {
 		return fmt.Errorf("failed to load scenario '%s': %w", startScenario, err)
 	}
@@ -68,13 +103,33 @@ func runStart(cmd *cobra.Command, args []string) error {
 		scen.Duration = startDuration
 	}
 
+	sources, err := resolveSources(scen, startSources)
+	if err != nil {
+		return err
+	}
+
 	// Create scenario engine
 	scenarioEngine := scenario.NewEngine(scen)
 
 	// Parse rate
-	tickRate, err := parseTickRate(startRate)
+	tickRate, err := scenario.ParseRate(startRate)
+	if err != nil {
+		return fmt.Errorf("invalid --rate: %w", err)
+	}
+	if startBlock < 0 {
+		return fmt.Errorf("invalid block size %d: must not be negative", startBlock)
+	}
+	faults, err := parseFaults(startFaults)
+	if err != nil {
+		return err
+	}
+	window, err := generator.ParseWindowSpec(startWindow)
 	if err != nil {
-		return fmt.Errorf("invalid rate: %w", err)
+		return fmt.Errorf("invalid --window %q: %w", startWindow, err)
+	}
+	wireEncoding, err := encoding.ParseFormat(startEncoding)
+	if err != nil {
+		return fmt.Errorf("invalid --encoding: %w", err)
 	}
 
 	// Create generator
@@ -83,25 +138,59 @@ func runStart(cmd *cobra.Command, args []string) error {
 		DefaultRate: tickRate,
 		SourceType:  "wearable",
 		SourceID:    "mock-watch-01",
-		Vendor:      startVendor,
+		Sources:     sources,
+		Vendor:      vendor,
+		Window:      window,
+		Block:       startBlock,
+		Faults:      faults,
 	}
 	gen := generator.NewGenerator(scenarioEngine, genConfig)
 
+	var mqttPublisher *transport.MQTTPublisher
+	var mqttBroker *broker.Broker
+	if startMQTT != "" {
+		if startMQTTQoS < 0 || startMQTTQoS > 2 {
+			return fmt.Errorf("invalid --mqtt-qos %d (expected 0, 1 or 2)", startMQTTQoS)
+		}
+		brokerURL := startMQTT
+		if startMQTT == "embedded" {
+			mqttBroker = broker.NewBroker(startHost, startPort+3)
+			brokerURL = mqttBroker.GetAddress()
+		}
+		mqttPublisher, err = transport.NewMQTTPublisher(transport.MQTTConfig{
+			Broker: brokerURL,
+			RunID:  gen.GetRunID(),
+			QoS:    byte(startMQTTQoS),
+			Retain: startMQTTRetain,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid --mqtt: %w", err)
+		}
+		mqttPublisher.SetEncoding(wireEncoding)
+	}
+
 	// Setup Flux Engine (Optional HSI Engine)
 	var fluxEngine *flux.Engine
-	if startFlux {
+	if modes.HSI {
 		var err error
-		fluxEngine, err = flux.NewEngine(context.Background())
+		fluxEngine, err = startFluxFlags.open(context.Background(), sources)
 		if err != nil {
-			return fmt.Errorf("failed to initialize flux engine: %w", err)
+			return err
 		}
 		defer fluxEngine.Close(context.Background())
 		fmt.Println("✨ Flux Engine initialized (Embedded Wasm)")
 	}
 
 	// Create channels
-	vendorPayloads := make(chan []byte, 100)
-	broadcastRecords := make(chan []byte, 100)
+	var rawEvents chan models.Event
+	if modes.Events {
+		rawEvents = make(chan models.Event, 1000)
+	}
+	var vendorPayloads chan []byte
+	if modes.NeedsVendor() {
+		vendorPayloads = make(chan []byte, 100)
+	}
+	broadcastRecords := make(chan transport.Record, 1000)
 
 	// Create dispatcher for final output
 	dispatcher := transport.NewDispatcher(broadcastRecords, 100)
@@ -110,6 +199,18 @@ func runStart(cmd *cobra.Command, args []string) error {
 	wsServer := transport.NewWebSocketServer(startHost, startPort)
 	sse := transport.NewSSEServer(startHost, startPort+1)
 	udp := transport.NewUDPServer(startHost, startPort+2)
+	wsServer.SetEncoding(wireEncoding)
+	udp.SetEncoding(wireEncoding)
+
+	// Control plane shares the WebSocket server and drives the generator ticker
+	ticker := time.NewTicker(tickRate)
+	defer ticker.Stop()
+	controller := control.NewController(scenarioEngine, registry, ticker, tickRate)
+	if fluxEngine != nil {
+		controller.SetFlux(fluxEngine)
+	}
+	wsServer.Handle("/control", controller.Handler())
+	wsServer.Handle("/control/", controller.Handler())
 
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
@@ -140,21 +241,50 @@ func runStart(cmd *cobra.Command, args []string) error {
 			log.Printf("UDP error: %v", err)
 		}
 	}()
+	if mqttBroker != nil {
+		go func() {
+			if err := mqttBroker.Start(ctx); err != nil && err != context.Canceled {
+				log.Printf("MQTT broker error: %v", err)
+			}
+		}()
+	}
 
 	time.Sleep(200 * time.Millisecond)
 
+	if mqttPublisher != nil {
+		if err := mqttPublisher.Connect(); err != nil {
+			return fmt.Errorf("failed to start MQTT publisher: %w", err)
+		}
+		defer mqttPublisher.Close()
+	}
+
 	fmt.Printf("🚀 Synheart Mock Server Started\n\n")
 	fmt.Printf("Scenario:     %s\n", scen.Name)
+	fmt.Printf("Sources:      %s\n", describeSources(sources))
+	fmt.Printf("Faults:       %s\n", describeFaults(scen, faults))
 	fmt.Printf("WebSocket:    %s\n", wsServer.GetAddress())
 	fmt.Printf("SSE:          %s\n", sse.GetAddress())
 	fmt.Printf("UDP:          %s\n", udp.GetAddress())
+	if mqttPublisher != nil {
+		fmt.Printf("MQTT:         %s\n", describeMQTT(mqttPublisher.GetAddress(), mqttBroker != nil, gen.GetRunID(), startMQTTQoS, startMQTTRetain))
+	}
+	fmt.Printf("Control:      http://%s:%d/control\n", startHost, startPort)
 	fmt.Printf("Vendor:       %s\n", startVendor)
-	fmt.Printf("Flux Enabled: %v\n\n", startFlux)
+	fmt.Printf("Window:       %s\n", window)
+	fmt.Printf("Encoding:     %s\n", describeEncoding(wireEncoding))
+	fmt.Printf("Flux Enabled: %v\n", modes.HSI)
+	if fluxEngine != nil {
+		fmt.Printf("Flux Setup:   %s\n", startFluxFlags.describe(fluxEngine))
+	}
+	fmt.Printf("Streams:      %s\n\n", modes)
 
 	// Wire up transport broadcasting
 	go func() { wsServer.BroadcastFromChannel(ctx, dispatcher.Subscribe()) }()
 	go func() { sse.BroadcastFromChannel(ctx, dispatcher.Subscribe()) }()
 	go func() { udp.BroadcastFromChannel(ctx, dispatcher.Subscribe()) }()
+	if mqttPublisher != nil {
+		go func() { mqttPublisher.BroadcastFromChannel(ctx, dispatcher.Subscribe()) }()
+	}
 
 	if startOut != "" {
 		if rec, err := recorder.NewRecorder(startOut); err == nil {
@@ -166,52 +296,38 @@ func runStart(cmd *cobra.Command, args []string) error {
 
 	go dispatcher.Run(ctx)
 
-	// Transformation Pipeline: Generator -> Vendor Payloads -> (Flux) -> Final Records
+	// Transformation Pipeline: Generator -> Events / Vendor Payloads -> (Flux) -> Final Records
+	pipeline := &outputPipeline{
+		modes:       modes,
+		encoder:     encoding.NewEncoder(encoding.FormatJSON),
+		fluxEngine:  fluxEngine,
+		vendor:      vendor,
+		fluxVerbose: startFluxVerbose,
+	}
+	pipelineDone := make(chan struct{})
 	go func() {
-		defer close(broadcastRecords)
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case payload, ok := <-vendorPayloads:
-				if !ok {
-					return
-				}
-
-				if startFluxVerbose {
-					ui := NewUI(os.Stdout, os.Stderr, false, false, false)
-					ui.Printf("\n%s\n", ui.bold(fmt.Sprintf("--- Raw %s JSON ---", strings.ToUpper(startVendor))))
-					ui.Printf("%s\n\n", string(payload))
-				}
-
-				if startFlux {
-					var hsi string
-					var err error
-					if startVendor == "garmin" {
-						hsi, err = fluxEngine.GarminToHSI(ctx, string(payload), "UTC", "mock-watch-01")
-					} else {
-						hsi, err = fluxEngine.WhoopToHSI(ctx, string(payload), "UTC", "mock-watch-01")
-					}
-					if err == nil {
-						broadcastRecords <- []byte(hsi)
-					} else {
-						log.Printf("Flux error: %v", err)
-					}
-				} else {
-					broadcastRecords <- payload
-				}
-			}
-		}
+		defer close(pipelineDone)
+		pipeline.Run(ctx, rawEvents, vendorPayloads, broadcastRecords)
 	}()
 
 	// Start Generating
-	ticker := time.NewTicker(tickRate)
-	defer ticker.Stop()
-	if err := gen.Generate(ctx, ticker, nil, vendorPayloads); err != nil && err != context.Canceled {
+	if err := gen.Generate(ctx, ticker, rawEvents, vendorPayloads); err != nil && err != context.Canceled {
 		return fmt.Errorf("generator error: %w", err)
 	}
 
-	close(vendorPayloads)
+	if rawEvents != nil {
+		close(rawEvents)
+	}
+	if vendorPayloads != nil {
+		close(vendorPayloads)
+	}
+	<-pipelineDone
+
+	if fluxEngine != nil {
+		if err := startFluxFlags.saveBaselines(context.Background(), fluxEngine); err != nil {
+			return err
+		}
+	}
 
 	fmt.Println("\nShutdown complete")
 	return nil
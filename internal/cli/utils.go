@@ -4,7 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/generator"
+	"github.com/synheart/synheart-cli/internal/scenario"
+	"github.com/synheart/synheart-cli/internal/transport"
+	"github.com/synheart/synheart-cli/scenarios"
 )
 
 func getScenarioDir() string {
@@ -26,14 +32,172 @@ func getScenarioDir() string {
 	return "scenarios"
 }
 
-func parseTickRate(rate string) (time.Duration, error) {
-	var hz float64
-	_, err := fmt.Sscanf(rate, "%fhz", &hz)
-	if err != nil {
-		return 0, err
+// scenarioPathEnv lists extra scenario directories or files, separated like PATH.
+// Earlier entries take precedence.
+const scenarioPathEnv = "SYNHEART_SCENARIO_PATH"
+
+// scenarioSearchPath returns the scenario directories and files to load, in
+// increasing order of precedence: the scenarios directory next to the executable,
+// ./scenarios, then SYNHEART_SCENARIO_PATH entries (last to first).
+func scenarioSearchPath() []string {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			abs = path
+		}
+		if seen[abs] {
+			return
+		}
+		seen[abs] = true
+		paths = append(paths, path)
+	}
+
+	if exe, err := os.Executable(); err == nil {
+		dir := filepath.Join(filepath.Dir(exe), "scenarios")
+		if _, err := os.Stat(dir); err == nil {
+			add(dir)
+		}
+	}
+	if _, err := os.Stat("scenarios"); err == nil {
+		add("scenarios")
+	}
+
+	entries := filepath.SplitList(os.Getenv(scenarioPathEnv))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := strings.TrimSpace(entries[i])
+		if entry == "" {
+			continue
+		}
+		add(entry)
+	}
+
+	return paths
+}
+
+// loadScenarioRegistry loads the built-in scenarios and then every entry of the
+// scenario search path, so user scenarios override built-ins with the same name.
+// Scenarios referencing signals the generator doesn't know are rejected. Broken
+// or missing user paths are skipped and kept in the registry's Failures; only
+// requesting a scenario that failed to load is an error.
+func loadScenarioRegistry() (*scenario.Registry, error) {
+	registry := scenario.NewRegistry()
+	registry.SetKnownSignals(generator.SignalNames())
+	if err := registry.LoadFromEmbedded(scenarios.FS, "."); err != nil {
+		return nil, fmt.Errorf("failed to load built-in scenarios: %w", err)
+	}
+
+	for _, path := range scenarioSearchPath() {
+		registry.LoadFromPath(path) // failures are reported through the registry
+	}
+	return registry, nil
+}
+
+// warnScenarioFailures warns about the user scenario paths that were skipped
+func warnScenarioFailures(registry *scenario.Registry) {
+	if ui == nil {
+		return
+	}
+	for _, failure := range registry.Failures() {
+		ui.Warnf("skipped %s: %s", failure.Path, oneLine(failure.Err))
+	}
+}
+
+// oneLine joins the lines of a multi-line error for list output
+func oneLine(err error) string {
+	return strings.Join(strings.Fields(err.Error()), " ")
+}
+
+// resolveSources returns the devices for a session: --source flags take precedence
+// over the sources declared by the scenario. An empty result means the default
+// single wearable.
+func resolveSources(scen *scenario.Scenario, specs []string) ([]scenario.SourceConfig, error) {
+	sources := scen.Sources
+	if len(specs) > 0 {
+		sources = make([]scenario.SourceConfig, 0, len(specs))
+		for _, spec := range specs {
+			source, err := generator.ParseSourceSpec(spec)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --source %q: %w", spec, err)
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	if err := generator.ValidateSources(sources); err != nil {
+		return nil, fmt.Errorf("invalid sources: %w", err)
+	}
+	return sources, nil
+}
+
+// parseFaults parses --fault flag values
+func parseFaults(specs []string) ([]scenario.FaultConfig, error) {
+	faults := make([]scenario.FaultConfig, 0, len(specs))
+	for _, spec := range specs {
+		fault, err := generator.ParseFaultSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --fault %q: %w", spec, err)
+		}
+		faults = append(faults, fault)
+	}
+	return faults, nil
+}
+
+// describeFaults formats the faults of a run for startup banners
+func describeFaults(scen *scenario.Scenario, faults []scenario.FaultConfig) string {
+	all := append(append([]scenario.FaultConfig(nil), scen.Faults...), faults...)
+	phased := 0
+	for _, phase := range scen.Phases {
+		phased += len(phase.Faults)
+	}
+	if len(all) == 0 && phased == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(all)+1)
+	for _, fault := range all {
+		parts = append(parts, fault.Label())
+	}
+	if phased > 0 {
+		parts = append(parts, fmt.Sprintf("%d during phases", phased))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeSources formats sources for startup banners
+func describeSources(sources []scenario.SourceConfig) string {
+	if len(sources) == 0 {
+		return "mock-watch-01 (wearable)"
+	}
+	parts := make([]string, 0, len(sources))
+	for _, source := range sources {
+		kind := source.Type
+		if source.Side != "" {
+			kind += "/" + source.Side
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", source.ID, kind))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeEncoding formats the wire encoding for startup banners
+func describeEncoding(format encoding.Format) string {
+	if !format.Binary() {
+		return string(format)
+	}
+	return fmt.Sprintf("%s (raw events; vendor and HSI records stay text, SSE stays JSON)", format)
+}
+
+// describeMQTT formats the MQTT publisher for startup banners
+func describeMQTT(url string, embedded bool, runID string, qos int, retain bool) string {
+	parts := []string{url}
+	if embedded {
+		parts[0] += " (embedded broker)"
 	}
-	if hz <= 0 {
-		return 0, fmt.Errorf("rate must be positive")
+	parts = append(parts, fmt.Sprintf("QoS %d", qos))
+	if retain {
+		parts = append(parts, "retained")
 	}
-	return time.Duration(float64(time.Second) / hz), nil
+	parts = append(parts, fmt.Sprintf("topics %s/%s/<source>/<signal>", transport.MQTTTopicRoot, runID))
+	return strings.Join(parts, ", ")
 }
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/synheart/synheart-cli/internal/generator"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+var validateStrict bool
+
+var validateCmd = &cobra.Command{
+	Use:   "validate <file|dir>...",
+	Short: "Validate scenario files",
+	Long: `Checks scenario YAML files for unknown fields, unknown signal names, invalid rates
+and durations, and phase durations that don't add up to the scenario duration.
+Scenarios using extends, includes or phase templates are resolved against the
+available scenarios, reporting unknown references and cycles.
+Directories are searched for .yaml and .yml files. Exits non-zero if any errors
+are found (or warnings, with --strict). Use --format json for CI.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runValidate,
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Treat warnings as errors")
+}
+
+type validateResult struct {
+	File   string           `json:"file"`
+	Valid  bool             `json:"valid"`
+	Issues []scenario.Issue `json:"issues"`
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	files, err := scenarioFiles(args)
+	if err != nil {
+		return err
+	}
+
+	// Files are resolved against the registered scenarios so extends and includes
+	// can be checked; broken scenario files on the search path are skipped
+	registry, err := loadScenarioRegistry()
+	if err != nil {
+		return err
+	}
+	warnScenarioFailures(registry)
+
+	validator := scenario.NewValidator(generator.SignalNames())
+	allIssues := make([]scenario.Issues, len(files))
+	names := make([]string, len(files))
+
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			allIssues[i] = scenario.Issues{{File: file, Severity: scenario.SeverityError, Message: err.Error()}}
+			continue
+		}
+		scen, issues := validator.Validate(file, data)
+		allIssues[i] = issues
+		if len(issues.Errors()) == 0 && registry.LoadFromFile(file) == nil {
+			names[i] = scen.Name
+		}
+	}
+
+	results := make([]validateResult, 0, len(files))
+	failed := 0
+
+	for i, file := range files {
+		issues := allIssues[i]
+		if names[i] != "" {
+			if _, err := registry.Get(names[i]); err != nil {
+				issues = append(issues, scenario.Issue{File: file, Severity: scenario.SeverityError, Message: err.Error()})
+			}
+		}
+
+		valid := len(issues.Errors()) == 0
+		if validateStrict {
+			valid = len(issues) == 0
+		}
+		if !valid {
+			failed++
+		}
+		if issues == nil {
+			issues = scenario.Issues{}
+		}
+		results = append(results, validateResult{File: file, Valid: valid, Issues: issues})
+	}
+
+	if globalOpts.Format == "json" {
+		if ui != nil {
+			if err := ui.PrintJSON(results); err != nil {
+				return err
+			}
+		} else {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				return err
+			}
+		}
+	} else {
+		out := cmd.OutOrStdout()
+		for _, result := range results {
+			for _, issue := range result.Issues {
+				fmt.Fprintf(out, "%s: %s\n", issue.Severity, issue.Error())
+			}
+			if result.Valid {
+				fmt.Fprintf(out, "ok: %s\n", result.File)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scenario file(s) failed validation", failed, len(files))
+	}
+	return nil
+}
+
+// scenarioFiles expands the given paths into scenario YAML files
+func scenarioFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		var found []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || (!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml")) {
+				continue
+			}
+			found = append(found, filepath.Join(path, name))
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no scenario files found")
+	}
+	return files, nil
+}
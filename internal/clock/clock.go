@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time to the scenario engine and generators
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Real returns a clock backed by the system wall clock
+func Real() Clock {
+	return realClock{}
+}
+
+// Virtual is a manually advanced clock used to render scenarios faster than real time
+type Virtual struct {
+	now time.Time
+	mu  sync.RWMutex
+}
+
+// NewVirtual creates a virtual clock starting at the given epoch
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{now: start}
+}
+
+// Now returns the current virtual time
+func (v *Virtual) Now() time.Time {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.now
+}
+
+// Advance moves the virtual clock forward by d
+func (v *Virtual) Advance(d time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.now = v.now.Add(d)
+}
@@ -0,0 +1,311 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// maxBaselinesSize bounds a baselines snapshot posted to the control plane
+const maxBaselinesSize = 4 << 20
+
+// Controller exposes an HTTP control plane for steering a running mock session
+type Controller struct {
+	engine   *scenario.Engine
+	registry *scenario.Registry
+	ticker   *time.Ticker
+	rate     time.Duration
+	flux     FluxBaselines // nil unless Flux is enabled
+	mu       sync.Mutex
+}
+
+// FluxBaselines is the Flux processor state the control plane can snapshot,
+// load and reset
+type FluxBaselines interface {
+	Baselines(ctx context.Context) (string, error)
+	LoadBaselines(ctx context.Context, snapshot string) error
+	ResetBaselines(ctx context.Context) error
+}
+
+// State describes the current state of a running session
+type State struct {
+	Scenario            string  `json:"scenario"`
+	Phase               string  `json:"phase,omitempty"`
+	Paused              bool    `json:"paused"`
+	Complete            bool    `json:"complete"`
+	ElapsedSeconds      float64 `json:"elapsed_seconds"`
+	PhaseElapsedSeconds float64 `json:"phase_elapsed_seconds"`
+	Rate                string  `json:"rate"`
+}
+
+type scenarioRequest struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase,omitempty"`
+}
+
+type phaseRequest struct {
+	Name string `json:"name"`
+}
+
+type rateRequest struct {
+	Rate string `json:"rate"`
+}
+
+// NewController creates a controller driving the given engine and generator ticker
+func NewController(engine *scenario.Engine, registry *scenario.Registry, ticker *time.Ticker, rate time.Duration) *Controller {
+	return &Controller{
+		engine:   engine,
+		registry: registry,
+		ticker:   ticker,
+		rate:     rate,
+	}
+}
+
+// Handler returns an HTTP handler serving the /control/ endpoints
+func (c *Controller) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/control", c.handleState)
+	mux.HandleFunc("/control/state", c.handleState)
+	mux.HandleFunc("/control/pause", c.handlePause)
+	mux.HandleFunc("/control/resume", c.handleResume)
+	mux.HandleFunc("/control/scenario", c.handleScenario)
+	mux.HandleFunc("/control/phase", c.handlePhase)
+	mux.HandleFunc("/control/rate", c.handleRate)
+	mux.HandleFunc("/control/flux/baselines", c.handleFluxBaselines)
+	mux.HandleFunc("/control/flux/reset", c.handleFluxReset)
+	return mux
+}
+
+// SetFlux exposes the Flux processor's baselines through /control/flux/
+func (c *Controller) SetFlux(flux FluxBaselines) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flux = flux
+}
+
+// GetState returns the current session state
+func (c *Controller) GetState() State {
+	c.mu.Lock()
+	rate := c.rate
+	c.mu.Unlock()
+
+	state := State{
+		Scenario:            c.engine.GetScenario().Name,
+		Paused:              c.engine.IsPaused(),
+		Complete:            c.engine.IsComplete(),
+		ElapsedSeconds:      c.engine.GetElapsed().Seconds(),
+		PhaseElapsedSeconds: c.engine.GetPhaseElapsed().Seconds(),
+		Rate:                fmt.Sprintf("%ghz", float64(time.Second)/float64(rate)),
+	}
+	if phase := c.engine.GetCurrentPhase(); phase != nil {
+		state.Phase = phase.Name
+	}
+	return state
+}
+
+// SetRate changes the generator tick rate
+func (c *Controller) SetRate(rate time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rate = rate
+	c.ticker.Reset(rate)
+}
+
+func (c *Controller) handleState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	c.writeState(w)
+}
+
+func (c *Controller) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	c.engine.Pause()
+	c.writeState(w)
+}
+
+func (c *Controller) handleResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	c.engine.Resume()
+	c.writeState(w)
+}
+
+func (c *Controller) handleScenario(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req scenarioRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Name == "" {
+		c.writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	scen, err := c.registry.Get(req.Name)
+	if err != nil {
+		c.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if req.Phase != "" {
+		if _, err := scen.GetPhaseOffset(req.Phase); err != nil {
+			c.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+
+	c.engine.SetScenario(scen)
+	if req.Phase != "" {
+		if err := c.engine.JumpToPhase(req.Phase); err != nil {
+			c.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+	c.writeState(w)
+}
+
+func (c *Controller) handlePhase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req phaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Name == "" {
+		c.writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	if err := c.engine.JumpToPhase(req.Name); err != nil {
+		c.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	c.writeState(w)
+}
+
+func (c *Controller) handleRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req rateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+
+	rate, err := scenario.ParseRate(req.Rate)
+	if err != nil {
+		c.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.SetRate(rate)
+	c.writeState(w)
+}
+
+// handleFluxBaselines returns the baselines snapshot on GET and loads one on POST
+func (c *Controller) handleFluxBaselines(w http.ResponseWriter, r *http.Request) {
+	flux := c.fluxBaselines(w)
+	if flux == nil {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBaselinesSize))
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			c.writeError(w, status, err.Error())
+			return
+		}
+		if err := flux.LoadBaselines(r.Context(), string(body)); err != nil {
+			c.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	c.writeBaselines(w, r, flux)
+}
+
+func (c *Controller) handleFluxReset(w http.ResponseWriter, r *http.Request) {
+	flux := c.fluxBaselines(w)
+	if flux == nil {
+		return
+	}
+	if r.Method != http.MethodPost {
+		c.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if err := flux.ResetBaselines(r.Context()); err != nil {
+		c.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.writeBaselines(w, r, flux)
+}
+
+// fluxBaselines returns the Flux baselines, writing an error if Flux is not enabled
+func (c *Controller) fluxBaselines(w http.ResponseWriter) FluxBaselines {
+	c.mu.Lock()
+	flux := c.flux
+	c.mu.Unlock()
+	if flux == nil {
+		c.writeError(w, http.StatusNotFound, "flux is not enabled for this session")
+	}
+	return flux
+}
+
+func (c *Controller) writeBaselines(w http.ResponseWriter, r *http.Request, flux FluxBaselines) {
+	snapshot, err := flux.Baselines(r.Context())
+	if err != nil {
+		c.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, snapshot)
+}
+
+func (c *Controller) writeState(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(c.GetState())
+}
+
+func (c *Controller) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": message,
+	})
+}
@@ -0,0 +1,201 @@
+package control
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func newTestController(t *testing.T) *Controller {
+	t.Helper()
+
+	registry := scenario.NewRegistry()
+	registry.Add(&scenario.Scenario{
+		Name:     "first",
+		Duration: "10m",
+		Phases: []scenario.Phase{
+			{Name: "warmup", Duration: "2m"},
+			{Name: "spike", Duration: "1m"},
+		},
+	})
+	registry.Add(&scenario.Scenario{
+		Name:     "second",
+		Duration: "5m",
+		Phases: []scenario.Phase{
+			{Name: "rest", Duration: "1m"},
+			{Name: "run", Duration: "4m"},
+		},
+	})
+
+	first, _ := registry.Get("first")
+	ticker := time.NewTicker(time.Second)
+	t.Cleanup(ticker.Stop)
+
+	return NewController(scenario.NewEngine(first), registry, ticker, time.Second)
+}
+
+func doRequest(t *testing.T, c *Controller, method, path, body string) (*httptest.ResponseRecorder, State) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rr, req)
+
+	var state State
+	if rr.Code == http.StatusOK {
+		if err := json.Unmarshal(rr.Body.Bytes(), &state); err != nil {
+			t.Fatalf("failed to decode state: %v", err)
+		}
+	}
+	return rr, state
+}
+
+func TestControlState(t *testing.T) {
+	c := newTestController(t)
+
+	rr, state := doRequest(t, c, http.MethodGet, "/control/state", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if state.Scenario != "first" || state.Phase != "warmup" {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if state.Rate != "1hz" {
+		t.Errorf("expected rate 1hz, got %s", state.Rate)
+	}
+}
+
+func TestControlPauseResume(t *testing.T) {
+	c := newTestController(t)
+
+	_, state := doRequest(t, c, http.MethodPost, "/control/pause", "")
+	if !state.Paused {
+		t.Error("expected session to be paused")
+	}
+
+	paused := c.engine.GetElapsed()
+	time.Sleep(20 * time.Millisecond)
+	if c.engine.GetElapsed() != paused {
+		t.Error("expected clock to be frozen while paused")
+	}
+
+	_, state = doRequest(t, c, http.MethodPost, "/control/resume", "")
+	if state.Paused {
+		t.Error("expected session to be resumed")
+	}
+
+	rr, _ := doRequest(t, c, http.MethodGet, "/control/pause", "")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rr.Code)
+	}
+}
+
+func TestControlScenarioAndPhase(t *testing.T) {
+	c := newTestController(t)
+
+	_, state := doRequest(t, c, http.MethodPost, "/control/phase", `{"name": "spike"}`)
+	if state.Phase != "spike" {
+		t.Errorf("expected phase spike, got %q", state.Phase)
+	}
+	if state.ElapsedSeconds < 120 {
+		t.Errorf("expected elapsed >= 120s, got %v", state.ElapsedSeconds)
+	}
+
+	_, state = doRequest(t, c, http.MethodPost, "/control/scenario", `{"name": "second", "phase": "run"}`)
+	if state.Scenario != "second" || state.Phase != "run" {
+		t.Errorf("unexpected state after scenario switch: %+v", state)
+	}
+
+	rr, _ := doRequest(t, c, http.MethodPost, "/control/scenario", `{"name": "missing"}`)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+
+	rr, _ = doRequest(t, c, http.MethodPost, "/control/phase", `{"name": "spike"}`)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for phase of previous scenario, got %d", rr.Code)
+	}
+}
+
+func TestControlRate(t *testing.T) {
+	c := newTestController(t)
+
+	_, state := doRequest(t, c, http.MethodPost, "/control/rate", `{"rate": "10hz"}`)
+	if state.Rate != "10hz" {
+		t.Errorf("expected rate 10hz, got %s", state.Rate)
+	}
+
+	for _, body := range []string{`{"rate": "fast"}`, `{"rate": "1e10hz"}`} {
+		rr, _ := doRequest(t, c, http.MethodPost, "/control/rate", body)
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rr.Code)
+		}
+	}
+}
+
+// fakeBaselines stands in for the Flux engine
+type fakeBaselines struct {
+	snapshot string
+}
+
+func (f *fakeBaselines) Baselines(ctx context.Context) (string, error) {
+	return f.snapshot, nil
+}
+
+func (f *fakeBaselines) LoadBaselines(ctx context.Context, snapshot string) error {
+	if !json.Valid([]byte(snapshot)) {
+		return errors.New("invalid snapshot")
+	}
+	f.snapshot = snapshot
+	return nil
+}
+
+func (f *fakeBaselines) ResetBaselines(ctx context.Context) error {
+	f.snapshot = `{"hrv_values":[]}`
+	return nil
+}
+
+func TestControlFluxBaselines(t *testing.T) {
+	c := newTestController(t)
+
+	rr, _ := doRequest(t, c, http.MethodGet, "/control/flux/baselines", "")
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 without flux, got %d", rr.Code)
+	}
+
+	flux := &fakeBaselines{snapshot: `{"hrv_values":[50]}`}
+	c.SetFlux(flux)
+
+	rr, _ = doRequest(t, c, http.MethodGet, "/control/flux/baselines", "")
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"hrv_values":[50]}` {
+		t.Errorf("expected the snapshot, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr, _ = doRequest(t, c, http.MethodPost, "/control/flux/baselines", `{"hrv_values":[50,55]}`)
+	if rr.Code != http.StatusOK || flux.snapshot != `{"hrv_values":[50,55]}` {
+		t.Errorf("expected the snapshot to be loaded, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr, _ = doRequest(t, c, http.MethodPost, "/control/flux/baselines", `{bad`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid snapshot, got %d", rr.Code)
+	}
+
+	rr, _ = doRequest(t, c, http.MethodPost, "/control/flux/baselines", `{"hrv_values":[`+strings.Repeat("50,", maxBaselinesSize/3)+`50]}`)
+	if rr.Code != http.StatusRequestEntityTooLarge || flux.snapshot != `{"hrv_values":[50,55]}` {
+		t.Errorf("expected status 413 for an oversized snapshot, got %d", rr.Code)
+	}
+
+	rr, _ = doRequest(t, c, http.MethodPost, "/control/flux/reset", "")
+	if rr.Code != http.StatusOK || rr.Body.String() != `{"hrv_values":[]}` {
+		t.Errorf("expected the reset snapshot, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
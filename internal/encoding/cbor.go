@@ -0,0 +1,53 @@
+package encoding
+
+import (
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/synheart/synheart-cli/internal/models"
+)
+
+// CBOR uses the JSON field names and omitempty rules, with struct fields in
+// declaration order, so the same event always encodes to the same bytes and
+// mirrors the JSON envelope. Floats keep their full precision.
+var (
+	cborEncMode, _ = cbor.EncOptions{}.EncMode()
+	cborDecMode, _ = cbor.DecOptions{
+		DefaultMapType: reflect.TypeOf(map[string]interface{}(nil)),
+	}.DecMode()
+)
+
+// CBOREncoder encodes events as CBOR (RFC 8949)
+type CBOREncoder struct{}
+
+func NewCBOREncoder() *CBOREncoder {
+	return &CBOREncoder{}
+}
+
+func (e *CBOREncoder) Encode(event models.Event) ([]byte, error) {
+	return cborEncMode.Marshal(event)
+}
+
+func (e *CBOREncoder) ContentType() string {
+	return "application/cbor"
+}
+
+// CBORDecoder decodes CBOR events
+type CBORDecoder struct{}
+
+func NewCBORDecoder() *CBORDecoder {
+	return &CBORDecoder{}
+}
+
+func (d *CBORDecoder) Decode(data []byte) (models.Event, error) {
+	var event models.Event
+	if err := cborDecMode.Unmarshal(data, &event); err != nil {
+		return event, err
+	}
+	event.Signal.Value = normalizeValue(event.Signal.Value)
+	return event, nil
+}
+
+func (d *CBORDecoder) ContentType() string {
+	return "application/cbor"
+}
@@ -2,8 +2,12 @@ package encoding
 
 import (
 	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
 
 	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 // Format represents the encoding format
@@ -12,8 +16,52 @@ type Format string
 const (
 	FormatJSON     Format = "json"
 	FormatProtobuf Format = "protobuf"
+	FormatCBOR     Format = "cbor"
+	FormatMsgPack  Format = "msgpack"
 )
 
+// Formats lists the supported formats
+var Formats = []Format{FormatJSON, FormatProtobuf, FormatCBOR, FormatMsgPack}
+
+// ParseFormat parses a format name such as "protobuf"
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if strings.EqualFold(strings.TrimSpace(name), string(format)) {
+			return format, nil
+		}
+	}
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("invalid encoding %q (expected %s)", name, strings.Join(names, "|"))
+}
+
+// Binary reports whether the format is a binary encoding
+func (f Format) Binary() bool {
+	return f != FormatJSON
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	return NewEncoder(f).ContentType()
+}
+
+// ParseContentType returns the format of a MIME type such as
+// "application/cbor; charset=binary"
+func ParseContentType(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	for _, format := range Formats {
+		if mediaType == format.ContentType() {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported content type %q", mediaType)
+}
+
 // Encoder encodes events to bytes
 type Encoder interface {
 	Encode(event models.Event) ([]byte, error)
@@ -35,11 +83,113 @@ func (e *JSONEncoder) ContentType() string {
 	return "application/json"
 }
 
+// Decoder decodes events from bytes, the inverse of Encoder
+type Decoder interface {
+	Decode(data []byte) (models.Event, error)
+	ContentType() string
+}
+
+// JSONDecoder decodes JSON events
+type JSONDecoder struct{}
+
+func NewJSONDecoder() *JSONDecoder {
+	return &JSONDecoder{}
+}
+
+// Decode unmarshals a JSON event. Vector values and sample blocks come back
+// as []float64 and [][]float64, as the generator produces them.
+func (d *JSONDecoder) Decode(data []byte) (models.Event, error) {
+	var event models.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, err
+	}
+	event.Signal.Value = normalizeValue(event.Signal.Value)
+	return event, nil
+}
+
+func (d *JSONDecoder) ContentType() string {
+	return "application/json"
+}
+
+// normalizeValue converts JSON arrays of numbers, or of arrays of numbers, to
+// float slices; other values are returned unchanged
+func normalizeValue(v interface{}) interface{} {
+	items, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	values := make([]float64, 0, len(items))
+	vectors := make([][]float64, 0, len(items))
+	for _, item := range items {
+		if f, ok := toFloat(item); ok {
+			values = append(values, f)
+			continue
+		}
+		vector, ok := normalizeValue(item).([]float64)
+		if !ok {
+			return v
+		}
+		vectors = append(vectors, vector)
+	}
+	switch {
+	case len(vectors) == 0:
+		return values
+	case len(values) == 0:
+		return vectors
+	}
+	return v
+}
+
+// ToJSON converts a document in the format to JSON. Protobuf documents must be
+// events; CBOR and MessagePack documents may be anything with string map keys.
+func ToJSON(data []byte, format Format) ([]byte, error) {
+	var doc interface{}
+	switch format {
+	case FormatJSON:
+		return data, nil
+	case FormatProtobuf:
+		event, err := NewProtobufDecoder().Decode(data)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(event)
+	case FormatCBOR:
+		if err := cborDecMode.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	case FormatMsgPack:
+		if err := msgpack.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", format)
+	}
+	return json.Marshal(doc)
+}
+
+// NewDecoder creates a decoder for the given format
+func NewDecoder(format Format) Decoder {
+	switch format {
+	case FormatProtobuf:
+		return NewProtobufDecoder()
+	case FormatCBOR:
+		return NewCBORDecoder()
+	case FormatMsgPack:
+		return NewMsgPackDecoder()
+	default:
+		return NewJSONDecoder()
+	}
+}
+
 // NewEncoder creates an encoder for the given format
 func NewEncoder(format Format) Encoder {
 	switch format {
 	case FormatProtobuf:
 		return NewProtobufEncoder()
+	case FormatCBOR:
+		return NewCBOREncoder()
+	case FormatMsgPack:
+		return NewMsgPackEncoder()
 	default:
 		return NewJSONEncoder()
 	}
@@ -0,0 +1,65 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryFormats_MirrorJSON(t *testing.T) {
+	for _, format := range []Format{FormatCBOR, FormatMsgPack} {
+		enc := NewEncoder(format)
+		for _, event := range roundTripEvents() {
+			data, err := enc.Encode(event)
+			if err != nil {
+				t.Fatalf("%s: encode failed: %v", format, err)
+			}
+			again, _ := enc.Encode(event)
+			if !bytes.Equal(data, again) {
+				t.Errorf("%s: encoding %s is not deterministic", format, event.Signal.Name)
+			}
+
+			// Field names, nesting and omitted fields match the JSON envelope
+			converted, err := ToJSON(data, format)
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			want, _ := json.Marshal(event)
+			var gotDoc, wantDoc interface{}
+			json.Unmarshal(converted, &gotDoc)
+			json.Unmarshal(want, &wantDoc)
+			if !reflect.DeepEqual(gotDoc, wantDoc) {
+				t.Errorf("%s: %s\n got %s\nwant %s", format, event.Signal.Name, converted, want)
+			}
+		}
+	}
+}
+
+func TestBinaryFormats_Smaller(t *testing.T) {
+	event := roundTripEvents()[0]
+	text, _ := NewJSONEncoder().Encode(event)
+	for _, format := range []Format{FormatProtobuf, FormatCBOR, FormatMsgPack} {
+		data, _ := NewEncoder(format).Encode(event)
+		if len(data) >= len(text) {
+			t.Errorf("%s: %d bytes, JSON is %d", format, len(data), len(text))
+		}
+	}
+}
+
+func TestParseContentType(t *testing.T) {
+	tests := map[string]Format{
+		"application/json; charset=utf-8": FormatJSON,
+		"application/x-protobuf":          FormatProtobuf,
+		"application/cbor":                FormatCBOR,
+		"application/msgpack":             FormatMsgPack,
+	}
+	for contentType, want := range tests {
+		if got, err := ParseContentType(contentType); err != nil || got != want {
+			t.Errorf("%s: got %q (%v), want %q", contentType, got, err, want)
+		}
+	}
+	if _, err := ParseContentType("text/plain"); err == nil {
+		t.Error("expected an error for text/plain")
+	}
+}
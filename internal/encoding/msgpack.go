@@ -0,0 +1,57 @@
+package encoding
+
+import (
+	"bytes"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MessagePack uses the JSON field names and omitempty rules, with struct
+// fields in declaration order, so the same event always encodes to the same
+// bytes and mirrors the JSON envelope. Integers are packed into the fewest
+// bytes; floats keep their full precision.
+
+// MsgPackEncoder encodes events as MessagePack
+type MsgPackEncoder struct{}
+
+func NewMsgPackEncoder() *MsgPackEncoder {
+	return &MsgPackEncoder{}
+}
+
+func (e *MsgPackEncoder) Encode(event models.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	if err := enc.Encode(event); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *MsgPackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+// MsgPackDecoder decodes MessagePack events
+type MsgPackDecoder struct{}
+
+func NewMsgPackDecoder() *MsgPackDecoder {
+	return &MsgPackDecoder{}
+}
+
+func (d *MsgPackDecoder) Decode(data []byte) (models.Event, error) {
+	var event models.Event
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	if err := dec.Decode(&event); err != nil {
+		return event, err
+	}
+	event.Signal.Value = normalizeValue(event.Signal.Value)
+	return event, nil
+}
+
+func (d *MsgPackDecoder) ContentType() string {
+	return "application/msgpack"
+}
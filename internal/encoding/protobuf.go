@@ -22,6 +22,25 @@ func (e *ProtobufEncoder) ContentType() string {
 	return "application/x-protobuf"
 }
 
+// ProtobufDecoder decodes protocol buffer events
+type ProtobufDecoder struct{}
+
+func NewProtobufDecoder() *ProtobufDecoder {
+	return &ProtobufDecoder{}
+}
+
+func (d *ProtobufDecoder) Decode(data []byte) (models.Event, error) {
+	var pb hsi.Event
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return models.Event{}, err
+	}
+	return protoToEvent(&pb), nil
+}
+
+func (d *ProtobufDecoder) ContentType() string {
+	return "application/x-protobuf"
+}
+
 func eventToProto(e models.Event) *hsi.Event {
 	pb := &hsi.Event{
 		SchemaVersion: e.SchemaVersion,
@@ -37,12 +56,15 @@ func eventToProto(e models.Event) *hsi.Event {
 			Seed:     e.Session.Seed,
 		},
 		Signal: &hsi.Signal{
-			Name:    e.Signal.Name,
-			Unit:    e.Signal.Unit,
-			Quality: e.Signal.Quality,
+			Name:           e.Signal.Name,
+			Unit:           e.Signal.Unit,
+			Quality:        e.Signal.Quality,
+			SamplePeriodMs: e.Signal.SamplePeriodMs,
 		},
 		Meta: &hsi.Meta{
-			Sequence: e.Meta.Sequence,
+			Sequence:   e.Meta.Sequence,
+			Injections: e.Meta.Injections,
+			Faults:     e.Meta.Faults,
 		},
 	}
 
@@ -50,10 +72,61 @@ func eventToProto(e models.Event) *hsi.Event {
 		pb.Source.Side = e.Source.Side
 	}
 
-	pb.Signal.Value = toSignalValue(e.Signal.Value)
+	if e.Signal.IsBlock() {
+		pb.Signal.Value = toBlockValue(e.Signal.Value)
+	} else {
+		pb.Signal.Value = toSignalValue(e.Signal.Value)
+	}
 	return pb
 }
 
+func toBlockValue(v interface{}) *hsi.SignalValue {
+	switch val := v.(type) {
+	case []float64:
+		return &hsi.SignalValue{Kind: &hsi.SignalValue_Samples{
+			Samples: &hsi.ScalarBlock{Values: val},
+		}}
+	case [][]float64:
+		vectors := make([]*hsi.Vector3, 0, len(val))
+		for _, sample := range val {
+			if len(sample) < 3 {
+				return nil
+			}
+			vectors = append(vectors, &hsi.Vector3{X: sample[0], Y: sample[1], Z: sample[2]})
+		}
+		return &hsi.SignalValue{Kind: &hsi.SignalValue_Vectors{
+			Vectors: &hsi.VectorBlock{Values: vectors},
+		}}
+	case []interface{}:
+		// JSON unmarshals blocks as []interface{}, of numbers or of arrays
+		values := make([]float64, 0, len(val))
+		vectors := make([]*hsi.Vector3, 0, len(val))
+		for _, item := range val {
+			if f, ok := toFloat(item); ok {
+				values = append(values, f)
+				continue
+			}
+			vector := toSignalValue(item).GetVector()
+			if vector == nil {
+				return nil
+			}
+			vectors = append(vectors, vector)
+		}
+		if len(vectors) == 0 {
+			return &hsi.SignalValue{Kind: &hsi.SignalValue_Samples{
+				Samples: &hsi.ScalarBlock{Values: values},
+			}}
+		}
+		if len(values) > 0 {
+			return nil
+		}
+		return &hsi.SignalValue{Kind: &hsi.SignalValue_Vectors{
+			Vectors: &hsi.VectorBlock{Values: vectors},
+		}}
+	}
+	return nil
+}
+
 func toSignalValue(v interface{}) *hsi.SignalValue {
 	switch val := v.(type) {
 	case float64:
@@ -95,3 +168,68 @@ func toFloat(v interface{}) (float64, bool) {
 	}
 	return 0, false
 }
+
+func protoToEvent(pb *hsi.Event) models.Event {
+	e := models.Event{
+		SchemaVersion: pb.GetSchemaVersion(),
+		EventID:       pb.GetEventId(),
+		Timestamp:     pb.GetTs(),
+		Source: models.Source{
+			Type: pb.GetSource().GetType(),
+			ID:   pb.GetSource().GetId(),
+		},
+		Session: models.Session{
+			RunID:    pb.GetSession().GetRunId(),
+			Scenario: pb.GetSession().GetScenario(),
+			Seed:     pb.GetSession().GetSeed(),
+		},
+		Signal: models.Signal{
+			Name:           pb.GetSignal().GetName(),
+			Unit:           pb.GetSignal().GetUnit(),
+			Value:          fromSignalValue(pb.GetSignal().GetValue()),
+			Quality:        pb.GetSignal().GetQuality(),
+			SamplePeriodMs: pb.GetSignal().GetSamplePeriodMs(),
+		},
+		Meta: models.Meta{
+			Sequence:   pb.GetMeta().GetSequence(),
+			Injections: pb.GetMeta().GetInjections(),
+			Faults:     pb.GetMeta().GetFaults(),
+		},
+	}
+	if pb.GetSource().Side != nil {
+		side := pb.GetSource().GetSide()
+		e.Source.Side = &side
+	}
+	return e
+}
+
+// fromSignalValue is the inverse of toSignalValue and toBlockValue: scalars
+// become float64, text string, vectors []float64{x, y, z}, and blocks
+// []float64 or [][]float64
+func fromSignalValue(v *hsi.SignalValue) interface{} {
+	switch kind := v.GetKind().(type) {
+	case *hsi.SignalValue_Scalar:
+		return kind.Scalar
+	case *hsi.SignalValue_Text:
+		return kind.Text
+	case *hsi.SignalValue_Vector:
+		return fromVector(kind.Vector)
+	case *hsi.SignalValue_Samples:
+		values := kind.Samples.GetValues()
+		if values == nil {
+			values = []float64{}
+		}
+		return values
+	case *hsi.SignalValue_Vectors:
+		vectors := make([][]float64, 0, len(kind.Vectors.GetValues()))
+		for _, vector := range kind.Vectors.GetValues() {
+			vectors = append(vectors, fromVector(vector))
+		}
+		return vectors
+	}
+	return nil
+}
+
+func fromVector(v *hsi.Vector3) []float64 {
+	return []float64{v.GetX(), v.GetY(), v.GetZ()}
+}
@@ -1,6 +1,7 @@
 package encoding
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/synheart/synheart-cli/internal/models"
@@ -106,6 +107,30 @@ func TestProtobufEncoder_TextValue(t *testing.T) {
 	}
 }
 
+func TestProtobufEncoder_Injections(t *testing.T) {
+	enc := NewProtobufEncoder()
+
+	event := models.Event{
+		Signal: models.Signal{Name: "ppg.hr_bpm", Value: 90.0},
+		Meta:   models.Meta{Sequence: 3, Injections: []string{"motion_artifact", "notification"}},
+	}
+
+	data, err := enc.Encode(event)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	var pb hsi.Event
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	got := pb.Meta.GetInjections()
+	if len(got) != 2 || got[0] != "motion_artifact" || got[1] != "notification" {
+		t.Errorf("meta.injections = %v, want [motion_artifact notification]", got)
+	}
+}
+
 func TestProtobufEncoder_ContentType(t *testing.T) {
 	enc := NewProtobufEncoder()
 	if ct := enc.ContentType(); ct != "application/x-protobuf" {
@@ -124,3 +149,152 @@ func TestNewEncoder_Factory(t *testing.T) {
 		t.Errorf("protobuf encoder content type = %q", protoEnc.ContentType())
 	}
 }
+
+func TestProtobufEncoder_ScalarBlock(t *testing.T) {
+	enc := NewProtobufEncoder()
+
+	event := models.Event{
+		SchemaVersion: "hsi.input.v1",
+		EventID:       "ppg-789",
+		Timestamp:     "2025-01-02T10:00:00Z",
+		Source:        models.Source{Type: "wearable", ID: "watch-1"},
+		Session:       models.Session{RunID: "run-1", Scenario: "beat_detection", Seed: 7},
+		Signal: models.Signal{
+			Name:           "ppg.raw",
+			Unit:           "au",
+			Value:          []float64{0.1, 0.4, 0.9, 0.7},
+			Quality:        0.97,
+			SamplePeriodMs: 10,
+		},
+		Meta: models.Meta{Sequence: 3},
+	}
+
+	data, err := enc.Encode(event)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	var pb hsi.Event
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if pb.Signal.Value.GetVector() != nil {
+		t.Fatal("expected a sample block, not a vector")
+	}
+	samples := pb.Signal.Value.GetSamples().GetValues()
+	if len(samples) != 4 || samples[2] != 0.9 {
+		t.Errorf("samples = %v, want [0.1 0.4 0.9 0.7]", samples)
+	}
+	if pb.Signal.SamplePeriodMs != 10 {
+		t.Errorf("sample_period_ms = %v, want 10", pb.Signal.SamplePeriodMs)
+	}
+}
+
+func TestProtobufEncoder_VectorBlock(t *testing.T) {
+	enc := NewProtobufEncoder()
+
+	event := models.Event{
+		SchemaVersion: "hsi.input.v1",
+		EventID:       "accel-block",
+		Timestamp:     "2025-01-02T10:00:00Z",
+		Source:        models.Source{Type: "wearable", ID: "watch-1"},
+		Session:       models.Session{RunID: "run-1", Scenario: "workout", Seed: 7},
+		Signal: models.Signal{
+			Name:           "accel.xyz_mps2",
+			Unit:           "m/s²",
+			Value:          []interface{}{[]interface{}{0.1, 0.2, 9.8}, []interface{}{0.0, -0.1, 9.9}},
+			Quality:        0.95,
+			SamplePeriodMs: 20,
+		},
+		Meta: models.Meta{Sequence: 4},
+	}
+
+	data, err := enc.Encode(event)
+	if err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	var pb hsi.Event
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	vectors := pb.Signal.Value.GetVectors().GetValues()
+	if len(vectors) != 2 {
+		t.Fatalf("expected 2 vector samples, got %v", pb.Signal.Value)
+	}
+	if vectors[1].Y != -0.1 || vectors[1].Z != 9.9 {
+		t.Errorf("second sample = (%v, %v, %v), want (0, -0.1, 9.9)", vectors[1].X, vectors[1].Y, vectors[1].Z)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("Protobuf"); err != nil || format != FormatProtobuf || !format.Binary() {
+		t.Errorf("expected protobuf, got %q (%v)", format, err)
+	}
+	if format, err := ParseFormat("json"); err != nil || format.Binary() {
+		t.Errorf("expected json, got %q (%v)", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil || err.Error() != `invalid encoding "xml" (expected json|protobuf|cbor|msgpack)` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func roundTripEvents() []models.Event {
+	side := "right"
+	base := func(name string, value interface{}) models.Event {
+		return models.Event{
+			SchemaVersion: "hsi.input.v1",
+			EventID:       "evt-" + name,
+			Timestamp:     "2025-01-02T10:00:00.02Z",
+			Source:        models.Source{Type: "wearable", ID: "watch-1", Side: &side},
+			Session:       models.Session{RunID: "run-1", Scenario: "baseline", Seed: 7},
+			Signal:        models.Signal{Name: name, Unit: "u", Value: value, Quality: 0.9},
+			Meta:          models.Meta{Sequence: 4, Injections: []string{"blip"}, Faults: []string{"stuck"}},
+		}
+	}
+	samples := base("ppg.raw", []float64{0.1, 0.5, 0.9})
+	samples.Signal.SamplePeriodMs = 20
+	vectors := base("accel.xyz_mps2", [][]float64{{0, 0, 9.8}, {0.1, -0.2, 9.7}})
+	vectors.Signal.SamplePeriodMs = 20
+	phone := base("screen.state", "on")
+	phone.Source = models.Source{Type: "phone", ID: "phone-1"}
+	phone.Meta = models.Meta{Sequence: 5}
+
+	return []models.Event{
+		base("ppg.hr_bpm", 72.5),
+		base("gyro.xyz_rps", []float64{0.01, -0.02, 0.03}),
+		phone,
+		samples,
+		vectors,
+	}
+}
+
+func TestDecoder_RoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		enc, dec := NewEncoder(format), NewDecoder(format)
+		if enc.ContentType() != dec.ContentType() {
+			t.Errorf("%s: decoder content type %q, want %q", format, dec.ContentType(), enc.ContentType())
+		}
+		for _, event := range roundTripEvents() {
+			data, err := enc.Encode(event)
+			if err != nil {
+				t.Fatalf("%s: encode %s failed: %v", format, event.Signal.Name, err)
+			}
+			decoded, err := dec.Decode(data)
+			if err != nil {
+				t.Fatalf("%s: decode %s failed: %v", format, event.Signal.Name, err)
+			}
+			if !reflect.DeepEqual(decoded, event) {
+				t.Errorf("%s: round trip of %s\n got %+v\nwant %+v", format, event.Signal.Name, decoded, event)
+			}
+		}
+	}
+}
+
+func TestProtobufDecoder_Invalid(t *testing.T) {
+	if _, err := NewProtobufDecoder().Decode([]byte{0x0a, 0xff}); err == nil {
+		t.Error("expected an error for a truncated message")
+	}
+}
@@ -0,0 +1,176 @@
+package encoding
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/synheart/synheart-cli/internal/models"
+)
+
+// maxRecordSize bounds a single record in a stream, guarding against reading
+// a corrupt length prefix as a huge allocation
+const maxRecordSize = 16 << 20
+
+// RecordWriter frames encoded records onto a stream
+type RecordWriter interface {
+	Write(record []byte) error
+}
+
+// RecordReader reads framed records from a stream, returning io.EOF after the
+// last one
+type RecordReader interface {
+	Next() ([]byte, error)
+}
+
+// NewRecordWriter frames records for the format: one per line for JSON
+// (NDJSON), and prefixed with their length as a protobuf varint for the binary
+// formats, the framing of protodelim and of writeDelimitedTo in other languages
+func NewRecordWriter(w io.Writer, format Format) RecordWriter {
+	if format.Binary() {
+		return &delimitedWriter{w: w}
+	}
+	return &lineWriter{w: w}
+}
+
+// NewRecordReader reads records framed by NewRecordWriter for the format
+func NewRecordReader(r io.Reader, format Format) RecordReader {
+	if format.Binary() {
+		return &delimitedReader{r: bufio.NewReader(r)}
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	return &lineReader{scanner: scanner}
+}
+
+// DetectStreamFormat tells NDJSON from length-delimited binary events by
+// reading the first record as if the stream were delimited and decoding it with
+// each binary decoder in turn; the format that yields an event with a schema
+// version and a signal is the stream's. Anything else, including an empty
+// stream, is JSON. The returned reader replays the bytes read for detection
+// ahead of the rest of the stream.
+func DetectStreamFormat(r io.Reader) (Format, io.Reader, error) {
+	stream := bufio.NewReader(r)
+	head, err := stream.Peek(binary.MaxVarintLen64)
+	if err != nil && err != io.EOF {
+		return "", nil, err
+	}
+	size, n := binary.Uvarint(head)
+	if n <= 0 || size == 0 || size > maxRecordSize {
+		return FormatJSON, stream, nil
+	}
+
+	first := make([]byte, n+int(size))
+	read, err := io.ReadFull(stream, first)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, err
+	}
+	replay := io.MultiReader(bytes.NewReader(first[:read]), stream)
+	if read < len(first) {
+		return FormatJSON, replay, nil
+	}
+	for _, format := range Formats {
+		if !format.Binary() {
+			continue
+		}
+		if event, err := NewDecoder(format).Decode(first[n:]); err == nil && event.SchemaVersion != "" && event.Signal.Name != "" {
+			return format, replay, nil
+		}
+	}
+	return FormatJSON, replay, nil
+}
+
+// EventReader decodes events from a framed stream
+type EventReader struct {
+	records RecordReader
+	decoder Decoder
+}
+
+// NewEventReader reads events encoded and framed in the format
+func NewEventReader(r io.Reader, format Format) *EventReader {
+	return &EventReader{records: NewRecordReader(r, format), decoder: NewDecoder(format)}
+}
+
+// Next returns the next event, or io.EOF after the last one
+func (r *EventReader) Next() (models.Event, error) {
+	data, err := r.records.Next()
+	if err != nil {
+		return models.Event{}, err
+	}
+	event, err := r.decoder.Decode(data)
+	if err != nil {
+		return event, fmt.Errorf("failed to decode event: %w", err)
+	}
+	return event, nil
+}
+
+type lineWriter struct {
+	w io.Writer
+}
+
+func (w *lineWriter) Write(record []byte) error {
+	if _, err := w.w.Write(record); err != nil {
+		return err
+	}
+	_, err := w.w.Write([]byte("\n"))
+	return err
+}
+
+type lineReader struct {
+	scanner *bufio.Scanner
+}
+
+// Next returns the next non-blank line
+func (r *lineReader) Next() ([]byte, error) {
+	for r.scanner.Scan() {
+		if line := bytes.TrimSpace(r.scanner.Bytes()); len(line) > 0 {
+			return append([]byte(nil), line...), nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type delimitedWriter struct {
+	w io.Writer
+}
+
+func (w *delimitedWriter) Write(record []byte) error {
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(record)))
+	if _, err := w.w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(record)
+	return err
+}
+
+type delimitedReader struct {
+	r *bufio.Reader
+}
+
+func (r *delimitedReader) Next() ([]byte, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("failed to read record length: %w", err)
+	}
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes exceeds the %d byte limit", size, maxRecordSize)
+	}
+	record := make([]byte, size)
+	if _, err := io.ReadFull(r.r, record); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("failed to read record: %w", err)
+	}
+	return record, nil
+}
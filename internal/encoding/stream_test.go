@@ -0,0 +1,121 @@
+package encoding
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestEventStream_RoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		writer := NewRecordWriter(&buf, format)
+		enc := NewEncoder(format)
+		events := roundTripEvents()
+		for _, event := range events {
+			data, err := enc.Encode(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		detected, stream, err := DetectStreamFormat(&buf)
+		if err != nil || detected != format {
+			t.Errorf("%s: detected %q (%v)", format, detected, err)
+		}
+
+		reader := NewEventReader(stream, format)
+		for i, want := range events {
+			got, err := reader.Next()
+			if err != nil {
+				t.Fatalf("%s: event %d: %v", format, i, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: event %d\n got %+v\nwant %+v", format, i, got, want)
+			}
+		}
+		if _, err := reader.Next(); err != io.EOF {
+			t.Errorf("%s: expected io.EOF after the last event, got %v", format, err)
+		}
+	}
+}
+
+func TestRecordReader_NDJSON(t *testing.T) {
+	reader := NewRecordReader(strings.NewReader("{\"a\":1}\n\n  {\"b\":2}  \n"), FormatJSON)
+	var records []string
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, string(record))
+	}
+	if strings.Join(records, "|") != `{"a":1}|{"b":2}` {
+		t.Errorf("unexpected records %q", records)
+	}
+}
+
+func TestRecordReader_Truncated(t *testing.T) {
+	reader := NewRecordReader(bytes.NewReader([]byte{0x05, 0x0a, 0x01}), FormatProtobuf)
+	if _, err := reader.Next(); err == nil || !strings.Contains(err.Error(), "unexpected EOF") {
+		t.Errorf("expected an unexpected EOF error, got %v", err)
+	}
+
+	reader = NewRecordReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x7f}), FormatProtobuf)
+	if _, err := reader.Next(); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("expected a size limit error, got %v", err)
+	}
+}
+
+func TestDetectStreamFormat_Empty(t *testing.T) {
+	if format, _, err := DetectStreamFormat(strings.NewReader("")); err != nil || format != FormatJSON {
+		t.Errorf("expected json for an empty stream, got %q (%v)", format, err)
+	}
+}
+
+func TestDetectStreamFormat_EnvelopeFields(t *testing.T) {
+	// Detection must not depend on how many fields the envelope has: an event
+	// map with a field added is still recognized
+	fields := struct {
+		models.Event
+		Extra string `json:"extra"`
+	}{Event: roundTripEvents()[0], Extra: "field"}
+
+	encoded := map[Format][]byte{}
+	encoded[FormatCBOR], _ = cbor.Marshal(fields)
+	var msgpackData bytes.Buffer
+	enc := msgpack.NewEncoder(&msgpackData)
+	enc.SetCustomStructTag("json")
+	enc.Encode(fields)
+	encoded[FormatMsgPack] = msgpackData.Bytes()
+	for format, data := range encoded {
+		var buf bytes.Buffer
+		NewRecordWriter(&buf, format).Write(data)
+		if detected, _, err := DetectStreamFormat(&buf); err != nil || detected != format {
+			t.Errorf("%s: detected %q (%v)", format, detected, err)
+		}
+	}
+
+	// NDJSON is read back in full after detection
+	text, _ := NewJSONEncoder().Encode(fields.Event)
+	ndjson := string(text) + "\n" + string(text) + "\n"
+	detected, stream, err := DetectStreamFormat(strings.NewReader(ndjson))
+	if err != nil || detected != FormatJSON {
+		t.Fatalf("expected json, got %q (%v)", detected, err)
+	}
+	if rest, _ := io.ReadAll(stream); string(rest) != ndjson {
+		t.Errorf("expected the stream replayed in full, got %q", rest)
+	}
+}
@@ -3,8 +3,13 @@ package flux
 import (
 	"context"
 	_ "embed"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
@@ -14,59 +19,228 @@ import (
 //go:embed synheart_flux.wasm
 var fluxWasm []byte
 
+// transforms maps the vendor formats the embedded module understands to their
+// processor functions. Other formats have no Flux transform.
+var transforms = map[string]string{
+	"whoop":  "flux_processor_process_whoop",
+	"garmin": "flux_processor_process_garmin",
+}
+
+// Vendors returns the sorted vendor formats Flux can transform
+func Vendors() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckVendor returns an error if Flux has no transform for the vendor format
+func CheckVendor(vendor string) error {
+	if _, ok := transforms[vendor]; !ok {
+		return fmt.Errorf("flux has no transform for %s payloads (supported: %s)", vendor, strings.Join(Vendors(), ", "))
+	}
+	return nil
+}
+
+// vendorKeys are the top-level collections that identify a vendor payload
+var vendorKeys = map[string][]string{
+	"whoop":  {"cycle", "recovery"},
+	"garmin": {"dailies"},
+}
+
+// DetectVendor guesses the vendor format of a JSON payload from its top-level
+// collections: Whoop's cycle or recovery, Garmin's dailies
+func DetectVendor(payload []byte) (string, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return "", fmt.Errorf("invalid payload: %w", err)
+	}
+	for _, vendor := range Vendors() {
+		for _, key := range vendorKeys[vendor] {
+			if _, ok := doc[key]; ok {
+				return vendor, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unrecognized payload: expected Whoop cycle/recovery or Garmin dailies")
+}
+
+// Options configure an Engine; zero values select the defaults
+type Options struct {
+	BaselineDays int    // days in the processor's rolling baselines, default 14
+	Timezone     string // IANA timezone the payloads are interpreted in, default UTC
+	DeviceID     string // device ID recorded in HSI provenance, default mock-watch-01
+}
+
+func (o Options) withDefaults() Options {
+	if o.BaselineDays == 0 {
+		o.BaselineDays = 14
+	}
+	if o.Timezone == "" {
+		o.Timezone = "UTC"
+	}
+	if o.DeviceID == "" {
+		o.DeviceID = "mock-watch-01"
+	}
+	return o
+}
+
+// Validate checks the options
+func (o Options) Validate() error {
+	if o.BaselineDays < 0 {
+		return fmt.Errorf("invalid baseline window %d: must be positive", o.BaselineDays)
+	}
+	if _, err := time.LoadLocation(o.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", o.Timezone, err)
+	}
+	return nil
+}
+
+// Engine runs the embedded Flux module. Its processor keeps rolling baselines
+// across payloads, which can be saved, loaded and reset. Calls are serialized,
+// so an Engine can be shared with the control plane.
 type Engine struct {
 	runtime wazero.Runtime
 	module  api.Module
 	ptr     uint32 // FluxProcessorHandle pointer
+	opts    Options
+	mu      sync.Mutex
 }
 
 func NewEngine(ctx context.Context) (*Engine, error) {
-	wasmBytes := fluxWasm
+	return NewEngineWithOptions(ctx, Options{})
+}
 
-	r := wazero.NewRuntime(ctx)
+// NewEngineWithOptions creates an engine with the given baseline window,
+// timezone and device ID
+func NewEngineWithOptions(ctx context.Context, opts Options) (*Engine, error) {
+	opts = opts.withDefaults()
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	e := &Engine{
+		runtime: wazero.NewRuntime(ctx),
+		opts:    opts,
+	}
+	if err := e.instantiate(ctx); err != nil {
+		e.runtime.Close(ctx)
+		return nil, err
+	}
+	return e, nil
+}
 
-	// Instantiate WASI
-	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+// instantiate loads WASI and the Flux module into the runtime and creates the
+// processor
+func (e *Engine) instantiate(ctx context.Context) error {
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, e.runtime); err != nil {
+		return fmt.Errorf("failed to instantiate WASI: %w", err)
+	}
 
-	// Compile and instantiate the module
-	compiled, err := r.CompileModule(ctx, wasmBytes)
+	compiled, err := e.runtime.CompileModule(ctx, fluxWasm)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile wasm module: %w", err)
+		return fmt.Errorf("failed to compile wasm module: %w", err)
 	}
 
-	mod, err := r.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithStdout(os.Stdout).WithStderr(os.Stderr))
+	e.module, err = e.runtime.InstantiateModule(ctx, compiled, wazero.NewModuleConfig().WithStdout(os.Stdout).WithStderr(os.Stderr))
 	if err != nil {
-		return nil, fmt.Errorf("failed to instantiate wasm module: %w", err)
+		return fmt.Errorf("failed to instantiate wasm module: %w", err)
 	}
+	return e.newProcessor(ctx)
+}
+
+// Options returns the engine's options, defaults filled in
+func (e *Engine) Options() Options {
+	return e.opts
+}
 
-	// Create processor
-	fn := mod.ExportedFunction("flux_processor_new")
+// newProcessor creates the stateful processor with empty baselines
+func (e *Engine) newProcessor(ctx context.Context) error {
+	fn := e.module.ExportedFunction("flux_processor_new")
 	if fn == nil {
-		return nil, fmt.Errorf("flux_processor_new not exported")
+		return fmt.Errorf("flux_processor_new not exported")
 	}
 
-	results, err := fn.Call(ctx, 14) // Default 14 days baseline
+	results, err := fn.Call(ctx, uint64(e.opts.BaselineDays))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create flux processor: %w", err)
+		return fmt.Errorf("failed to create flux processor: %w", err)
 	}
-
-	return &Engine{
-		runtime: r,
-		module:  mod,
-		ptr:     uint32(results[0]),
-	}, nil
+	e.ptr = uint32(results[0])
+	return nil
 }
 
-func (e *Engine) Close(ctx context.Context) error {
+func (e *Engine) freeProcessor(ctx context.Context) {
 	if e.ptr != 0 {
 		fn := e.module.ExportedFunction("flux_processor_free")
 		if fn != nil {
 			_, _ = fn.Call(ctx, uint64(e.ptr))
 		}
+		e.ptr = 0
 	}
+}
+
+func (e *Engine) Close(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.freeProcessor(ctx)
 	return e.runtime.Close(ctx)
 }
 
+// Baselines returns a JSON snapshot of the processor's rolling baselines
+func (e *Engine) Baselines(ctx context.Context) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	fn := e.module.ExportedFunction("flux_processor_save_baselines")
+	if fn == nil {
+		return "", fmt.Errorf("flux_processor_save_baselines not exported")
+	}
+	results, err := fn.Call(ctx, uint64(e.ptr))
+	if err != nil {
+		return "", fmt.Errorf("failed to save baselines: %w", err)
+	}
+	ptr := uint32(results[0])
+	if ptr == 0 {
+		return "", e.getLastError(ctx)
+	}
+	defer e.freeString(ctx, ptr)
+	return e.readString(ptr)
+}
+
+// LoadBaselines replaces the processor's baselines with a snapshot from Baselines
+func (e *Engine) LoadBaselines(ctx context.Context, snapshot string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ptr, size, err := e.writeString(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+	defer e.dealloc(ctx, ptr, size)
+
+	fn := e.module.ExportedFunction("flux_processor_load_baselines")
+	if fn == nil {
+		return fmt.Errorf("flux_processor_load_baselines not exported")
+	}
+	results, err := fn.Call(ctx, uint64(e.ptr), uint64(ptr))
+	if err != nil {
+		return fmt.Errorf("failed to load baselines: %w", err)
+	}
+	if int32(results[0]) != 0 {
+		return e.getLastError(ctx)
+	}
+	return nil
+}
+
+// ResetBaselines discards the processor's baselines, as if no payloads had been seen
+func (e *Engine) ResetBaselines(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.freeProcessor(ctx)
+	return e.newProcessor(ctx)
+}
+
 func (e *Engine) WhoopToHSI(ctx context.Context, json, timezone, deviceID string) (string, error) {
 	return e.callTransform(ctx, "flux_processor_process_whoop", true, json, timezone, deviceID)
 }
@@ -75,7 +249,19 @@ func (e *Engine) GarminToHSI(ctx context.Context, json, timezone, deviceID strin
 	return e.callTransform(ctx, "flux_processor_process_garmin", true, json, timezone, deviceID)
 }
 
+// Transform converts a payload in the given vendor format to HSI, with the
+// engine's timezone and device ID
+func (e *Engine) Transform(ctx context.Context, vendor, json string) (string, error) {
+	if err := CheckVendor(vendor); err != nil {
+		return "", err
+	}
+	return e.callTransform(ctx, transforms[vendor], true, json, e.opts.Timezone, e.opts.DeviceID)
+}
+
 func (e *Engine) callTransform(ctx context.Context, funcName string, stateful bool, json, timezone, deviceID string) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	// Allocate and copy strings to guest memory
 	jsonPtr, jsonLen, err := e.writeString(ctx, json)
 	if err != nil {
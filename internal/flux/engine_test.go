@@ -0,0 +1,131 @@
+package flux
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// whoopPayload returns a minimal Whoop payload for the given day
+func whoopPayload(day int, hrv float64) string {
+	date := fmt.Sprintf("2025-01-%02d", day)
+	return fmt.Sprintf(`{"cycle":[{"id":%d,"start":"%sT00:00:00Z","end":"%sT23:59:00Z","score":{"strain":8,"kilojoule":9000,"average_heart_rate":70,"max_heart_rate":150}}],`+
+		`"recovery":[{"cycle_id":%d,"sleep_id":%d,"created_at":"%sT07:00:00Z","score":{"recovery_score":60,"resting_heart_rate":55,"hrv_rmssd_milli":%g,"skin_temp_celsius":33.1}}],`+
+		`"sleep":[{"id":%d,"start":"%sT23:00:00Z","end":"%sT07:00:00Z","score":{"stage_summary":{"total_in_bed_time_milli":28800000,"total_awake_time_milli":1800000,"total_light_sleep_time_milli":12600000,"total_slow_wave_sleep_time_milli":7200000,"total_rem_sleep_time_milli":7200000,"total_sleep_time_milli":27000000,"disturbance_count":2},"sleep_performance_percentage":90,"respiratory_rate":15}}]}`,
+		day, date, date, day, day, date, hrv, day, fmt.Sprintf("2025-01-%02d", day-1), date)
+}
+
+func newTestEngine(t *testing.T, opts Options) *Engine {
+	t.Helper()
+	engine, err := NewEngineWithOptions(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { engine.Close(context.Background()) })
+	return engine
+}
+
+func baselineCount(t *testing.T, engine *Engine) int {
+	t.Helper()
+	snapshot, err := engine.Baselines(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var store struct {
+		HRV []float64 `json:"hrv_values"`
+	}
+	if err := json.Unmarshal([]byte(snapshot), &store); err != nil {
+		t.Fatalf("invalid snapshot %s: %v", snapshot, err)
+	}
+	return len(store.HRV)
+}
+
+func TestEngineOptions(t *testing.T) {
+	ctx := context.Background()
+	engine := newTestEngine(t, Options{BaselineDays: 3, Timezone: "America/New_York", DeviceID: "watch-7"})
+
+	hsi, err := engine.Transform(ctx, "whoop", whoopPayload(2, 50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hsi, `"source_device_id": "watch-7"`) || !strings.Contains(hsi, `"timezone": "America/New_York"`) {
+		t.Errorf("expected the device ID and timezone in the HSI output, got %s", hsi)
+	}
+
+	// The rolling window keeps the last 3 days
+	for day := 3; day <= 6; day++ {
+		if _, err := engine.Transform(ctx, "whoop", whoopPayload(day, 50+float64(day))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := baselineCount(t, engine); n != 3 {
+		t.Errorf("expected 3 days of baselines, got %d", n)
+	}
+
+	if _, err := NewEngineWithOptions(ctx, Options{Timezone: "Mars/Olympus"}); err == nil {
+		t.Error("expected an unknown timezone to be rejected")
+	}
+	if _, err := engine.Transform(ctx, "polar", "{}"); err == nil || !strings.Contains(err.Error(), "no transform for polar") {
+		t.Errorf("expected polar to have no transform, got %v", err)
+	}
+}
+
+func TestEngineBaselineSnapshots(t *testing.T) {
+	ctx := context.Background()
+	engine := newTestEngine(t, Options{})
+	for day := 2; day <= 4; day++ {
+		if _, err := engine.Transform(ctx, "whoop", whoopPayload(day, 60)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshot, err := engine.Baselines(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.ResetBaselines(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := baselineCount(t, engine); n != 0 {
+		t.Errorf("expected no baselines after reset, got %d days", n)
+	}
+
+	restored := newTestEngine(t, Options{})
+	if err := restored.LoadBaselines(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if n := baselineCount(t, restored); n != 3 {
+		t.Errorf("expected 3 days of baselines after loading the snapshot, got %d", n)
+	}
+	if err := restored.LoadBaselines(ctx, "{bad"); err == nil {
+		t.Error("expected an invalid snapshot to be rejected")
+	}
+}
+
+func TestDetectVendor(t *testing.T) {
+	tests := []struct {
+		payload string
+		vendor  string
+		err     string
+	}{
+		{payload: whoopPayload(2, 50), vendor: "whoop"},
+		{payload: `{"recovery":[]}`, vendor: "whoop"},
+		{payload: `{"dailies":[],"sleep":[]}`, vendor: "garmin"},
+		{payload: `{"sleep":[]}`, err: "unrecognized payload"},
+		{payload: `[1,2]`, err: "invalid payload"},
+	}
+	for _, test := range tests {
+		vendor, err := DetectVendor([]byte(test.payload))
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error containing %q, got %v", test.payload, test.err, err)
+			}
+			continue
+		}
+		if err != nil || vendor != test.vendor {
+			t.Errorf("%s: expected %s, got %s (%v)", test.payload, test.vendor, vendor, err)
+		}
+	}
+}
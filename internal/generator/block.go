@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// blockRateThreshold is the slowest rate batched by Config.Block; slower signals
+// keep one event per sample unless their config sets a block size
+const blockRateThreshold = 100 * time.Millisecond // 10hz
+
+// sampleBlock collects consecutive samples of one signal for a single event
+type sampleBlock struct {
+	start  time.Time
+	period time.Duration
+	config *scenario.SignalConfig // config of the first sample
+	values []interface{}
+	tags   []string
+}
+
+// blockSize returns the number of samples per event for a signal emitted every
+// period, or 0 to emit each sample as its own event
+func (g *Generator) blockSize(signalName string, config *scenario.SignalConfig, period time.Duration) int {
+	if !isBlockSignal(signalName) {
+		return 0
+	}
+	if config.Block > 0 {
+		return config.Block
+	}
+	if g.block > 0 && period <= blockRateThreshold {
+		return g.block
+	}
+	return 0
+}
+
+func isBlockSignal(signalName string) bool {
+	for _, name := range scenario.BlockSignals {
+		if name == signalName {
+			return true
+		}
+	}
+	return false
+}
+
+// addToBlock appends a sample taken at the given time to the signal's pending
+// block and returns any events completed by it. A sample that doesn't follow the
+// previous one by one period (a pause, phase jump or rate change) closes the
+// pending block early, so the samples of a block are always evenly spaced.
+func (g *Generator) addToBlock(src *sourceState, signalName string, config *scenario.SignalConfig, value interface{}, at time.Time, period time.Duration, size int, tags []string) []models.Event {
+	var events []models.Event
+
+	b := src.blocks[signalName]
+	if b != nil {
+		expected := b.start.Add(time.Duration(len(b.values)) * period)
+		if drift := at.Sub(expected); b.period != period || drift < -period/2 || drift > period/2 {
+			events = append(events, g.blockEvent(src, signalName, config, b))
+			b = nil
+		}
+	}
+	if b == nil {
+		b = &sampleBlock{start: at, period: period, config: config}
+		src.blocks[signalName] = b
+	}
+
+	b.values = append(b.values, value)
+	b.tags = appendTags(b.tags, tags)
+	if len(b.values) >= size {
+		events = append(events, g.blockEvent(src, signalName, config, b))
+		delete(src.blocks, signalName)
+	}
+	return events
+}
+
+// flushBlocks returns an event for every pending partial block, in source and
+// signal order, so the trailing samples of a run are not lost when it ends
+func (g *Generator) flushBlocks() []models.Event {
+	var events []models.Event
+	for _, src := range g.sources {
+		for _, signalName := range src.signalNames {
+			if b := src.blocks[signalName]; b != nil {
+				events = append(events, g.blockEvent(src, signalName, b.config, b))
+				delete(src.blocks, signalName)
+			}
+		}
+	}
+	return events
+}
+
+// blockEvent creates the event for a block, timestamped at its first sample
+func (g *Generator) blockEvent(src *sourceState, signalName string, config *scenario.SignalConfig, b *sampleBlock) models.Event {
+	event := g.createEvent(src, signalName, blockValue(b.values), config, b.start)
+	event.Signal.SamplePeriodMs = float64(b.period) / float64(time.Millisecond)
+	event.Meta.Injections = b.tags
+	return event
+}
+
+// blockValue packs samples into []float64 for scalars or [][]float64 for vectors
+func blockValue(values []interface{}) interface{} {
+	if _, ok := values[0].([]float64); ok {
+		vectors := make([][]float64, 0, len(values))
+		for _, v := range values {
+			vectors = append(vectors, v.([]float64))
+		}
+		return vectors
+	}
+	scalars := make([]float64, 0, len(values))
+	for _, v := range values {
+		scalars = append(scalars, v.(float64))
+	}
+	return scalars
+}
+
+// appendTags adds the injection names not already present
+func appendTags(tags, more []string) []string {
+	for _, tag := range more {
+		found := false
+		for _, existing := range tags {
+			if existing == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,91 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestGenerateBlocks(t *testing.T) {
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	single := renderVirtualAt(t, testScenario(), 42, epoch)
+	blocked := renderVirtualConfig(t, testScenario(), Config{Seed: 42, Block: 10}, epoch)
+
+	samples := 0
+	for _, event := range single {
+		if event.Signal.Name == "accel.xyz_mps2" {
+			samples++
+		}
+	}
+
+	var blocks []models.Event
+	for _, event := range blocked {
+		switch event.Signal.Name {
+		case "accel.xyz_mps2":
+			blocks = append(blocks, event)
+		default:
+			// Signals slower than 10hz are not batched
+			if event.Signal.IsBlock() {
+				t.Errorf("unexpected block for %s", event.Signal.Name)
+			}
+		}
+	}
+
+	// Every sample is delivered: full blocks, then the partial last block
+	// flushed when the scenario completes
+	if want := (samples + 9) / 10; len(blocks) != want {
+		t.Fatalf("expected %d accel blocks from %d samples, got %d", want, samples, len(blocks))
+	}
+	blockedSamples := 0
+	for i, event := range blocks {
+		vectors, ok := event.Signal.Value.([][]float64)
+		if !ok || (len(vectors) != 10 && i != len(blocks)-1) {
+			t.Fatalf("block %d: expected 10 vector samples, got %v", i, event.Signal.Value)
+		}
+		blockedSamples += len(vectors)
+		if event.Signal.SamplePeriodMs != 100 {
+			t.Errorf("block %d: expected a 100ms sample period, got %v", i, event.Signal.SamplePeriodMs)
+		}
+		for j, vector := range vectors {
+			if len(vector) != 3 {
+				t.Fatalf("block %d sample %d: expected a 3-axis vector", i, j)
+			}
+		}
+	}
+	if blockedSamples != samples {
+		t.Errorf("expected %d samples in blocks, got %d", samples, blockedSamples)
+	}
+	if blocks[0].Timestamp != "2025-01-01T00:00:00.1Z" || blocks[1].Timestamp != "2025-01-01T00:00:01.1Z" {
+		t.Errorf("expected blocks timestamped at their first sample, got %s and %s", blocks[0].Timestamp, blocks[1].Timestamp)
+	}
+}
+
+func TestBlockClosesOnGap(t *testing.T) {
+	gen := &Generator{engine: scenario.NewEngine(testScenario())}
+	src := newSourceState(models.Source{ID: "test"}, 1, nil, 0, 0)
+	config := &scenario.SignalConfig{}
+	period := 20 * time.Millisecond
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var events []models.Event
+	for i := 0; i < 3; i++ {
+		events = append(events, gen.addToBlock(src, "eda.us", config, float64(i), start.Add(time.Duration(i)*period), period, 5, nil)...)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events before the block fills, got %d", len(events))
+	}
+
+	// A sample one second later can't continue the block
+	events = gen.addToBlock(src, "eda.us", config, 3.0, start.Add(time.Second), period, 5, []string{"spike"})
+	if len(events) != 1 {
+		t.Fatalf("expected the pending block to close early, got %d events", len(events))
+	}
+	if values := events[0].Signal.Value.([]float64); len(values) != 3 {
+		t.Errorf("expected a 3-sample block, got %v", values)
+	}
+	if pending := src.blocks["eda.us"]; pending == nil || len(pending.values) != 1 || pending.tags[0] != "spike" {
+		t.Errorf("expected a new block started by the late sample, got %+v", pending)
+	}
+}
@@ -2,11 +2,26 @@ package generator
 
 import (
 	"math"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// Accelerometer magnitudes (m/s²) kept consistent with the motion.activity state
+const (
+	stillMaxAccel = 10.5 // above this while still, scale back to stillAccel
+	stillAccel    = 9.85
+	walkMinAccel  = 10.0 // outside walkMinAccel-walkMaxAccel while walking, rescale to walkAccel
+	walkMaxAccel  = 15.0
+	walkAccel     = 11.0 // plus half the x axis, so walking keeps some variation
+	runMinAccel   = 12.0 // below this while running, boost to runAccel
+	runAccel      = 13.0
 )
 
 // CorrelationContext holds generated signal values for correlation
 type CorrelationContext struct {
 	values map[string]interface{}
+	motion string // motion.activity state in effect on ticks that don't emit it
 }
 
 // NewCorrelationContext creates a new correlation context
@@ -27,81 +42,249 @@ func (c *CorrelationContext) Get(name string) (interface{}, bool) {
 	return val, ok
 }
 
-// ApplyCorrelations applies correlation rules between signals
-func (c *CorrelationContext) ApplyCorrelations() {
-	// HR ↔ Accel: Higher acceleration should correlate with higher HR
-	if accel, ok := c.Get("accel.xyz_mps2"); ok {
-		if hr, ok := c.Get("ppg.hr_bpm"); ok {
-			accelVec := accel.([]float64)
-			magnitude := math.Sqrt(accelVec[0]*accelVec[0] + accelVec[1]*accelVec[1] + accelVec[2]*accelVec[2])
+// Delete removes a signal value
+func (c *CorrelationContext) Delete(name string) {
+	delete(c.values, name)
+}
+
+// applyMotionConsistency keeps the accelerometer magnitude in the range of the
+// current motion.activity state
+func (c *CorrelationContext) applyMotionConsistency() {
+	motion, ok := c.Get("motion.activity")
+	if !ok {
+		if c.motion == "" {
+			return
+		}
+		motion = c.motion
+	}
+	accel, ok := c.Get("accel.xyz_mps2")
+	if !ok {
+		return
+	}
+	accelVec := accel.([]float64)
+	magnitude := vectorMagnitude(accelVec)
+
+	target := 0.0
+	switch motion.(string) {
+	case "still":
+		if magnitude > stillMaxAccel {
+			target = stillAccel
+		}
+	case "walk":
+		if magnitude < walkMinAccel || magnitude > walkMaxAccel {
+			target = walkAccel + math.Abs(accelVec[0])*0.5
+		}
+	case "run":
+		if magnitude < runMinAccel {
+			target = runAccel
+		}
+	}
+	if target > 0 {
+		c.Set("accel.xyz_mps2", scaleVector(accelVec, target/magnitude))
+	}
+}
+
+// timedValue is a source signal value at a point in scenario time
+type timedValue struct {
+	at    time.Duration
+	value float64
+}
+
+// correlator applies a scenario's correlation rules within one source. It keeps
+// the latest value of every source signal, so a rule still applies on ticks where
+// only its target is emitted, and a history of values for lagged rules.
+type correlator struct {
+	scenario *scenario.Scenario
+	rules    []scenario.CorrelationRule
+	latest   map[string]float64
+	history  map[string][]timedValue // sources of lagged rules
+	keep     time.Duration           // longest lag delay
+	last     time.Duration
+}
 
-			// If high acceleration (>11 m/s²), nudge HR up slightly
-			if magnitude > 11.0 {
-				hrVal := hr.(float64)
-				hrVal += (magnitude - 11.0) * 2.0 // Small correlation factor
-				c.Set("ppg.hr_bpm", clamp(hrVal, 40, 200))
+func newCorrelator(scen *scenario.Scenario) *correlator {
+	c := &correlator{
+		scenario: scen,
+		rules:    scen.CorrelationRules(),
+		latest:   make(map[string]float64),
+		history:  make(map[string][]timedValue),
+	}
+	for _, rule := range c.rules {
+		if rule.Transform == scenario.TransformLag {
+			c.history[rule.Source] = nil
+			if delay := rule.DelayDuration(); delay > c.keep {
+				c.keep = delay
 			}
 		}
 	}
+	return c
+}
 
-	// HRV ↔ EDA: Higher stress (EDA) should reduce HRV
-	if eda, ok := c.Get("eda.us"); ok {
-		if hrv, ok := c.Get("ppg.hrv_rmssd_ms"); ok {
-			edaVal := eda.(float64)
-			hrvVal := hrv.(float64)
+// apply records the tick's source values, runs the rules over the context in
+// order, then keeps the accelerometer consistent with the motion state. Time
+// moving backwards clears the history.
+func (c *correlator) apply(ctx *CorrelationContext, elapsed time.Duration) {
+	if elapsed < c.last {
+		c.latest = make(map[string]float64)
+		for name := range c.history {
+			c.history[name] = nil
+		}
+	}
+	c.last = elapsed
 
-			// If EDA is elevated (>4.0), reduce HRV
-			if edaVal > 4.0 {
-				factor := 1.0 - (edaVal-4.0)*0.05
-				if factor < 0.6 {
-					factor = 0.6
-				}
-				c.Set("ppg.hrv_rmssd_ms", clamp(hrvVal*factor, 10, 150))
+	for _, rule := range c.rules {
+		if value, ok := ctx.Get(rule.Source); ok {
+			if x, ok := numericValue(value, rule.Levels); ok {
+				c.latest[rule.Source] = x
 			}
 		}
 	}
+	for name := range c.history {
+		if x, ok := c.latest[name]; ok {
+			c.record(name, elapsed, x)
+		}
+	}
+
+	for _, rule := range c.rules {
+		target, ok := ctx.Get(rule.Target)
+		if !ok {
+			continue
+		}
+		x, ok := c.sourceValue(ctx, rule, elapsed)
+		if !ok {
+			continue
+		}
 
-	// Motion activity ↔ Accel: Ensure consistency
-	if motion, ok := c.Get("motion.activity"); ok {
-		if accel, ok := c.Get("accel.xyz_mps2"); ok {
-			motionStr := motion.(string)
-			accelVec := accel.([]float64)
-			magnitude := math.Sqrt(accelVec[0]*accelVec[0] + accelVec[1]*accelVec[1] + accelVec[2]*accelVec[2])
-
-			// Adjust magnitude based on motion state for consistency
-			switch motionStr {
-			case "still":
-				if magnitude > 10.5 {
-					// Reduce to near-gravity
-					factor := 9.85 / magnitude
-					c.Set("accel.xyz_mps2", []float64{
-						accelVec[0] * factor,
-						accelVec[1] * factor,
-						accelVec[2] * factor,
-					})
-				}
-			case "walk":
-				if magnitude < 10.0 || magnitude > 15.0 {
-					// Keep in walking range
-					target := 11.0 + math.Abs(accelVec[0])*0.5
-					factor := target / magnitude
-					c.Set("accel.xyz_mps2", []float64{
-						accelVec[0] * factor,
-						accelVec[1] * factor,
-						accelVec[2] * factor,
-					})
-				}
-			case "run":
-				if magnitude < 12.0 {
-					// Boost to running range
-					factor := 13.0 / magnitude
-					c.Set("accel.xyz_mps2", []float64{
-						accelVec[0] * factor,
-						accelVec[1] * factor,
-						accelVec[2] * factor,
-					})
-				}
+		var delta float64
+		switch rule.Transform {
+		case scenario.TransformThreshold:
+			if x <= rule.Threshold {
+				continue
 			}
+			delta = rule.Coefficient * (x - rule.Threshold)
+		default:
+			delta = rule.Coefficient * (x - rule.Offset)
+		}
+		if rule.EffectMin != nil && delta < *rule.EffectMin {
+			delta = *rule.EffectMin
+		}
+		if rule.EffectMax != nil && delta > *rule.EffectMax {
+			delta = *rule.EffectMax
+		}
+
+		if value, ok := applyCorrelation(target, rule, delta); ok {
+			ctx.Set(rule.Target, value)
 		}
 	}
+
+	ctx.applyMotionConsistency()
+}
+
+// sourceValue returns a rule's input: the delayed value for lagged rules, else the
+// value this tick (after earlier rules). A source not due this tick contributes
+// the last value it emitted, so a slow source keeps driving a faster target
+// between its samples, at most one source period stale.
+func (c *correlator) sourceValue(ctx *CorrelationContext, rule scenario.CorrelationRule, elapsed time.Duration) (float64, bool) {
+	if rule.Transform == scenario.TransformLag {
+		return c.valueAt(rule.Source, elapsed-rule.DelayDuration())
+	}
+	if value, ok := ctx.Get(rule.Source); ok {
+		return numericValue(value, rule.Levels)
+	}
+	x, ok := c.latest[rule.Source]
+	return x, ok
+}
+
+// record appends a value to a signal's history, dropping values no lag can reach.
+// One value older than the longest delay is kept as the value in effect then.
+func (c *correlator) record(name string, at time.Duration, value float64) {
+	history := append(c.history[name], timedValue{at: at, value: value})
+	cutoff := at - c.keep
+	i := 0
+	for i+1 < len(history) && history[i+1].at <= cutoff {
+		i++
+	}
+	c.history[name] = history[i:]
+}
+
+// valueAt returns the value of a signal in effect at t. Before the history
+// begins, the oldest value is assumed to have held.
+func (c *correlator) valueAt(name string, t time.Duration) (float64, bool) {
+	history := c.history[name]
+	if len(history) == 0 {
+		return 0, false
+	}
+	value := history[0].value
+	for _, v := range history {
+		if v.at > t {
+			break
+		}
+		value = v.value
+	}
+	return value, true
+}
+
+// numericValue converts a signal value to a rule input: numbers as is, vectors by
+// magnitude and discrete states through levels
+func numericValue(value interface{}, levels map[string]float64) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case []float64:
+		return vectorMagnitude(v), true
+	case string:
+		return levels[v], true
+	}
+	return 0, false
+}
+
+// applyCorrelation changes a target value by delta per the rule's effect and
+// clamps the result. Vector targets are scaled, with clamps on their magnitude.
+func applyCorrelation(target interface{}, rule scenario.CorrelationRule, delta float64) (interface{}, bool) {
+	switch v := target.(type) {
+	case float64:
+		if rule.Effect == scenario.EffectMultiply {
+			v *= 1 + delta
+		} else {
+			v += delta
+		}
+		return clampRule(v, rule), true
+	case []float64:
+		magnitude := vectorMagnitude(v)
+		if magnitude == 0 {
+			return v, true
+		}
+		scaled := magnitude + delta
+		if rule.Effect == scenario.EffectMultiply {
+			scaled = magnitude * (1 + delta)
+		}
+		return scaleVector(v, clampRule(scaled, rule)/magnitude), true
+	}
+	return nil, false
+}
+
+func clampRule(value float64, rule scenario.CorrelationRule) float64 {
+	if rule.Min != nil && value < *rule.Min {
+		value = *rule.Min
+	}
+	if rule.Max != nil && value > *rule.Max {
+		value = *rule.Max
+	}
+	return value
+}
+
+func vectorMagnitude(v []float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+func scaleVector(v []float64, factor float64) []float64 {
+	scaled := make([]float64, len(v))
+	for i, x := range v {
+		scaled[i] = x * factor
+	}
+	return scaled
 }
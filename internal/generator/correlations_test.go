@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestDefaultCorrelations(t *testing.T) {
+	c := newCorrelator(&scenario.Scenario{})
+
+	ctx := NewCorrelationContext()
+	ctx.Set("accel.xyz_mps2", []float64{0, 5, 12}) // magnitude 13
+	ctx.Set("ppg.hr_bpm", 70.0)
+	ctx.Set("eda.us", 6.0)
+	ctx.Set("ppg.hrv_rmssd_ms", 50.0)
+	c.apply(ctx, 0)
+
+	if hr, _ := ctx.Get("ppg.hr_bpm"); math.Abs(hr.(float64)-74) > 1e-9 {
+		t.Errorf("expected HR raised by exertion to 74, got %v", hr)
+	}
+	if hrv, _ := ctx.Get("ppg.hrv_rmssd_ms"); math.Abs(hrv.(float64)-45) > 1e-9 {
+		t.Errorf("expected HRV lowered by stress to 45, got %v", hrv)
+	}
+
+	// EDA far above the threshold hits the effect floor of -40%
+	ctx.Set("eda.us", 20.0)
+	ctx.Set("ppg.hrv_rmssd_ms", 50.0)
+	c.apply(ctx, time.Second)
+	if hrv, _ := ctx.Get("ppg.hrv_rmssd_ms"); math.Abs(hrv.(float64)-30) > 1e-9 {
+		t.Errorf("expected HRV clamped to a 40%% reduction, got %v", hrv)
+	}
+}
+
+func TestCorrelationLinearClamp(t *testing.T) {
+	max := 38.0
+	c := newCorrelator(&scenario.Scenario{Correlations: []scenario.CorrelationRule{{
+		Source:      "eda.us",
+		Target:      "temp.skin_c",
+		Transform:   scenario.TransformLinear,
+		Offset:      2,
+		Coefficient: 0.5,
+		Max:         &max,
+	}}})
+
+	ctx := NewCorrelationContext()
+	ctx.Set("eda.us", 4.0)
+	ctx.Set("temp.skin_c", 33.0)
+	c.apply(ctx, 0)
+	if temp, _ := ctx.Get("temp.skin_c"); temp != 34.0 {
+		t.Errorf("expected 33 + 0.5*(4-2) = 34, got %v", temp)
+	}
+
+	// The source isn't emitted this tick: its latest value still applies
+	ctx = NewCorrelationContext()
+	ctx.Set("temp.skin_c", 37.5)
+	c.apply(ctx, time.Second)
+	if temp, _ := ctx.Get("temp.skin_c"); temp != max {
+		t.Errorf("expected the result clamped to %v, got %v", max, temp)
+	}
+}
+
+func TestCorrelationHeldSource(t *testing.T) {
+	c := newCorrelator(&scenario.Scenario{Correlations: []scenario.CorrelationRule{{
+		Source:      "eda.us",
+		Target:      "ppg.hr_bpm",
+		Transform:   scenario.TransformLinear,
+		Coefficient: 2,
+	}}})
+
+	ctx := NewCorrelationContext()
+	ctx.Set("eda.us", 3.0)
+	ctx.Set("ppg.hr_bpm", 70.0)
+	c.apply(ctx, 0)
+
+	// A tick where only the target is due uses the source's last value
+	ctx = NewCorrelationContext()
+	ctx.Set("ppg.hr_bpm", 70.0)
+	c.apply(ctx, 40*time.Millisecond)
+	if hr, _ := ctx.Get("ppg.hr_bpm"); hr != 76.0 {
+		t.Errorf("expected the last eda.us value to apply, got %v", hr)
+	}
+}
+
+func TestCorrelationLag(t *testing.T) {
+	c := newCorrelator(&scenario.Scenario{Correlations: []scenario.CorrelationRule{{
+		Name:        "hr_follows_motion",
+		Source:      "motion.activity",
+		Target:      "ppg.hr_bpm",
+		Transform:   scenario.TransformLag,
+		Delay:       "10s",
+		Levels:      map[string]float64{"walk": 20},
+		Coefficient: 1,
+	}}})
+
+	hrAt := make(map[time.Duration]float64)
+	for elapsed := time.Duration(0); elapsed <= 30*time.Second; elapsed += time.Second {
+		motion := "still"
+		if elapsed >= 5*time.Second {
+			motion = "walk"
+		}
+		ctx := NewCorrelationContext()
+		ctx.Set("motion.activity", motion)
+		ctx.Set("ppg.hr_bpm", 70.0)
+		c.apply(ctx, elapsed)
+		hr, _ := ctx.Get("ppg.hr_bpm")
+		hrAt[elapsed] = hr.(float64)
+	}
+
+	// Walking starts at 5s, so HR follows from 15s
+	if hrAt[10*time.Second] != 70 || hrAt[14*time.Second] != 70 {
+		t.Errorf("expected no effect before the delay, got %v at 10s and %v at 14s", hrAt[10*time.Second], hrAt[14*time.Second])
+	}
+	if hrAt[15*time.Second] != 90 || hrAt[30*time.Second] != 90 {
+		t.Errorf("expected HR raised 10s after motion onset, got %v at 15s and %v at 30s", hrAt[15*time.Second], hrAt[30*time.Second])
+	}
+}
+
+func TestMotionConsistency(t *testing.T) {
+	c := newCorrelator(&scenario.Scenario{Correlations: []scenario.CorrelationRule{}})
+
+	ctx := NewCorrelationContext()
+	ctx.Set("motion.activity", "run")
+	ctx.Set("accel.xyz_mps2", []float64{0, 0, 9.81})
+	c.apply(ctx, 0)
+
+	accel, _ := ctx.Get("accel.xyz_mps2")
+	if magnitude := vectorMagnitude(accel.([]float64)); math.Abs(magnitude-runAccel) > 1e-9 {
+		t.Errorf("expected accel boosted to the running range, got magnitude %v", magnitude)
+	}
+}
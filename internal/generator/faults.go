@@ -0,0 +1,392 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// faultSeedSalt separates fault decisions from the signal and ID streams so
+// enabling a fault doesn't change the values it leaves untouched
+const faultSeedSalt = 0xfa017
+
+// defaultReorderDelay is how late reordered events arrive when the fault sets no delay
+const defaultReorderDelay = time.Second
+
+// faultQuality is the quality reported for events shaped by each sensor fault.
+// Delivery faults (dropout, duplicate, reorder) leave quality alone.
+var faultQuality = map[string]float64{
+	scenario.FaultStuck:    0.3,
+	scenario.FaultSaturate: 0.2,
+	scenario.FaultTimeJump: 0.5,
+	scenario.FaultSkew:     0.7,
+}
+
+// signalLimits are the range limits values saturate at, matching the clamps and
+// sensor ranges of the generators (±8 g, ±2000 °/s)
+var signalLimits = map[string][2]float64{
+	"ppg.hr_bpm":       {40, 200},
+	"ppg.hrv_rmssd_ms": {10, 150},
+	"ppg.rr_ms":        {minRR, maxRR},
+	"ppg.raw":          {-1, 3},
+	"accel.xyz_mps2":   {-8 * gravity, 8 * gravity},
+	"gyro.xyz_rps":     {-34.9, 34.9},
+	"temp.skin_c":      {30, 37},
+	"eda.us":           {0.1, 20},
+}
+
+// faultState tracks the episodes of one declared fault
+type faultState struct {
+	config   scenario.FaultConfig
+	mean     time.Duration // mean interval between episodes, 0 if always active
+	duration time.Duration
+	next     time.Duration // start of the current or next episode
+	start    time.Duration // start of the current episode, or of the fault's scope
+	active   bool
+	pinned   map[string]interface{} // stuck: value held per source and signal
+}
+
+// heldEvent is an event delayed by a reorder fault
+type heldEvent struct {
+	due   time.Duration
+	event models.Event
+}
+
+// faultLayer applies sensor and delivery faults to each tick's events before they
+// leave the generator. Faults come from the scenario, the current phase and the
+// command line. Random decisions are drawn from a seeded RNG as time moves
+// forward, so a run with the same seed and tick sequence faults the same events.
+type faultLayer struct {
+	seed     int64
+	extra    []scenario.FaultConfig // from the command line, active for the whole run
+	scenario *scenario.Scenario
+	rng      *rand.Rand
+	states   map[string]*faultState // keyed by scope and index
+	held     []heldEvent
+	last     time.Duration
+}
+
+func newFaultLayer(seed int64, extra []scenario.FaultConfig) *faultLayer {
+	f := &faultLayer{seed: seed, extra: extra}
+	f.reset(nil, 0)
+	return f
+}
+
+// reset forgets every episode, stuck value and held event
+func (f *faultLayer) reset(scen *scenario.Scenario, elapsed time.Duration) {
+	f.scenario = scen
+	f.rng = rand.New(rand.NewSource(f.seed ^ faultSeedSalt))
+	f.states = make(map[string]*faultState)
+	f.held = nil
+	f.last = elapsed
+}
+
+// apply returns the events of a tick as delivered through the active faults.
+// Events held back by a reorder fault are delivered after the tick's own events
+// once their delay has passed.
+func (f *faultLayer) apply(events []models.Event, scen *scenario.Scenario, phase *scenario.Phase, elapsed time.Duration) []models.Event {
+	if scen != f.scenario || elapsed < f.last {
+		f.reset(scen, elapsed)
+	}
+	f.last = elapsed
+
+	active := f.active(scen, phase, elapsed)
+	if len(active) == 0 && len(f.held) == 0 {
+		return events
+	}
+
+	out := make([]models.Event, 0, len(events))
+	for _, event := range events {
+		out = append(out, f.applyEvent(event, active, elapsed)...)
+	}
+
+	remaining := f.held[:0]
+	for _, h := range f.held {
+		if h.due <= elapsed {
+			out = append(out, h.event)
+		} else {
+			remaining = append(remaining, h)
+		}
+	}
+	f.held = remaining
+	return out
+}
+
+// flush returns every event still held by a reorder fault, in the order they
+// were due, so none are lost when the run ends
+func (f *faultLayer) flush() []models.Event {
+	sort.SliceStable(f.held, func(i, j int) bool { return f.held[i].due < f.held[j].due })
+	events := make([]models.Event, 0, len(f.held))
+	for _, h := range f.held {
+		events = append(events, h.event)
+	}
+	f.held = nil
+	return events
+}
+
+// active returns the faults in effect at elapsed. Faults of phases no longer
+// current are dropped, so re-entering a phase starts its faults afresh.
+func (f *faultLayer) active(scen *scenario.Scenario, phase *scenario.Phase, elapsed time.Duration) []*faultState {
+	scopes := map[string][]scenario.FaultConfig{"cli": f.extra}
+	if scen != nil {
+		scopes["scenario"] = scen.Faults
+	}
+	phaseScope := ""
+	if phase != nil {
+		phaseScope = "phase:" + phase.Name
+		scopes[phaseScope] = phase.Faults
+	}
+	for key := range f.states {
+		if strings.HasPrefix(key, "phase:") && !strings.HasPrefix(key, phaseScope+"/") {
+			delete(f.states, key)
+		}
+	}
+
+	var active []*faultState
+	for _, scope := range []string{"scenario", phaseScope, "cli"} {
+		for i, config := range scopes[scope] {
+			key := fmt.Sprintf("%s/%d", scope, i)
+			state := f.states[key]
+			if state == nil {
+				state = f.newState(config, elapsed)
+				f.states[key] = state
+			}
+			if f.isActive(state, elapsed) {
+				active = append(active, state)
+			}
+		}
+	}
+	return active
+}
+
+func (f *faultLayer) newState(config scenario.FaultConfig, elapsed time.Duration) *faultState {
+	state := &faultState{config: config, start: elapsed}
+	if config.Every != "" {
+		state.mean, _ = time.ParseDuration(config.Every)
+		state.duration, _ = time.ParseDuration(config.Duration)
+	}
+	if state.mean > 0 {
+		state.next = elapsed + f.interval(state.mean)
+	}
+	return state
+}
+
+// interval draws the time until the next episode
+func (f *faultLayer) interval(mean time.Duration) time.Duration {
+	return time.Duration(f.rng.ExpFloat64() * float64(mean))
+}
+
+// isActive reports whether the fault is in effect at elapsed, drawing new
+// episodes as earlier ones finish. Stuck values are released between episodes.
+func (f *faultLayer) isActive(s *faultState, elapsed time.Duration) bool {
+	active := true
+	if s.mean > 0 {
+		for elapsed >= s.next+s.duration {
+			s.next += f.interval(s.mean)
+		}
+		active = elapsed >= s.next
+		if active && !s.active {
+			s.start = s.next
+		}
+	}
+	if !active {
+		s.pinned = nil
+	}
+	s.active = active
+	return active
+}
+
+// applyEvent runs one event through the active faults, returning the events
+// delivered now: none if dropped or held back, two if duplicated
+func (f *faultLayer) applyEvent(event models.Event, active []*faultState, elapsed time.Duration) []models.Event {
+	var duplicates []models.Event
+	for _, s := range active {
+		config := s.config
+		if !config.AffectsSignal(event.Signal.Name) {
+			continue
+		}
+		if config.Probability > 0 && f.rng.Float64() >= config.Probability {
+			continue
+		}
+
+		switch config.Type {
+		case scenario.FaultDropout:
+			return nil
+		case scenario.FaultStuck:
+			key := event.Source.ID + "/" + event.Signal.Name
+			if s.pinned == nil {
+				s.pinned = make(map[string]interface{})
+			}
+			if _, ok := s.pinned[key]; !ok {
+				s.pinned[key] = firstSample(event.Signal)
+			}
+			event.Signal.Value = fillSamples(event.Signal, s.pinned[key])
+		case scenario.FaultSaturate:
+			limits, ok := signalLimits[event.Signal.Name]
+			if !ok {
+				continue
+			}
+			limit := limits[1]
+			if config.Limit == "low" {
+				limit = limits[0]
+			}
+			event.Signal.Value = fillSamples(event.Signal, saturated(firstSample(event.Signal), limit))
+		case scenario.FaultTimeJump:
+			offset, _ := time.ParseDuration(config.Offset)
+			event.Timestamp = shiftTimestamp(event.Timestamp, offset)
+		case scenario.FaultSkew:
+			perMinute, _ := time.ParseDuration(config.Offset)
+			drift := time.Duration(float64(perMinute) * (elapsed - s.start).Minutes())
+			event.Timestamp = shiftTimestamp(event.Timestamp, drift)
+		case scenario.FaultDuplicate:
+			duplicate := event
+			duplicate.Meta.Faults = appendTags(append([]string(nil), event.Meta.Faults...), []string{config.Type})
+			duplicates = append(duplicates, duplicate)
+			continue
+		case scenario.FaultReorder:
+			delay := defaultReorderDelay
+			if config.Delay != "" {
+				delay, _ = time.ParseDuration(config.Delay)
+			}
+			event.Meta.Faults = appendTags(event.Meta.Faults, []string{config.Type})
+			f.held = append(f.held, heldEvent{due: elapsed + delay, event: event})
+			return nil
+		}
+
+		event.Meta.Faults = appendTags(event.Meta.Faults, []string{config.Type})
+		if quality, ok := faultQuality[config.Type]; ok || config.Quality != nil {
+			if config.Quality != nil {
+				quality = *config.Quality
+			}
+			if quality < event.Signal.Quality {
+				event.Signal.Quality = quality
+			}
+		}
+	}
+	return append([]models.Event{event}, duplicates...)
+}
+
+// firstSample returns the signal's value, or its first sample for a block
+func firstSample(signal models.Signal) interface{} {
+	if !signal.IsBlock() {
+		return signal.Value
+	}
+	switch v := signal.Value.(type) {
+	case []float64:
+		if len(v) > 0 {
+			return v[0]
+		}
+	case [][]float64:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return signal.Value
+}
+
+// fillSamples returns the signal's value with every sample replaced by sample
+func fillSamples(signal models.Signal, sample interface{}) interface{} {
+	if !signal.IsBlock() {
+		return sample
+	}
+	switch v := signal.Value.(type) {
+	case []float64:
+		if x, ok := sample.(float64); ok {
+			filled := make([]float64, len(v))
+			for i := range filled {
+				filled[i] = x
+			}
+			return filled
+		}
+	case [][]float64:
+		if vector, ok := sample.([]float64); ok {
+			filled := make([][]float64, len(v))
+			for i := range filled {
+				filled[i] = vector
+			}
+			return filled
+		}
+	}
+	return signal.Value
+}
+
+// saturated pins a scalar or every axis of a vector at limit
+func saturated(sample interface{}, limit float64) interface{} {
+	switch v := sample.(type) {
+	case float64:
+		return limit
+	case []float64:
+		pinned := make([]float64, len(v))
+		for i := range pinned {
+			pinned[i] = limit
+		}
+		return pinned
+	}
+	return sample
+}
+
+// shiftTimestamp moves an RFC 3339 timestamp by offset
+func shiftTimestamp(ts string, offset time.Duration) string {
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return ts
+	}
+	return t.Add(offset).UTC().Format(time.RFC3339Nano)
+}
+
+// ParseFaultSpec parses a --fault flag value such as
+// "type=dropout,signals=ppg.hr_bpm+eda.us,every=2m,duration=10s,probability=0.5"
+func ParseFaultSpec(spec string) (scenario.FaultConfig, error) {
+	var fault scenario.FaultConfig
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fault, fmt.Errorf("invalid fault field %q (expected key=value)", part)
+		}
+		switch strings.TrimSpace(key) {
+		case "type":
+			fault.Type = value
+		case "signals":
+			for _, name := range strings.Split(value, "+") {
+				if name = strings.TrimSpace(name); name != "" {
+					fault.Signals = append(fault.Signals, name)
+				}
+			}
+		case "every":
+			fault.Every = value
+		case "duration":
+			fault.Duration = value
+		case "probability":
+			p, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fault, fmt.Errorf("invalid fault probability %q", value)
+			}
+			fault.Probability = p
+		case "offset":
+			fault.Offset = value
+		case "limit":
+			fault.Limit = value
+		case "delay":
+			fault.Delay = value
+		case "quality":
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fault, fmt.Errorf("invalid fault quality %q", value)
+			}
+			fault.Quality = &q
+		default:
+			return fault, fmt.Errorf("unknown fault field %q", key)
+		}
+	}
+	return fault, fault.Validate(SignalNames())
+}
@@ -0,0 +1,190 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+var faultEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func faultScenario() *scenario.Scenario {
+	return &scenario.Scenario{
+		Name:     "faults",
+		Duration: "1m",
+		Signals: map[string]*scenario.SignalConfig{
+			"eda.us":         {Rate: "1hz"},
+			"accel.xyz_mps2": {Rate: "1hz"},
+		},
+	}
+}
+
+func renderFaults(t *testing.T, scen *scenario.Scenario, faults ...scenario.FaultConfig) []models.Event {
+	t.Helper()
+	return renderVirtualConfig(t, scen, Config{Seed: 42, Faults: faults}, faultEpoch)
+}
+
+func signalEvents(events []models.Event, name string) []models.Event {
+	var matched []models.Event
+	for _, event := range events {
+		if event.Signal.Name == name {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+func TestFaultsLeaveOtherSignals(t *testing.T) {
+	clean := renderFaults(t, faultScenario())
+	faulty := renderFaults(t, faultScenario(), scenario.FaultConfig{Type: scenario.FaultDropout, Signals: []string{"eda.us"}})
+
+	if n := len(signalEvents(faulty, "eda.us")); n != 0 {
+		t.Errorf("expected every eda.us event dropped, got %d", n)
+	}
+	cleanAccel, faultyAccel := signalEvents(clean, "accel.xyz_mps2"), signalEvents(faulty, "accel.xyz_mps2")
+	if len(cleanAccel) != len(faultyAccel) {
+		t.Fatalf("expected accel untouched, got %d events instead of %d", len(faultyAccel), len(cleanAccel))
+	}
+	for i := range cleanAccel {
+		if cleanAccel[i].EventID != faultyAccel[i].EventID || cleanAccel[i].Signal.Quality != faultyAccel[i].Signal.Quality {
+			t.Fatalf("expected accel event %d unchanged by the eda.us fault", i)
+		}
+	}
+}
+
+func TestFaultStuckAndSaturate(t *testing.T) {
+	events := renderFaults(t, faultScenario(),
+		scenario.FaultConfig{Type: scenario.FaultStuck, Signals: []string{"eda.us"}},
+		scenario.FaultConfig{Type: scenario.FaultSaturate, Signals: []string{"accel.xyz_mps2"}},
+	)
+
+	eda := signalEvents(events, "eda.us")
+	for _, event := range eda {
+		if event.Signal.Value != eda[0].Signal.Value {
+			t.Fatalf("expected a flatlined eda.us, got %v then %v", eda[0].Signal.Value, event.Signal.Value)
+		}
+		if event.Signal.Quality != 0.3 || len(event.Meta.Faults) != 1 || event.Meta.Faults[0] != "stuck" {
+			t.Fatalf("expected stuck events tagged with quality 0.3, got %v %v", event.Signal.Quality, event.Meta.Faults)
+		}
+	}
+
+	for _, event := range signalEvents(events, "accel.xyz_mps2") {
+		for _, axis := range event.Signal.Value.([]float64) {
+			if axis != 8*gravity {
+				t.Fatalf("expected accel pinned at +8 g, got %v", event.Signal.Value)
+			}
+		}
+		if event.Signal.Quality != 0.2 {
+			t.Fatalf("expected saturated quality 0.2, got %v", event.Signal.Quality)
+		}
+	}
+}
+
+func TestFaultDuplicateAndReorder(t *testing.T) {
+	events := renderFaults(t, faultScenario(), scenario.FaultConfig{Type: scenario.FaultDuplicate, Signals: []string{"eda.us"}})
+	eda := signalEvents(events, "eda.us")
+	if len(eda)%2 != 0 || len(eda) == 0 {
+		t.Fatalf("expected every eda.us event twice, got %d events", len(eda))
+	}
+	for i := 0; i < len(eda); i += 2 {
+		if eda[i].EventID != eda[i+1].EventID || eda[i].Meta.Sequence != eda[i+1].Meta.Sequence {
+			t.Fatalf("expected a duplicate with the same ID and sequence at %d", i)
+		}
+	}
+
+	events = renderFaults(t, faultScenario(), scenario.FaultConfig{Type: scenario.FaultReorder, Probability: 0.3, Delay: "3s"})
+	outOfOrder := 0
+	for i := 1; i < len(events); i++ {
+		if events[i].Meta.Sequence < events[i-1].Meta.Sequence {
+			outOfOrder++
+		}
+	}
+	if outOfOrder == 0 {
+		t.Error("expected reordered events to arrive after later sequences")
+	}
+}
+
+func TestFaultReorderFlushedAtEnd(t *testing.T) {
+	clean := renderFaults(t, faultScenario())
+	// Every event is held past the end of the run
+	events := renderFaults(t, faultScenario(), scenario.FaultConfig{Type: scenario.FaultReorder, Delay: "2m"})
+	if len(events) != len(clean) {
+		t.Fatalf("expected all %d events once the run ends, got %d", len(clean), len(events))
+	}
+	delivered := make(map[string]bool)
+	for _, event := range events {
+		delivered[event.EventID] = true
+	}
+	for _, event := range clean {
+		if !delivered[event.EventID] {
+			t.Fatalf("event %s was never delivered", event.EventID)
+		}
+	}
+}
+
+func TestFaultTimestamps(t *testing.T) {
+	clean := signalEvents(renderFaults(t, faultScenario()), "eda.us")
+	jumped := signalEvents(renderFaults(t, faultScenario(), scenario.FaultConfig{Type: scenario.FaultTimeJump, Offset: "-2s"}), "eda.us")
+	skewed := signalEvents(renderFaults(t, faultScenario(), scenario.FaultConfig{Type: scenario.FaultSkew, Offset: "1s"}), "eda.us")
+
+	for i := range clean {
+		ts, _ := time.Parse(time.RFC3339Nano, clean[i].Timestamp)
+		jumpedTs, _ := time.Parse(time.RFC3339Nano, jumped[i].Timestamp)
+		if ts.Sub(jumpedTs) != 2*time.Second {
+			t.Fatalf("expected timestamps 2s early, got %s for %s", jumped[i].Timestamp, clean[i].Timestamp)
+		}
+	}
+
+	// Skew of 1s per minute reaches about 1s by the end of the run
+	last := len(clean) - 1
+	ts, _ := time.Parse(time.RFC3339Nano, clean[last].Timestamp)
+	skewedTs, _ := time.Parse(time.RFC3339Nano, skewed[last].Timestamp)
+	if drift := skewedTs.Sub(ts); drift < 900*time.Millisecond || drift > time.Second {
+		t.Errorf("expected about 1s of drift after a minute, got %s", drift)
+	}
+}
+
+func TestPhaseFaultEpisodes(t *testing.T) {
+	scen := faultScenario()
+	scen.Duration = "10m"
+	scen.Phases = []scenario.Phase{
+		{Name: "clean", Duration: "5m"},
+		{Name: "flaky", Duration: "5m", Faults: []scenario.FaultConfig{
+			{Type: scenario.FaultDropout, Signals: []string{"eda.us"}, Every: "30s", Duration: "10s"},
+		}},
+	}
+
+	var early, late int
+	for _, event := range signalEvents(renderFaults(t, scen), "eda.us") {
+		ts, _ := time.Parse(time.RFC3339Nano, event.Timestamp)
+		if ts.Sub(faultEpoch) < 5*time.Minute {
+			early++
+		} else {
+			late++
+		}
+	}
+	if early < 299 {
+		t.Errorf("expected no dropouts before the flaky phase, got %d events", early)
+	}
+	if late > 280 || late < 150 {
+		t.Errorf("expected episodic gaps in the flaky phase, got %d of 300 events", late)
+	}
+}
+
+func TestParseFaultSpec(t *testing.T) {
+	fault, err := ParseFaultSpec("type=dropout,signals=ppg.hr_bpm+eda.us,every=2m,duration=10s,probability=0.5")
+	if err != nil {
+		t.Fatalf("ParseFaultSpec failed: %v", err)
+	}
+	if fault.Type != scenario.FaultDropout || len(fault.Signals) != 2 || fault.Every != "2m" || fault.Probability != 0.5 {
+		t.Errorf("unexpected fault %+v", fault)
+	}
+
+	for _, spec := range []string{"type=melt", "type=stuck,offset=2s", "type=dropout,every=1m", "type=dropout,color=red"} {
+		if _, err := ParseFaultSpec(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
@@ -7,175 +7,480 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/synheart/synheart-cli/internal/clock"
 	"github.com/synheart/synheart-cli/internal/models"
 	"github.com/synheart/synheart-cli/internal/scenario"
 )
 
+// idSeedSalt separates the ID stream from the signal stream so deterministic
+// IDs don't change the generated values for a given seed
+const idSeedSalt = 0x5eed1d5
+
 // Generator orchestrates signal generation based on scenario
 type Generator struct {
-	engine      *scenario.Engine
-	rng         *rand.Rand
-	runID       string
-	source      models.Source
-	sequence    int64
-	signals     map[string]SignalGenerator
-	signalRates map[string]time.Duration
-	lastEmit    map[string]time.Time
-	vendor      string
+	engine  *scenario.Engine
+	clock   clock.Clock
+	idRNG   *rand.Rand // nil unless IDs are derived from the seed
+	runID   string
+	signals map[string]SignalGenerator
+	sources []*sourceState
+	subject *subjectState // shared by the sources
+	vendor  VendorFormatter
+	window  WindowConfig
+	block   int // samples per event for block signals at 10hz or faster, 0 for none
+
+	seed     int64
+	injector *injector // rebuilt when the engine switches scenario
+	faults   *faultLayer
 }
 
 // Config holds generator configuration
 type Config struct {
 	Seed        int64
 	DefaultRate time.Duration
-	SourceType  string
+	SourceType  string // used when Sources is empty
 	SourceID    string
 	SourceSide  *string
-	Vendor      string // "whoop" or "garmin"
+	Sources     []scenario.SourceConfig // devices emitting together in one session
+	Vendor      VendorFormatter         // vendor payload format, defaults to Whoop
+	Window      WindowConfig            // vendor aggregation windows, defaults to one minute
+	Block       int                     // batch block signals at 10hz or faster into events of this many samples
+	Faults      []scenario.FaultConfig  // applied for the whole run, on top of the scenario's faults
+	Clock       clock.Clock             // defaults to the wall clock
+	// Deterministic derives the run ID and event IDs from the seed so that
+	// repeated runs with the same seed and clock produce identical output
+	Deterministic bool
 }
 
 // NewGenerator creates a new event generator
 func NewGenerator(engine *scenario.Engine, config Config) *Generator {
-	source := rand.NewSource(config.Seed)
-	rng := rand.New(source)
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real()
+	}
 
-	side := config.SourceSide
-	if side == nil && config.SourceType == "wearable" {
-		left := "left"
-		side = &left
+	names := SignalNames()
+
+	g := &Generator{
+		engine:  engine,
+		clock:   clk,
+		signals: GetAllSignals(),
+		subject: newSubjectState(config.Seed),
+		vendor:  config.Vendor,
+		window:  config.Window,
+		block:   config.Block,
+		seed:    config.Seed,
+		faults:  newFaultLayer(config.Seed, config.Faults),
 	}
 
-	return &Generator{
-		engine: engine,
-		rng:    rng,
-		runID:  uuid.New().String(),
-		source: models.Source{
+	if g.vendor == nil {
+		g.vendor = whoopFormatter{}
+	}
+
+	if len(config.Sources) == 0 {
+		side := config.SourceSide
+		if side == nil && config.SourceType == "wearable" {
+			left := "left"
+			side = &left
+		}
+		source := models.Source{
 			Type: config.SourceType,
 			ID:   config.SourceID,
 			Side: side,
-		},
-		sequence:    0,
-		signals:     GetAllSignals(),
-		signalRates: make(map[string]time.Duration),
-		lastEmit:    make(map[string]time.Time),
-		vendor:      config.Vendor,
+		}
+		g.sources = []*sourceState{newSourceState(source, config.Seed, names, 0, 0)}
+	} else {
+		for _, sc := range config.Sources {
+			g.sources = append(g.sources, newSourceStateFromConfig(sc, config.Seed, names))
+		}
 	}
+
+	if config.Deterministic {
+		g.idRNG = rand.New(rand.NewSource(config.Seed ^ idSeedSalt))
+	}
+	g.runID = g.newID()
+
+	return g
 }
 
-// Generate produces events and optionally vendor records
-func (g *Generator) Generate(ctx context.Context, ticker *time.Ticker, events chan<- models.Event, records chan<- []byte) error {
-	now := time.Now()
-	for signalName := range g.signals {
-		// Initialize with a time in the past to trigger immediately on first tick
-		g.lastEmit[signalName] = now.Add(-10 * time.Minute)
+// newID returns a random UUID, drawn from the seeded ID stream in deterministic mode
+func (g *Generator) newID() string {
+	if g.idRNG == nil {
+		return uuid.New().String()
 	}
+	id, err := uuid.NewRandomFromReader(g.idRNG)
+	if err != nil {
+		return uuid.New().String()
+	}
+	return id.String()
+}
 
-	aggregator := NewAggregator()
+// Generate produces events and optionally vendor records
+func (g *Generator) Generate(ctx context.Context, ticker *time.Ticker, events chan<- models.Event, records chan<- []byte) error {
+	g.resetEmitTimes()
+	windows := newVendorWindows(NewAggregator(g.clock), g.window, g.vendor)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
+			if g.engine.IsPaused() {
+				continue
+			}
 			if g.engine.IsComplete() {
-				return nil
+				return g.finish(ctx, windows, events, records, false)
 			}
 
-			tickEvents := g.generateTick()
-			for _, event := range tickEvents {
-				// Send to events channel if provided (non-blocking to prevent generator hang)
-				if events != nil {
-					select {
-					case events <- event:
-					default:
-						// Buffer full, dropping event to maintain real-time generation
-					}
-				}
+			if err := g.emitTick(ctx, windows, events, records, false); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GenerateVirtual renders the scenario as fast as possible by advancing a virtual
+// clock one tick at a time. Unlike Generate, sends block instead of dropping events
+// so the output is complete and reproducible for a given seed.
+func (g *Generator) GenerateVirtual(ctx context.Context, clk *clock.Virtual, tick time.Duration, events chan<- models.Event, records chan<- []byte) error {
+	if tick <= 0 {
+		return fmt.Errorf("tick must be positive")
+	}
+	if _, unlimited := scenario.ParseDuration(g.engine.GetScenario().Duration); unlimited {
+		return fmt.Errorf("virtual time requires a finite scenario duration")
+	}
+
+	g.resetEmitTimes()
+	windows := newVendorWindows(NewAggregator(g.clock), g.window, g.vendor)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
 
-				// Handle vendor aggregation (if records channel is provided)
-				if records != nil {
-					aggregator.Add(event)
-					if aggregator.Count() >= 10 {
-						var payload string
-						var err error
-						if g.vendor == "garmin" {
-							payload, err = aggregator.ToGarminJSON()
-						} else {
-							payload, err = aggregator.ToWhoopJSON()
-						}
-
-						if err == nil {
-							select {
-							case records <- []byte(payload):
-							case <-ctx.Done():
-								return ctx.Err()
-							}
-						}
-						aggregator.Clear()
-					}
+		clk.Advance(tick)
+		if g.engine.IsComplete() {
+			return g.finish(ctx, windows, events, records, true)
+		}
+
+		if err := g.emitTick(ctx, windows, events, records, true); err != nil {
+			return err
+		}
+	}
+}
+
+// resetEmitTimes initializes emit times in the past to trigger every signal on the first tick
+func (g *Generator) resetEmitTimes() {
+	now := g.clock.Now()
+	for _, src := range g.sources {
+		for _, signalName := range src.signalNames {
+			src.lastEmit[signalName] = now.Add(-10 * time.Minute)
+		}
+	}
+}
+
+// emitTick generates one tick of events and forwards them to the events channel and
+// the vendor windows. When block is false, events are dropped if the channel is full.
+func (g *Generator) emitTick(ctx context.Context, windows *vendorWindows, events chan<- models.Event, records chan<- []byte, block bool) error {
+	tickEvents := g.generateTick()
+	now := g.clock.Now()
+
+	// Close the previous vendor window before this tick's events join the next
+	if records != nil {
+		stage := ""
+		if phase := g.engine.GetCurrentPhase(); phase != nil {
+			stage = phase.SleepStage
+		}
+		windows.agg.ObserveSleep(stage, now)
+		if err := sendPayload(ctx, records, windows.advance(g.engine, g.engine.GetElapsed(), now)); err != nil {
+			return err
+		}
+	}
+
+	if err := g.deliver(ctx, windows, tickEvents, events, records, block); err != nil {
+		return err
+	}
+
+	if records != nil {
+		return sendPayload(ctx, records, windows.snapshot(now))
+	}
+	return nil
+}
+
+// deliver forwards events to the events channel and the vendor windows
+func (g *Generator) deliver(ctx context.Context, windows *vendorWindows, tickEvents []models.Event, events chan<- models.Event, records chan<- []byte, block bool) error {
+	for _, event := range tickEvents {
+		// Send to events channel if provided
+		if events != nil {
+			if block {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			} else {
+				select {
+				case events <- event:
+				default:
+					// Buffer full, dropping event to maintain real-time generation
 				}
 			}
 		}
+
+		// Handle vendor aggregation (if records channel is provided)
+		if records != nil {
+			windows.agg.Add(event)
+		}
+	}
+	return nil
+}
+
+// finish delivers what is still pending when the scenario completes: partial
+// sample blocks, events held back by a reorder fault, then the open vendor window
+func (g *Generator) finish(ctx context.Context, windows *vendorWindows, events chan<- models.Event, records chan<- []byte, block bool) error {
+	pending := g.faults.apply(g.flushBlocks(), g.engine.GetScenario(), g.engine.GetCurrentPhase(), g.engine.GetElapsed())
+	pending = append(pending, g.faults.flush()...)
+	if err := g.deliver(ctx, windows, pending, events, records, block); err != nil {
+		return err
+	}
+	if records == nil {
+		return nil
 	}
+	return sendPayload(ctx, records, windows.finish())
 }
 
-// generateTick generates all events for the current tick
+// sendPayload sends a vendor payload, if any
+func sendPayload(ctx context.Context, records chan<- []byte, payload []byte) error {
+	if payload == nil {
+		return nil
+	}
+	select {
+	case records <- payload:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// generateTick generates all events for the current tick across every source,
+// as delivered through any active faults
 func (g *Generator) generateTick() []models.Event {
 	elapsed := g.engine.GetElapsed()
-	now := time.Now()
+	now := g.clock.Now()
 	events := make([]models.Event, 0)
+	injections := g.activeInjections(elapsed)
+
+	for _, src := range g.sources {
+		events = append(events, g.generateSourceTick(src, elapsed, now, injections)...)
+	}
+
+	return g.faults.apply(events, g.engine.GetScenario(), g.engine.GetCurrentPhase(), elapsed)
+}
+
+// activeInjections returns the scenario events in effect at elapsed
+func (g *Generator) activeInjections(elapsed time.Duration) []activeInjection {
+	scen := g.engine.GetScenario()
+	if g.injector == nil || g.injector.scenario != scen {
+		g.injector = newInjector(scen, g.seed)
+	}
+
+	phase := ""
+	if current := g.engine.GetCurrentPhase(); current != nil {
+		phase = current.Name
+	}
+	return g.injector.active(elapsed, phase)
+}
+
+// signalConfig returns the effective config for a signal with any active
+// injection effects applied, and the names of the injections that apply
+func (g *Generator) signalConfig(signalName string, injections []activeInjection) (*scenario.SignalConfig, []string) {
+	config := g.engine.GetSignalConfig(signalName)
+	if config == nil {
+		return nil, nil
+	}
+
+	var applied []string
+	for _, injection := range injections {
+		if effect, ok := injection.effects[signalName]; ok && effect != nil {
+			config = scenario.ApplyEffect(config, effect)
+			applied = append(applied, injection.name)
+		}
+	}
+	return config, applied
+}
+
+// generateSourceTick generates the events due for a single source. Correlations
+// are applied within each source, since every device observes its own signals;
+// discrete states come from the subject shared by every source.
+func (g *Generator) generateSourceTick(src *sourceState, elapsed time.Duration, now time.Time, injections []activeInjection) []models.Event {
+	events := make([]models.Event, 0)
+	tags := make(map[string][]string)
+	if scen := g.engine.GetScenario(); src.correlator == nil || src.correlator.scenario != scen {
+		src.correlator = newCorrelator(scen)
+	}
+	heartConfigs := make(map[string]*scenario.SignalConfig)
+	heartDue := make(map[string]bool)
 
 	// Build correlation context
 	ctx := NewCorrelationContext()
 
 	// Generate all signals first
-	for signalName, generator := range g.signals {
-		config := g.engine.GetSignalConfig(signalName)
+	for _, signalName := range src.signalNames {
+		config, applied := g.signalConfig(signalName, injections)
 		if config == nil {
 			continue
 		}
+		if len(applied) > 0 {
+			tags[signalName] = applied
+		}
+		if heartSignals[signalName] {
+			heartConfigs[signalName] = config
+		}
+		if signalName == "ppg.rr_ms" || signalName == "ppg.raw" {
+			// Emitted once per beat or sample rather than at a tick rate
+			continue
+		}
 
 		// Check if it's time to emit this signal
-		signalRate := g.getSignalRate(config)
-		if now.Sub(g.lastEmit[signalName]) < signalRate {
+		if now.Sub(src.lastEmit[signalName]) < g.signalInterval(src, config) {
 			continue
 		}
+		src.lastEmit[signalName] = now
 
-		value := generator(g.rng, config, elapsed.Seconds())
-		ctx.Set(signalName, value)
-		g.lastEmit[signalName] = now
+		if heartSignals[signalName] {
+			heartDue[signalName] = true
+			continue
+		}
+		if m := markovConfig(signalName, config); m != nil {
+			chain := g.subject.chains[signalName]
+			if chain == nil {
+				chain = &markovChain{}
+				g.subject.chains[signalName] = chain
+			}
+			ctx.Set(signalName, chain.at(g.subject.chainRNG, m, config.Value, elapsed))
+			continue
+		}
+		generator, ok := g.signals[signalName]
+		if !ok {
+			continue
+		}
+		ctx.Set(signalName, generator(src.rng, config, elapsed.Seconds()))
 	}
 
 	// Apply correlations
-	ctx.ApplyCorrelations()
+	if chain := g.subject.chains["motion.activity"]; chain != nil {
+		ctx.motion = chain.state
+	}
+	if len(heartConfigs) > 0 {
+		advanceHeart(src, g.subject.heart, ctx, elapsed, heartConfigs, heartDue)
+	} else {
+		src.correlator.apply(ctx, elapsed)
+	}
+	if accel, ok := ctx.Get("accel.xyz_mps2"); ok {
+		src.ppg.observeMotion(accel.([]float64))
+	}
 
 	// Create events from correlated values
-	for signalName := range g.signals {
+	for _, signalName := range src.signalNames {
+		config := g.engine.GetSignalConfig(signalName)
+		if config == nil {
+			continue
+		}
+
+		if signalName == "ppg.rr_ms" {
+			for _, b := range src.newBeats(g.subject.heart) {
+				event := g.createEvent(src, signalName, b.rr, config, now.Add(b.at-elapsed))
+				event.Meta.Injections = tags[signalName]
+				events = append(events, event)
+			}
+			continue
+		}
+		if signalName == "ppg.raw" {
+			events = append(events, g.waveformEvents(src, config, elapsed, now, tags[signalName])...)
+			continue
+		}
+
 		value, ok := ctx.Get(signalName)
 		if !ok {
 			continue
 		}
 
-		config := g.engine.GetSignalConfig(signalName)
-		if config == nil {
+		period := g.signalInterval(src, config)
+		if size := g.blockSize(signalName, config, period); size > 0 {
+			events = append(events, g.addToBlock(src, signalName, config, value, now, period, size, tags[signalName])...)
 			continue
 		}
 
-		event := g.createEvent(signalName, value, config)
+		event := g.createEvent(src, signalName, value, config, now)
+		event.Meta.Injections = tags[signalName]
 		events = append(events, event)
 	}
 
 	return events
 }
 
-// createEvent creates a single event
-func (g *Generator) createEvent(signalName string, value interface{}, config *scenario.SignalConfig) models.Event {
-	g.sequence++
+// advanceHeart runs the subject's beat model up to elapsed. The heart rate and
+// RMSSD targets go through the source's correlation rules with its other signals,
+// then the values due this tick are measured from the beats so they agree with
+// ppg.rr_ms. The first source to reach a tick sets the targets of its beats.
+func advanceHeart(src *sourceState, heart *heartModel, ctx *CorrelationContext, elapsed time.Duration, configs map[string]*scenario.SignalConfig, due map[string]bool) {
+	targets := targetsFromConfig(configs["ppg.hr_bpm"], configs["ppg.hrv_rmssd_ms"])
+	ctx.Set("ppg.hr_bpm", targets.hr)
+	ctx.Set("ppg.hrv_rmssd_ms", targets.rmssd)
+	src.correlator.apply(ctx, elapsed)
+
+	hr, _ := ctx.Get("ppg.hr_bpm")
+	hrv, _ := ctx.Get("ppg.hrv_rmssd_ms")
+	targets.hr = hr.(float64)
+	targets.rmssd = hrv.(float64)
+	heart.advance(elapsed, targets)
+
+	ctx.Delete("ppg.hr_bpm")
+	ctx.Delete("ppg.hrv_rmssd_ms")
+	if due["ppg.hr_bpm"] {
+		ctx.Set("ppg.hr_bpm", heart.heartRate()+src.rng.NormFloat64()*hrNoise)
+	}
+	if due["ppg.hrv_rmssd_ms"] {
+		ctx.Set("ppg.hrv_rmssd_ms", heart.rmssd())
+	}
+}
+
+// waveformEvents synthesizes the ppg.raw samples due since the previous tick,
+// one event per sample or per block
+func (g *Generator) waveformEvents(src *sourceState, config *scenario.SignalConfig, elapsed time.Duration, now time.Time, tags []string) []models.Event {
+	period := defaultPPGRate
+	if config.Rate != "" {
+		period = g.getSignalRate(config)
+	}
+	if period < src.minInterval {
+		period = src.minInterval
+	}
+	size := g.blockSize("ppg.raw", config, period)
+
+	var events []models.Event
+	for _, sample := range src.ppg.samples(g.subject.heart, elapsed, period, config) {
+		at := now.Add(sample.at - elapsed)
+		if size > 0 {
+			events = append(events, g.addToBlock(src, "ppg.raw", config, sample.value, at, period, size, tags)...)
+			continue
+		}
+		event := g.createEvent(src, "ppg.raw", sample.value, config, at)
+		event.Meta.Injections = tags
+		events = append(events, event)
+	}
+	return events
+}
+
+// createEvent creates a single event timestamped at (before the source's skew)
+func (g *Generator) createEvent(src *sourceState, signalName string, value interface{}, config *scenario.SignalConfig, at time.Time) models.Event {
+	src.sequence++
 
 	signal := models.Signal{
 		Name:    signalName,
 		Value:   value,
-		Quality: 0.9 + g.rng.Float64()*0.1, // 0.9-1.0 quality
+		Quality: 0.9 + src.rng.Float64()*0.1, // 0.9-1.0 quality
 	}
 
 	// Set unit from config or use default
@@ -188,22 +493,32 @@ func (g *Generator) createEvent(signalName string, value interface{}, config *sc
 	session := models.Session{
 		RunID:    g.runID,
 		Scenario: g.engine.GetScenario().Name,
-		Seed:     g.rng.Int63(),
+		Seed:     src.rng.Int63(),
 	}
 
-	return models.NewEvent(
-		uuid.New().String(),
-		g.source,
+	return models.NewEventAt(
+		at.Add(src.skew),
+		g.newID(),
+		src.source,
 		session,
 		signal,
-		g.sequence,
+		src.sequence,
 	)
 }
 
+// signalInterval returns how often a source emits a signal, honoring its rate cap
+func (g *Generator) signalInterval(src *sourceState, config *scenario.SignalConfig) time.Duration {
+	interval := g.getSignalRate(config)
+	if interval < src.minInterval {
+		interval = src.minInterval
+	}
+	return interval
+}
+
 // getSignalRate returns the rate for a signal (how often to emit)
 func (g *Generator) getSignalRate(config *scenario.SignalConfig) time.Duration {
 	if config.Rate != "" {
-		if duration, err := parseRate(config.Rate); err == nil {
+		if duration, err := scenario.ParseRate(config.Rate); err == nil {
 			return duration
 		}
 	}
@@ -212,24 +527,13 @@ func (g *Generator) getSignalRate(config *scenario.SignalConfig) time.Duration {
 	return time.Second // 1Hz default
 }
 
-// parseRate converts rate string like "50hz" to duration
-func parseRate(rate string) (time.Duration, error) {
-	var hz float64
-	_, err := fmt.Sscanf(rate, "%fhz", &hz)
-	if err != nil {
-		return 0, err
-	}
-	if hz <= 0 {
-		return 0, fmt.Errorf("invalid rate: %s", rate)
-	}
-	return time.Duration(float64(time.Second) / hz), nil
-}
-
 // getDefaultUnit returns the default unit for a signal
 func getDefaultUnit(signalName string) string {
 	units := map[string]string{
 		"ppg.hr_bpm":       "bpm",
 		"ppg.hrv_rmssd_ms": "ms",
+		"ppg.rr_ms":        "ms",
+		"ppg.raw":          "au",
 		"accel.xyz_mps2":   "m/s²",
 		"gyro.xyz_rps":     "rad/s",
 		"temp.skin_c":      "°C",
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/clock"
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func testScenario() *scenario.Scenario {
+	return &scenario.Scenario{
+		Name:     "test",
+		Duration: "10s",
+		Signals: map[string]*scenario.SignalConfig{
+			"ppg.hr_bpm":     {Baseline: 72.0, Noise: 3.0, Rate: "1hz"},
+			"accel.xyz_mps2": {Baseline: []interface{}{0, 0, 9.81}, Noise: 0.05, Rate: "10hz"},
+			"eda.us":         {Baseline: 2.0, Rate: "0.5hz"},
+		},
+	}
+}
+
+// renderVirtualScenario renders a scenario from the 2025-01-01 epoch on a virtual clock
+func renderVirtualScenario(t *testing.T, scen *scenario.Scenario, seed int64) []models.Event {
+	t.Helper()
+	return renderVirtualAt(t, scen, seed, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func renderVirtualAt(t *testing.T, scen *scenario.Scenario, seed int64, epoch time.Time) []models.Event {
+	t.Helper()
+	return renderVirtualConfig(t, scen, Config{Seed: seed}, epoch)
+}
+
+// renderVirtualConfig renders a scenario for a single test watch with the given
+// generator config, filling in the source, clock and determinism
+func renderVirtualConfig(t *testing.T, scen *scenario.Scenario, config Config, epoch time.Time) []models.Event {
+	t.Helper()
+
+	clk := clock.NewVirtual(epoch)
+	engine := scenario.NewEngineWithClock(scen, clk)
+	config.SourceType = "wearable"
+	config.SourceID = "test-watch"
+	config.Clock = clk
+	config.Deterministic = true
+	gen := NewGenerator(engine, config)
+
+	events := make(chan models.Event)
+	done := make(chan error, 1)
+	go func() {
+		done <- gen.GenerateVirtual(context.Background(), clk, 100*time.Millisecond, events, nil)
+		close(events)
+	}()
+
+	var rendered []models.Event
+	for event := range events {
+		rendered = append(rendered, event)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("GenerateVirtual failed: %v", err)
+	}
+	return rendered
+}
+
+// renderVirtual renders testScenario on a virtual clock and returns the encoded events
+func renderVirtual(t *testing.T, seed int64, epoch time.Time) []string {
+	t.Helper()
+
+	var lines []string
+	for _, event := range renderVirtualAt(t, testScenario(), seed, epoch) {
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatalf("failed to marshal event: %v", err)
+		}
+		lines = append(lines, string(data))
+	}
+	return lines
+}
+
+func TestGenerateVirtualDeterministic(t *testing.T) {
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := renderVirtual(t, 42, epoch)
+	second := renderVirtual(t, 42, epoch)
+
+	// 99 ticks before completion: 1hz + every tick at 10hz + 0.5hz
+	if len(first) != 10+99+5 {
+		t.Fatalf("expected 114 events, got %d", len(first))
+	}
+	if len(first) != len(second) {
+		t.Fatalf("expected identical event counts, got %d and %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("event %d differs:\n%s\n%s", i, first[i], second[i])
+		}
+	}
+
+	third := renderVirtual(t, 43, epoch)
+	if first[0] == third[0] {
+		t.Error("expected different output for a different seed")
+	}
+}
+
+func TestGenerateVirtualTimestamps(t *testing.T) {
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	lines := renderVirtual(t, 1, epoch)
+
+	var first, last models.Event
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[len(lines)-1]), &last)
+
+	if first.Timestamp != "2025-01-01T00:00:00.1Z" {
+		t.Errorf("expected first timestamp one tick after epoch, got %s", first.Timestamp)
+	}
+	ts, err := time.Parse(time.RFC3339Nano, last.Timestamp)
+	if err != nil {
+		t.Fatalf("invalid timestamp %q: %v", last.Timestamp, err)
+	}
+	if ts.Sub(epoch) >= 10*time.Second {
+		t.Errorf("expected last event before scenario end, got %s", last.Timestamp)
+	}
+}
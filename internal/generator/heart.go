@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// heartSeedSalt separates the subject's beat stream from the signals of its sources
+const heartSeedSalt = 0x4ea27
+
+const (
+	hrWindow      = 5 * time.Second  // trailing beats averaged into ppg.hr_bpm
+	rmssdWindow   = 60 * time.Second // trailing beats for ppg.hrv_rmssd_ms (ultra-short-term RMSSD)
+	respirationHz = 0.25             // 15 breaths per minute
+	rsaShare      = 0.7              // share of the successive-difference variance from respiratory sinus arrhythmia
+	minRR         = 250.0            // ms, 240 bpm
+	maxRR         = 2000.0           // ms, 30 bpm
+
+	// Measurement noise each device adds to the subject's heart
+	beatJitter = 2.0 // ms, standard deviation of a detected beat's time
+	hrNoise    = 0.5 // bpm, standard deviation of a ppg.hr_bpm reading
+)
+
+// slowTaus are the time constants (seconds) of the Ornstein-Uhlenbeck processes
+// summed into the slow heart rate fluctuation. Equal variance per process,
+// spaced about evenly in log time, approximates a 1/f spectrum over that band.
+var slowTaus = [...]float64{15, 60, 240}
+
+// heartSignals are derived from the subject's beat model rather than a
+// SignalGenerator, so heart rate, RMSSD and RR intervals agree with each other
+// and across the subject's devices
+var heartSignals = map[string]bool{
+	"ppg.hr_bpm":       true,
+	"ppg.hrv_rmssd_ms": true,
+	"ppg.rr_ms":        true,
+	"ppg.raw":          true,
+}
+
+// heartTargets are what the beat model tracks for the next beats
+type heartTargets struct {
+	hr         float64 // mean heart rate, bpm
+	hrNoise    float64 // standard deviation of slow heart rate fluctuations, bpm
+	rmssd      float64 // beat-to-beat variability, ms
+	rmssdNoise float64 // standard deviation of slow RMSSD drift, ms
+}
+
+// targetsFromConfig derives the targets from the effective ppg.hr_bpm and
+// ppg.hrv_rmssd_ms configs. Either may be nil if the scenario omits the signal.
+func targetsFromConfig(hr, hrv *scenario.SignalConfig) heartTargets {
+	targets := heartTargets{hr: 72, hrNoise: 3, rmssd: 50, rmssdNoise: 8}
+	if hr != nil {
+		targets.hr = clamp(applyModifiers(getFloat(hr.Baseline, 72.0), hr), 40, 200)
+		targets.hrNoise = getFloat(hr.Noise, 3.0)
+	}
+	if hrv != nil {
+		targets.rmssd = clamp(applyModifiers(getFloat(hrv.Baseline, 50.0), hrv), 10, 150)
+		targets.rmssdNoise = getFloat(hrv.Noise, 8.0)
+	}
+	return targets
+}
+
+// beat is one simulated heartbeat
+type beat struct {
+	at time.Duration // scenario time at which the beat ends
+	rr float64       // interval since the previous beat, ms
+}
+
+// heartModel simulates RR intervals beat by beat. Each interval is the mean
+// interval for the target heart rate plus a slow 1/f-like fluctuation, a
+// respiratory sinus arrhythmia oscillation and white noise; the latter two are
+// scaled so the RMSSD of the series tracks the target.
+type heartModel struct {
+	rng       *rand.Rand
+	beats     []beat        // completed beats within the RMSSD window, oldest first
+	next      beat          // the beat in progress
+	warmedUp  time.Duration // end of the warm-up beats, which beatsAfter never returns
+	started   bool
+	respPhase float64
+	slow      [len(slowTaus)]float64 // unit-variance fluctuation states
+	drift     float64                // unit-variance RMSSD drift state
+}
+
+func newHeartModel(seed int64) *heartModel {
+	return &heartModel{rng: rand.New(rand.NewSource(seed ^ heartSeedSalt))}
+}
+
+// advance completes every beat ending at or before elapsed. The first call, or
+// time jumping backwards or past the window, warms the model up with a window of
+// beats that beatsAfter never returns, so heart rate and RMSSD are meaningful from
+// the first tick.
+func (h *heartModel) advance(elapsed time.Duration, targets heartTargets) {
+	if !h.started || elapsed < h.last() || elapsed-h.last() > rmssdWindow {
+		h.reset(elapsed, targets)
+	}
+
+	for h.next.at <= elapsed {
+		h.beats = append(h.beats, h.next)
+		h.next = h.draw(h.next.at, targets)
+	}
+
+	// Keep one beat before the window for its successive difference
+	cutoff := elapsed - rmssdWindow
+	i := 0
+	for i+1 < len(h.beats) && h.beats[i+1].at < cutoff {
+		i++
+	}
+	h.beats = h.beats[i:]
+}
+
+// reset discards the beat history and simulates a warm-up window ending at elapsed
+func (h *heartModel) reset(elapsed time.Duration, targets heartTargets) {
+	h.started = true
+	h.beats = h.beats[:0]
+	h.next = h.draw(elapsed-rmssdWindow, targets)
+	for h.next.at <= elapsed {
+		h.beats = append(h.beats, h.next)
+		h.next = h.draw(h.next.at, targets)
+	}
+	h.warmedUp = h.last()
+}
+
+// last returns the end of the last completed beat
+func (h *heartModel) last() time.Duration {
+	if len(h.beats) == 0 {
+		return h.next.at
+	}
+	return h.beats[len(h.beats)-1].at
+}
+
+// draw simulates the beat starting at start
+func (h *heartModel) draw(start time.Duration, targets heartTargets) beat {
+	meanRR := 60000 / targets.hr
+	dt := meanRR / 1000
+
+	// Slow fluctuation: the HR noise in bpm converted to ms at the current rate
+	slowSD := targets.hrNoise * 60000 / (targets.hr * targets.hr)
+	slow := 0.0
+	slowDiffVar := 0.0
+	for i, tau := range slowTaus {
+		a := math.Exp(-dt / tau)
+		h.slow[i] = a*h.slow[i] + math.Sqrt(1-a*a)*h.rng.NormFloat64()
+		slow += h.slow[i]
+		slowDiffVar += 2 * (1 - a)
+	}
+	slow *= slowSD / math.Sqrt(float64(len(slowTaus)))
+	slowDiffVar *= slowSD * slowSD / float64(len(slowTaus))
+
+	a := math.Exp(-dt / rmssdWindow.Seconds())
+	h.drift = a*h.drift + math.Sqrt(1-a*a)*h.rng.NormFloat64()
+	rmssd := math.Max(targets.rmssd+targets.rmssdNoise*h.drift, 1)
+
+	// A sinusoid advancing by step per beat has successive differences with an RMS
+	// of sqrt(2)*A*sin(step/2); white noise has sqrt(2)*sigma. The white share
+	// leaves room for what the slow fluctuation already contributes.
+	step := 2 * math.Pi * respirationHz * dt
+	h.respPhase = math.Mod(h.respPhase+step, 2*math.Pi)
+	amplitude := math.Sqrt(rsaShare) * rmssd / (math.Sqrt2 * math.Sin(step/2))
+	sigma := math.Sqrt(math.Max((1-rsaShare)*rmssd*rmssd-slowDiffVar, 0) / 2)
+
+	rr := meanRR + slow + amplitude*math.Sin(h.respPhase) + sigma*h.rng.NormFloat64()
+	rr = clamp(rr, minRR, maxRR)
+	return beat{at: start + time.Duration(rr*float64(time.Millisecond)), rr: rr}
+}
+
+// beatAt returns the start and interval of the beat in progress at t, which may
+// be the beat not yet completed
+func (h *heartModel) beatAt(t time.Duration) (time.Duration, float64, bool) {
+	for _, b := range h.beats {
+		if t < b.at {
+			return b.at - time.Duration(b.rr*float64(time.Millisecond)), b.rr, true
+		}
+	}
+	if t < h.next.at {
+		return h.next.at - time.Duration(h.next.rr*float64(time.Millisecond)), h.next.rr, true
+	}
+	return 0, 0, false
+}
+
+// heartRate returns the mean heart rate over the trailing beats, in bpm
+func (h *heartModel) heartRate() float64 {
+	cutoff := h.last() - hrWindow
+	sum, n := 0.0, 0
+	for i := len(h.beats) - 1; i >= 0 && (n == 0 || h.beats[i].at > cutoff); i-- {
+		sum += h.beats[i].rr
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return 60000 / (sum / float64(n))
+}
+
+// rmssd returns the root mean square of successive differences over the window, in ms
+func (h *heartModel) rmssd() float64 {
+	sum, n := 0.0, 0
+	for i := 1; i < len(h.beats); i++ {
+		d := h.beats[i].rr - h.beats[i-1].rr
+		sum += d * d
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+// beatsAfter returns the completed beats ending after t, past the warm-up
+func (h *heartModel) beatsAfter(t time.Duration) []beat {
+	if t < h.warmedUp {
+		t = h.warmedUp
+	}
+	var beats []beat
+	for _, b := range h.beats {
+		if b.at > t {
+			beats = append(beats, b)
+		}
+	}
+	return beats
+}
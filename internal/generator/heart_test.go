@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestHeartModelTracksTargets(t *testing.T) {
+	tests := []heartTargets{
+		{hr: 60, hrNoise: 2, rmssd: 70, rmssdNoise: 0},
+		{hr: 72, hrNoise: 3, rmssd: 50, rmssdNoise: 0},
+		{hr: 150, hrNoise: 3, rmssd: 12, rmssdNoise: 0},
+	}
+	for _, targets := range tests {
+		h := newHeartModel(1)
+		var hrSum, rmssdSum float64
+		n := 0
+		for elapsed := time.Duration(0); elapsed < 10*time.Minute; elapsed += 100 * time.Millisecond {
+			h.advance(elapsed, targets)
+			if elapsed%time.Second == 0 {
+				hrSum += h.heartRate()
+				rmssdSum += h.rmssd()
+				n++
+			}
+		}
+
+		hr, rmssd := hrSum/float64(n), rmssdSum/float64(n)
+		if math.Abs(hr-targets.hr) > targets.hr*0.05 {
+			t.Errorf("target %.0f bpm: mean heart rate %.1f", targets.hr, hr)
+		}
+		if math.Abs(rmssd-targets.rmssd) > targets.rmssd*0.15 {
+			t.Errorf("target %.0f ms: mean RMSSD %.1f", targets.rmssd, rmssd)
+		}
+	}
+}
+
+func TestHeartModelRespiratoryArrhythmia(t *testing.T) {
+	h := newHeartModel(1)
+	targets := heartTargets{hr: 60, rmssd: 60}
+	h.advance(0, targets)
+
+	// Intervals should swing with breathing: a 4s cycle at 60 bpm spans 4 beats,
+	// so intervals two beats apart are anti-correlated
+	var lag2, variance float64
+	for i := 2; i < len(h.beats); i++ {
+		a, b := h.beats[i].rr-1000, h.beats[i-2].rr-1000
+		lag2 += a * b
+		variance += a * a
+	}
+	if lag2 >= 0 || -lag2/variance < 0.3 {
+		t.Errorf("expected strong negative lag-2 correlation, got %.2f", lag2/variance)
+	}
+}
+
+func TestHeartSignalsConsistent(t *testing.T) {
+	scen := &scenario.Scenario{
+		Name:     "heart",
+		Duration: "2m",
+		Signals: map[string]*scenario.SignalConfig{
+			"ppg.hr_bpm":       {Baseline: 65.0, Noise: 3.0, Rate: "1hz"},
+			"ppg.hrv_rmssd_ms": {Baseline: 55.0, Noise: 8.0, Rate: "0.2hz"},
+			"ppg.rr_ms":        {},
+		},
+	}
+
+	events := renderVirtualScenario(t, scen, 42)
+	again := renderVirtualScenario(t, scen, 42)
+	if len(events) != len(again) {
+		t.Fatalf("expected deterministic output, got %d and %d events", len(events), len(again))
+	}
+
+	var beats []models.Event
+	for i, event := range events {
+		if event.Signal.Value != again[i].Signal.Value {
+			t.Fatalf("event %d differs between runs", i)
+		}
+		if event.Signal.Name == "ppg.rr_ms" {
+			if event.Signal.Unit != "ms" {
+				t.Errorf("expected ms unit, got %q", event.Signal.Unit)
+			}
+			beats = append(beats, event)
+		}
+	}
+	// About 65 beats a minute over two minutes
+	if len(beats) < 110 || len(beats) > 150 {
+		t.Fatalf("expected roughly 130 beats, got %d", len(beats))
+	}
+
+	// Beats are timestamped at their own times, so intervals between timestamps
+	// match the RR values
+	for i := 1; i < len(beats); i++ {
+		prev, _ := time.Parse(time.RFC3339Nano, beats[i-1].Timestamp)
+		cur, _ := time.Parse(time.RFC3339Nano, beats[i].Timestamp)
+		gap := float64(cur.Sub(prev)) / float64(time.Millisecond)
+		if math.Abs(gap-beats[i].Signal.Value.(float64)) > 1 {
+			t.Fatalf("beat %d: %v ms after the previous beat but RR is %v", i, gap, beats[i].Signal.Value)
+		}
+	}
+
+	// The last reported RMSSD agrees with the RMSSD of the emitted beats in the
+	// minute before it
+	var last models.Event
+	for _, event := range events {
+		if event.Signal.Name == "ppg.hrv_rmssd_ms" {
+			last = event
+		}
+	}
+	at, _ := time.Parse(time.RFC3339Nano, last.Timestamp)
+	var sum float64
+	n := 0
+	for i := 1; i < len(beats); i++ {
+		ts, _ := time.Parse(time.RFC3339Nano, beats[i].Timestamp)
+		if ts.After(at) || at.Sub(ts) > rmssdWindow {
+			continue
+		}
+		d := beats[i].Signal.Value.(float64) - beats[i-1].Signal.Value.(float64)
+		sum += d * d
+		n++
+	}
+	rmssd := math.Sqrt(sum / float64(n))
+	if reported := last.Signal.Value.(float64); math.Abs(rmssd-reported) > reported*0.05 {
+		t.Errorf("reported RMSSD %.1f, beats give %.1f", reported, rmssd)
+	}
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// injectionSeedSalt separates the injection schedule from the signal and ID
+// streams so adding events to a scenario doesn't change its other values
+const injectionSeedSalt = 0x1e7ec7
+
+// activeInjection is a scenario event in effect for the current tick
+type activeInjection struct {
+	name    string
+	effects map[string]*scenario.SignalConfig
+}
+
+// injectionSchedule tracks the occurrences of one scenario event
+type injectionSchedule struct {
+	config   scenario.EventConfig
+	duration time.Duration
+	offsets  []time.Duration // fixed occurrences, ascending
+	mean     time.Duration   // mean Poisson interval, 0 for none
+	next     time.Duration   // start of the current or next random occurrence
+}
+
+// injector decides which scenario events are active as elapsed time advances.
+// Random occurrences are drawn from a seeded RNG only as time moves forward, so a
+// run with the same seed and tick sequence injects at the same offsets.
+type injector struct {
+	scenario  *scenario.Scenario
+	seed      int64
+	rng       *rand.Rand
+	schedules []*injectionSchedule
+	last      time.Duration
+}
+
+func newInjector(scen *scenario.Scenario, seed int64) *injector {
+	inj := &injector{scenario: scen, seed: seed}
+	inj.reset(0)
+	return inj
+}
+
+// reset rebuilds every schedule with random occurrences drawn from elapsed onwards
+func (inj *injector) reset(elapsed time.Duration) {
+	inj.rng = rand.New(rand.NewSource(inj.seed ^ injectionSeedSalt))
+	inj.schedules = inj.schedules[:0]
+	inj.last = elapsed
+
+	for _, config := range inj.scenario.Events {
+		duration, _ := time.ParseDuration(config.Duration)
+		schedule := &injectionSchedule{
+			config:   config,
+			duration: duration,
+			offsets:  config.Offsets(),
+		}
+		if config.Every != "" {
+			schedule.mean, _ = time.ParseDuration(config.Every)
+		}
+		if schedule.mean > 0 {
+			schedule.next = elapsed + inj.interval(schedule.mean)
+		}
+		inj.schedules = append(inj.schedules, schedule)
+	}
+}
+
+// interval draws the time until the next random occurrence
+func (inj *injector) interval(mean time.Duration) time.Duration {
+	return time.Duration(inj.rng.ExpFloat64() * float64(mean))
+}
+
+// active returns the events in effect at elapsed during the named phase. Time
+// moving backwards (a phase jump or restart) reschedules random occurrences.
+func (inj *injector) active(elapsed time.Duration, phase string) []activeInjection {
+	if len(inj.schedules) == 0 {
+		return nil
+	}
+	if elapsed < inj.last {
+		inj.reset(elapsed)
+	}
+	inj.last = elapsed
+
+	var active []activeInjection
+	for _, schedule := range inj.schedules {
+		if inj.isActive(schedule, elapsed) && schedule.config.MatchesPhase(phase) {
+			active = append(active, activeInjection{
+				name:    schedule.config.Name,
+				effects: schedule.config.Effects,
+			})
+		}
+	}
+	return active
+}
+
+// isActive reports whether an occurrence of the schedule covers elapsed, drawing
+// new random occurrences as earlier ones finish
+func (inj *injector) isActive(s *injectionSchedule, elapsed time.Duration) bool {
+	active := false
+	for _, offset := range s.offsets {
+		if offset > elapsed {
+			break
+		}
+		if elapsed < offset+s.duration {
+			active = true
+		}
+	}
+
+	if s.mean > 0 {
+		// Skip occurrences that have already finished
+		for elapsed >= s.next+s.duration {
+			s.next += inj.interval(s.mean)
+		}
+		if elapsed >= s.next {
+			active = true
+		}
+	}
+	return active
+}
@@ -0,0 +1,133 @@
+package generator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestInjectorFixedOffsets(t *testing.T) {
+	scen := &scenario.Scenario{
+		Events: []scenario.EventConfig{{
+			Name:     "artifact",
+			At:       []string{"2m", "30s"},
+			Duration: "5s",
+			Effects:  map[string]*scenario.SignalConfig{"accel.xyz_mps2": {Noise: 2.0}},
+		}},
+	}
+	inj := newInjector(scen, 1)
+
+	tests := []struct {
+		elapsed time.Duration
+		active  bool
+	}{
+		{29 * time.Second, false},
+		{30 * time.Second, true},
+		{34 * time.Second, true},
+		{35 * time.Second, false},
+		{2 * time.Minute, true},
+		{2*time.Minute + 5*time.Second, false},
+	}
+	for _, test := range tests {
+		active := inj.active(test.elapsed, "")
+		if (len(active) == 1) != test.active {
+			t.Errorf("at %s: expected active=%v, got %v", test.elapsed, test.active, active)
+		}
+	}
+}
+
+func TestInjectorPoissonDeterministic(t *testing.T) {
+	scen := &scenario.Scenario{
+		Events: []scenario.EventConfig{{
+			Name:     "notification",
+			Every:    "1m",
+			Duration: "10s",
+			Effects:  map[string]*scenario.SignalConfig{"screen.state": {Value: "on"}},
+		}},
+	}
+
+	run := func(seed int64) []time.Duration {
+		inj := newInjector(scen, seed)
+		var starts []time.Duration
+		wasActive := false
+		for elapsed := time.Duration(0); elapsed < time.Hour; elapsed += time.Second {
+			active := len(inj.active(elapsed, "")) > 0
+			if active && !wasActive {
+				starts = append(starts, elapsed)
+			}
+			wasActive = active
+		}
+		return starts
+	}
+
+	first, second := run(7), run(7)
+	if len(first) == 0 {
+		t.Fatal("expected random occurrences within an hour")
+	}
+	// A 1m mean over an hour should land well within these bounds
+	if len(first) < 20 || len(first) > 100 {
+		t.Errorf("expected roughly 50 occurrences, got %d", len(first))
+	}
+	if len(first) != len(second) {
+		t.Fatalf("expected identical schedules for the same seed, got %d and %d occurrences", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("occurrence %d: %s != %s", i, first[i], second[i])
+		}
+	}
+
+	other := run(8)
+	if len(other) == len(first) && other[0] == first[0] {
+		t.Error("expected a different schedule for a different seed")
+	}
+}
+
+func TestInjectorPhases(t *testing.T) {
+	scen := &scenario.Scenario{
+		Events: []scenario.EventConfig{{
+			Name:     "cramp",
+			At:       []string{"0s"},
+			Duration: "1h",
+			Phases:   []string{"sprint"},
+			Effects:  map[string]*scenario.SignalConfig{"ppg.hr_bpm": {Add: 5}},
+		}},
+	}
+	inj := newInjector(scen, 1)
+
+	if active := inj.active(time.Second, "warmup"); len(active) != 0 {
+		t.Errorf("expected no injection outside listed phases, got %v", active)
+	}
+	if active := inj.active(2*time.Second, "sprint-2"); len(active) != 1 {
+		t.Errorf("expected injection during repeated phase sprint-2, got %v", active)
+	}
+}
+
+func TestGenerateTagsInjections(t *testing.T) {
+	scen := testScenario()
+	scen.Events = []scenario.EventConfig{{
+		Name:     "spike",
+		At:       []string{"2s"},
+		Duration: "2s",
+		Effects:  map[string]*scenario.SignalConfig{"eda.us": {Add: 10}},
+	}}
+
+	events := renderVirtualScenario(t, scen, 42)
+	tagged := 0
+	for _, event := range events {
+		if len(event.Meta.Injections) == 0 {
+			continue
+		}
+		tagged++
+		if event.Signal.Name != "eda.us" || event.Meta.Injections[0] != "spike" {
+			t.Errorf("unexpected tagged event: %s %v", event.Signal.Name, event.Meta.Injections)
+		}
+		if eda, ok := event.Signal.Value.(float64); !ok || eda < 8 {
+			t.Errorf("expected EDA raised by the injection, got %v", event.Signal.Value)
+		}
+	}
+	if tagged == 0 {
+		t.Error("expected events tagged with the injection")
+	}
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// markovSeedSalt separates the subject's state chains from the signals of its sources
+const markovSeedSalt = 0x3a4c0
+
+// minDwell bounds how briefly a state can last, so the chains never flicker
+const minDwell = time.Second
+
+// markovChain holds the current state of one discrete signal between ticks
+type markovChain struct {
+	state   string
+	until   time.Duration // scenario time the current state ends
+	started bool
+	forced  bool // state was set by a value override rather than the chain
+	last    time.Duration
+}
+
+// at advances the chain through every transition up to elapsed and returns the
+// state then. A value override holds the chain in that state; once it ends, the
+// state lasts a fresh dwell before the chain moves on. A state the chain doesn't
+// know (after a phase switches chains or an override) is left immediately.
+func (c *markovChain) at(rng *rand.Rand, m *scenario.Markov, value string, elapsed time.Duration) string {
+	if !c.started || elapsed < c.last {
+		c.started = true
+		c.forced = false
+		c.state = m.Initial
+		if c.state == "" {
+			c.state = drawInitial(rng, m)
+		}
+		c.until = elapsed + dwell(rng, m, c.state)
+	}
+	c.last = elapsed
+
+	if value != "" {
+		c.state = value
+		c.forced = true
+		return value
+	}
+	if c.forced {
+		c.forced = false
+		c.until = elapsed + dwell(rng, m, c.state)
+	}
+	if _, ok := m.Dwell[c.state]; !ok {
+		c.until = elapsed
+	}
+
+	for c.until <= elapsed {
+		c.state = transition(rng, m, c.state)
+		c.until += dwell(rng, m, c.state)
+	}
+	return c.state
+}
+
+// transition draws the state following from by its transition weights. A state
+// without transitions moves to the initial state.
+func transition(rng *rand.Rand, m *scenario.Markov, from string) string {
+	weights := m.Transitions[from]
+	total := 0.0
+	for _, state := range m.States() {
+		total += weights[state]
+	}
+	if total <= 0 {
+		if m.Initial != "" {
+			return m.Initial
+		}
+		return drawInitial(rng, m)
+	}
+
+	r := rng.Float64() * total
+	for _, state := range m.States() {
+		if weights[state] <= 0 {
+			continue
+		}
+		if r < weights[state] {
+			return state
+		}
+		r -= weights[state]
+	}
+	return from
+}
+
+// drawInitial picks a state in proportion to its mean dwell, which approximates
+// the share of time the chain spends in it
+func drawInitial(rng *rand.Rand, m *scenario.Markov) string {
+	states := m.States()
+	if len(states) == 0 {
+		return ""
+	}
+	total := 0.0
+	for _, state := range states {
+		total += float64(m.DwellDuration(state))
+	}
+	r := rng.Float64() * total
+	for _, state := range states {
+		if r < float64(m.DwellDuration(state)) {
+			return state
+		}
+		r -= float64(m.DwellDuration(state))
+	}
+	return states[len(states)-1]
+}
+
+// dwell draws how long a state lasts: gamma distributed with shape 2 around the
+// mean, so very short and very long stays are both rare
+func dwell(rng *rand.Rand, m *scenario.Markov, state string) time.Duration {
+	mean := float64(m.DwellDuration(state))
+	d := time.Duration(mean * (rng.ExpFloat64() + rng.ExpFloat64()) / 2)
+	if d < minDwell {
+		return minDwell
+	}
+	return d
+}
+
+// markovConfig returns the chain a discrete signal follows under config
+func markovConfig(signalName string, config *scenario.SignalConfig) *scenario.Markov {
+	if config.Markov != nil {
+		return config.Markov
+	}
+	return scenario.DefaultMarkov(signalName)
+}
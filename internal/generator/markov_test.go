@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestMarkovDwellTimes(t *testing.T) {
+	m := scenario.DefaultMarkov("app.activity")
+	chain := &markovChain{}
+	rng := rand.New(rand.NewSource(7))
+
+	// Sample once a second for ten hours, measuring how long each state lasts
+	var stays []time.Duration
+	var stayStart time.Duration
+	prev := ""
+	for elapsed := time.Duration(0); elapsed < 10*time.Hour; elapsed += time.Second {
+		state := chain.at(rng, m, "", elapsed)
+		if prev != "" && state != prev {
+			if m.Transitions[prev][state] <= 0 {
+				t.Fatalf("unexpected transition %s -> %s", prev, state)
+			}
+			stays = append(stays, elapsed-stayStart)
+			stayStart = elapsed
+		}
+		prev = state
+	}
+
+	var total time.Duration
+	for _, stay := range stays {
+		total += stay
+	}
+	mean := total / time.Duration(len(stays))
+	if mean < 30*time.Second || mean > 90*time.Second {
+		t.Errorf("expected states to last about a minute on average, got %s over %d stays", mean, len(stays))
+	}
+}
+
+func TestMarkovValueOverride(t *testing.T) {
+	m := &scenario.Markov{
+		Dwell: map[string]string{"on": "1m", "off": "1m"},
+		Transitions: map[string]map[string]float64{
+			"on":  {"off": 1},
+			"off": {"on": 1},
+		},
+	}
+	chain := &markovChain{}
+	rng := rand.New(rand.NewSource(1))
+
+	chain.at(rng, m, "", 0)
+	if state := chain.at(rng, m, "off", 10*time.Minute); state != "off" {
+		t.Fatalf("expected the override value, got %s", state)
+	}
+
+	// Once the override ends, the chain continues from the forced state
+	if state := chain.at(rng, m, "", 10*time.Minute+time.Second); state != "off" {
+		t.Errorf("expected the forced state to persist after the override, got %s", state)
+	}
+
+	// A state the chain doesn't know is left on the next tick
+	chain.at(rng, m, "dimmed", 20*time.Minute)
+	if state := chain.at(rng, m, "", 20*time.Minute+time.Second); state != "on" && state != "off" {
+		t.Errorf("expected the chain to leave an unknown state, got %s", state)
+	}
+}
+
+func TestDiscreteSignalsPersist(t *testing.T) {
+	scen := &scenario.Scenario{
+		Name:     "sessions",
+		Duration: "10m",
+		Signals: map[string]*scenario.SignalConfig{
+			"app.activity":    {Rate: "1hz"},
+			"screen.state":    {Rate: "1hz"},
+			"motion.activity": {Rate: "1hz"},
+		},
+	}
+
+	changes := make(map[string]int)
+	samples := make(map[string]int)
+	last := make(map[string]string)
+	for _, event := range renderVirtualScenario(t, scen, 42) {
+		name := event.Signal.Name
+		value := event.Signal.Value.(string)
+		if prev, ok := last[name]; ok && prev != value {
+			changes[name]++
+		}
+		last[name] = value
+		samples[name]++
+	}
+
+	// Independent draws per tick would change state on most samples
+	for name, n := range samples {
+		if changes[name] > n/10 {
+			t.Errorf("%s: expected persistent states, got %d changes in %d samples", name, changes[name], n)
+		}
+	}
+}
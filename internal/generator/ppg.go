@@ -0,0 +1,119 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// ppgSeedSalt separates the waveform's noise from the other signals of a source
+const ppgSeedSalt = 0x99a7e
+
+const (
+	defaultPPGRate  = 20 * time.Millisecond  // 50hz
+	motionSmoothing = 150 * time.Millisecond // time constant of motion artifacts
+	gravity         = 9.81                   // m/s²
+)
+
+// ppgSample is one synthesized point of the raw waveform
+type ppgSample struct {
+	at    time.Duration // scenario time
+	value float64
+}
+
+// ppgModel synthesizes the raw optical waveform from the subject's beats. Each
+// beat is a systolic peak followed by a dicrotic notch and a diastolic wave, on
+// top of respiratory baseline wander and artifacts that follow the accelerometer.
+type ppgModel struct {
+	rng      *rand.Rand
+	next     time.Duration // scenario time of the next sample
+	started  bool
+	motion   float64 // latest acceleration beyond gravity, m/s²
+	artifact float64 // motion artifact, low-pass filtered across samples
+}
+
+func newPPGModel(seed int64) *ppgModel {
+	return &ppgModel{rng: rand.New(rand.NewSource(seed ^ ppgSeedSalt))}
+}
+
+// observeMotion records the accelerometer reading driving motion artifacts
+func (p *ppgModel) observeMotion(accel []float64) {
+	if len(accel) < 3 {
+		return
+	}
+	p.motion = math.Sqrt(accel[0]*accel[0]+accel[1]*accel[1]+accel[2]*accel[2]) - gravity
+}
+
+// samples synthesizes every sample due up to elapsed. The heart model must have
+// been advanced to elapsed. Time moving backwards or jumping beyond the retained
+// beats restarts the waveform at elapsed.
+func (p *ppgModel) samples(h *heartModel, elapsed, period time.Duration, config *scenario.SignalConfig) []ppgSample {
+	if !p.started || p.next > elapsed+period || (len(h.beats) > 0 && p.next < h.beats[0].at) {
+		p.started = true
+		p.next = elapsed
+	}
+
+	m := morphology(config.Morphology)
+	baseline := getFloat(config.Baseline, 0)
+	noise := getFloat(config.Noise, 0.01)
+
+	var samples []ppgSample
+	for ; p.next <= elapsed; p.next += period {
+		start, rr, ok := h.beatAt(p.next)
+		if !ok {
+			continue
+		}
+		phase := float64(p.next-start) / (rr * float64(time.Millisecond))
+
+		// Motion artifacts follow the accelerometer through a first-order low-pass
+		alpha := 1 - math.Exp(-float64(period)/float64(motionSmoothing))
+		p.artifact += alpha * (m.Motion*p.motion - p.artifact)
+
+		t := p.next.Seconds()
+		wander := m.Wander * math.Sin(2*math.Pi*respirationHz*t)
+		value := baseline + m.Amplitude*pulse(phase, m) + wander + p.artifact + p.rng.NormFloat64()*noise
+		samples = append(samples, ppgSample{at: p.next, value: applyModifiers(value, config)})
+	}
+	return samples
+}
+
+// morphology fills in the defaults for unset pulse shape fields
+func morphology(m *scenario.Morphology) scenario.Morphology {
+	result := scenario.Morphology{Amplitude: 1, Dicrotic: 0.4, Notch: 0.35, Wander: 0.1, Motion: 0.5}
+	if m == nil {
+		return result
+	}
+	if m.Amplitude != 0 {
+		result.Amplitude = m.Amplitude
+	}
+	if m.Dicrotic != 0 {
+		result.Dicrotic = m.Dicrotic
+	}
+	if m.Notch > 0 && m.Notch < 1 {
+		result.Notch = m.Notch
+	}
+	if m.Wander != 0 {
+		result.Wander = m.Wander
+	}
+	if m.Motion != 0 {
+		result.Motion = m.Motion
+	}
+	return result
+}
+
+// pulse is the normalized pulse wave at a phase (0-1) of the beat: a systolic
+// Gaussian peaking well before the notch and a diastolic Gaussian after it, so the
+// trough between them forms the dicrotic notch
+func pulse(phase float64, m scenario.Morphology) float64 {
+	systolicAt := m.Notch * 0.5
+	diastolicAt := m.Notch + 0.1
+	systolic := math.Exp(-sq((phase-systolicAt)/(m.Notch*0.18)) / 2)
+	diastolic := m.Dicrotic * math.Exp(-sq((phase-diastolicAt)/0.08)/2)
+	return systolic + diastolic
+}
+
+func sq(x float64) float64 {
+	return x * x
+}
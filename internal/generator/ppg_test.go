@@ -0,0 +1,130 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestPulseMorphology(t *testing.T) {
+	m := morphology(nil)
+
+	// Sample one beat finely and locate the systolic peak, the notch and the diastolic wave
+	const steps = 1000
+	values := make([]float64, steps)
+	peak := 0
+	for i := range values {
+		values[i] = pulse(float64(i)/steps, m)
+		if values[i] > values[peak] {
+			peak = i
+		}
+	}
+	notch := -1
+	for i := peak + 1; i < steps-1; i++ {
+		if values[i] < values[i-1] && values[i] < values[i+1] {
+			notch = i
+			break
+		}
+	}
+	if notch < 0 {
+		t.Fatal("expected a dicrotic notch after the systolic peak")
+	}
+	diastolic := notch
+	for i := notch; i < steps; i++ {
+		if values[i] > values[diastolic] {
+			diastolic = i
+		}
+	}
+
+	if peak > steps/4 {
+		t.Errorf("expected the systolic peak early in the beat, got phase %.2f", float64(peak)/steps)
+	}
+	if values[diastolic] <= values[notch] || values[diastolic] >= values[peak] {
+		t.Errorf("expected a diastolic wave between the notch and the systolic peak, got %.2f / %.2f / %.2f",
+			values[peak], values[notch], values[diastolic])
+	}
+}
+
+func rawScenario(block int) *scenario.Scenario {
+	return &scenario.Scenario{
+		Name:     "raw",
+		Duration: "10s",
+		Signals: map[string]*scenario.SignalConfig{
+			"ppg.rr_ms": {},
+			"ppg.raw":   {Rate: "100hz", Noise: 0.0, Block: block},
+		},
+	}
+}
+
+func TestWaveformSamples(t *testing.T) {
+	var samples []float64
+	beats := 0
+	for _, event := range renderVirtualScenario(t, rawScenario(0), 42) {
+		switch event.Signal.Name {
+		case "ppg.raw":
+			if event.Signal.IsBlock() {
+				t.Fatal("expected scalar samples without block")
+			}
+			samples = append(samples, event.Signal.Value.(float64))
+		case "ppg.rr_ms":
+			beats++
+		}
+	}
+
+	// 100hz from the first tick at 0.1s through the last at 9.9s
+	if len(samples) != 981 {
+		t.Fatalf("expected 981 samples, got %d", len(samples))
+	}
+
+	// One systolic peak per beat: count upward crossings of the midline
+	crossings := 0
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0.6 && samples[i] >= 0.6 {
+			crossings++
+		}
+	}
+	if crossings < beats-1 || crossings > beats+1 {
+		t.Errorf("expected a pulse per beat, got %d pulses for %d beats", crossings, beats)
+	}
+}
+
+func TestWaveformBlocks(t *testing.T) {
+	scalar := renderVirtualScenario(t, rawScenario(0), 42)
+	blocked := renderVirtualScenario(t, rawScenario(25), 42)
+
+	var samples []float64
+	for _, event := range scalar {
+		if event.Signal.Name == "ppg.raw" {
+			samples = append(samples, event.Signal.Value.(float64))
+		}
+	}
+
+	var blocks []models.Event
+	for _, event := range blocked {
+		if event.Signal.Name == "ppg.raw" {
+			blocks = append(blocks, event)
+		}
+	}
+	if want := (len(samples) + 24) / 25; len(blocks) != want {
+		t.Fatalf("expected %d blocks, got %d", want, len(blocks))
+	}
+	var blockedSamples []float64
+	for i, event := range blocks {
+		if event.Signal.SamplePeriodMs != 10 {
+			t.Fatalf("expected a 10ms sample period, got %v", event.Signal.SamplePeriodMs)
+		}
+		values := event.Signal.Value.([]float64)
+		if len(values) != 25 && i != len(blocks)-1 {
+			t.Fatalf("block %d: expected 25 samples, got %d", i, len(values))
+		}
+		blockedSamples = append(blockedSamples, values...)
+	}
+	if !reflect.DeepEqual(blockedSamples, samples) {
+		t.Fatalf("expected the same %d samples as scalar output, got %d", len(samples), len(blockedSamples))
+	}
+	if blocks[1].Timestamp != "2025-01-01T00:00:00.35Z" {
+		t.Errorf("expected a block timestamped at its first sample, got %s", blocks[1].Timestamp)
+	}
+}
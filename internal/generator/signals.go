@@ -3,6 +3,7 @@ package generator
 import (
 	"math"
 	"math/rand"
+	"sort"
 
 	"github.com/synheart/synheart-cli/internal/scenario"
 )
@@ -10,55 +11,31 @@ import (
 // SignalGenerator generates a specific signal value
 type SignalGenerator func(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64) interface{}
 
-// GetAllSignals returns all available signal generators
+// GetAllSignals returns the generators of all signals drawn per sample. Heart
+// signals come from each source's beat model instead (see heartSignals), and
+// discrete signals from its state chains (see scenario.DiscreteSignals).
 func GetAllSignals() map[string]SignalGenerator {
 	return map[string]SignalGenerator{
-		"ppg.hr_bpm":       generateHeartRate,
-		"ppg.hrv_rmssd_ms": generateHRV,
-		"accel.xyz_mps2":   generateAccel,
-		"gyro.xyz_rps":     generateGyro,
-		"temp.skin_c":      generateSkinTemp,
-		"eda.us":           generateEDA,
-		"screen.state":     generateScreenState,
-		"app.activity":     generateAppActivity,
-		"motion.activity":  generateMotionActivity,
+		"accel.xyz_mps2": generateAccel,
+		"gyro.xyz_rps":   generateGyro,
+		"temp.skin_c":    generateSkinTemp,
+		"eda.us":         generateEDA,
 	}
 }
 
-// generateHeartRate generates heart rate in BPM
-func generateHeartRate(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64) interface{} {
-	baseline := getFloat(config.Baseline, 72.0)
-	noise := getFloat(config.Noise, 3.0)
-
-	// Apply modifiers
-	value := baseline
-	if config.Add != 0 {
-		value += config.Add
+// SignalNames returns the sorted names of all available signals
+func SignalNames() []string {
+	signals := GetAllSignals()
+	names := make([]string, 0, len(signals)+len(heartSignals)+len(scenario.DiscreteSignals))
+	for name := range signals {
+		names = append(names, name)
 	}
-	if config.Multiply != 0 {
-		value *= config.Multiply
-	}
-
-	// Add random noise
-	value += rng.NormFloat64() * noise
-
-	// Clamp to realistic range
-	return clamp(value, 40, 200)
-}
-
-// generateHRV generates heart rate variability in milliseconds
-func generateHRV(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64) interface{} {
-	baseline := getFloat(config.Baseline, 50.0)
-	noise := getFloat(config.Noise, 8.0)
-
-	value := baseline
-	if config.Multiply != 0 {
-		value *= config.Multiply
+	for name := range heartSignals {
+		names = append(names, name)
 	}
-
-	value += rng.NormFloat64() * noise
-
-	return clamp(value, 10, 150)
+	names = append(names, scenario.DiscreteSignals...)
+	sort.Strings(names)
+	return names
 }
 
 // generateAccel generates 3D acceleration vector in m/s²
@@ -93,7 +70,7 @@ func generateSkinTemp(rng *rand.Rand, config *scenario.SignalConfig, elapsed flo
 	// Slow sinusoidal drift
 	drift := math.Sin(elapsed/600.0) * 0.3
 
-	value := baseline + drift + rng.NormFloat64()*noise
+	value := applyModifiers(baseline, config) + drift + rng.NormFloat64()*noise
 
 	return clamp(value, 30, 37)
 }
@@ -103,61 +80,26 @@ func generateEDA(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64)
 	baseline := getFloat(config.Baseline, 2.0)
 	noise := getFloat(config.Noise, 0.2)
 
-	value := baseline
-	if config.Add != 0 {
-		value += config.Add
-	}
-
+	value := applyModifiers(baseline, config)
 	value += rng.NormFloat64() * noise
 
 	return clamp(value, 0.1, 20)
 }
 
-// generateScreenState generates screen on/off state
-func generateScreenState(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64) interface{} {
-	if config.Value != "" {
-		return config.Value
-	}
-
-	// Random on/off with some persistence
-	if rng.Float64() > 0.95 {
-		return "off"
-	}
-	return "on"
-}
-
-// generateAppActivity generates app foreground/background state
-func generateAppActivity(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64) interface{} {
-	if config.Value != "" {
-		return config.Value
-	}
-
-	activities := []string{"foreground", "background", "typing", "scrolling"}
-	return activities[rng.Intn(len(activities))]
-}
+// Helper functions
 
-// generateMotionActivity generates motion activity state
-func generateMotionActivity(rng *rand.Rand, config *scenario.SignalConfig, elapsed float64) interface{} {
-	if config.Value != "" {
-		return config.Value
+// applyModifiers applies the (possibly ramped) add and multiply modifiers to a baseline
+func applyModifiers(baseline float64, config *scenario.SignalConfig) float64 {
+	value := baseline
+	if config.Add != 0 {
+		value += config.Add
 	}
-
-	activities := []string{"still", "walk", "run"}
-	weights := []float64{0.7, 0.25, 0.05}
-
-	r := rng.Float64()
-	cumulative := 0.0
-	for i, weight := range weights {
-		cumulative += weight
-		if r < cumulative {
-			return activities[i]
-		}
+	if config.Multiply != 0 {
+		value *= config.Multiply
 	}
-	return "still"
+	return value
 }
 
-// Helper functions
-
 func getFloat(val interface{}, defaultVal float64) float64 {
 	switch v := val.(type) {
 	case float64:
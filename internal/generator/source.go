@@ -0,0 +1,182 @@
+package generator
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// sourceState tracks generation state for one device in a session
+type sourceState struct {
+	source      models.Source
+	rng         *rand.Rand
+	signalNames []string // sorted so the RNG is consumed in a stable order
+	sequence    int64
+	lastEmit    map[string]time.Time
+	minInterval time.Duration // caps the emission rate of every signal, 0 for no cap
+	skew        time.Duration // offset applied to the device's timestamps
+	beatsSeen   time.Duration // end of the last subject beat returned by newBeats
+	beatOffset  float64       // detection jitter of that beat, ms
+	ppg         *ppgModel     // raw waveform drawn over the subject's beats
+	blocks      map[string]*sampleBlock
+	correlator  *correlator // rebuilt when the engine switches scenario
+}
+
+func newSourceState(source models.Source, seed int64, signalNames []string, minInterval, skew time.Duration) *sourceState {
+	return &sourceState{
+		source:      source,
+		rng:         rand.New(rand.NewSource(seed)),
+		signalNames: signalNames,
+		lastEmit:    make(map[string]time.Time),
+		minInterval: minInterval,
+		skew:        skew,
+		ppg:         newPPGModel(seed),
+		blocks:      make(map[string]*sampleBlock),
+	}
+}
+
+// newBeats returns the subject's beats completed since the previous call, as
+// the device detects them: each beat is offset by detection jitter, and its
+// interval measured between the detected beats
+func (s *sourceState) newBeats(heart *heartModel) []beat {
+	if s.beatsSeen > heart.last() {
+		s.beatsSeen = 0 // the heart restarted earlier in time
+	}
+	beats := heart.beatsAfter(s.beatsSeen)
+	for i, b := range beats {
+		offset := s.rng.NormFloat64() * beatJitter
+		beats[i].rr = b.rr + offset - s.beatOffset
+		beats[i].at = b.at + time.Duration(offset*float64(time.Millisecond))
+		s.beatsSeen, s.beatOffset = b.at, offset
+	}
+	return beats
+}
+
+// subjectState is the person every source in a session observes. The state
+// chains of the discrete signals (motion.activity, and the phone's screen and
+// app) and the beat-to-beat heart run once per session, so devices worn by the
+// same subject agree on them; each source adds only its own sensor noise.
+type subjectState struct {
+	chains   map[string]*markovChain // state of each discrete signal
+	chainRNG *rand.Rand
+	heart    *heartModel // beats behind every source's heart signals
+}
+
+func newSubjectState(seed int64) *subjectState {
+	return &subjectState{
+		chains:   make(map[string]*markovChain),
+		chainRNG: rand.New(rand.NewSource(seed ^ markovSeedSalt)),
+		heart:    newHeartModel(seed),
+	}
+}
+
+// newSourceStateFromConfig builds source state from a declared source. Each source
+// draws from its own RNG stream derived from the session seed and the source ID.
+// The config is expected to have passed ValidateSources.
+func newSourceStateFromConfig(config scenario.SourceConfig, seed int64, allSignals []string) *sourceState {
+	var side *string
+	if config.Side != "" {
+		s := config.Side
+		side = &s
+	}
+	source := models.Source{
+		Type: config.Type,
+		ID:   config.ID,
+		Side: side,
+	}
+
+	names := allSignals
+	if len(config.Signals) > 0 {
+		wanted := make(map[string]bool, len(config.Signals))
+		for _, name := range config.Signals {
+			wanted[name] = true
+		}
+		names = make([]string, 0, len(config.Signals))
+		for _, name := range allSignals {
+			if wanted[name] {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var minInterval time.Duration
+	if config.Rate != "" {
+		minInterval, _ = scenario.ParseRate(config.Rate)
+	}
+	var skew time.Duration
+	if config.Skew != "" {
+		skew, _ = time.ParseDuration(config.Skew)
+	}
+
+	return newSourceState(source, deriveSeed(seed, config.ID), names, minInterval, skew)
+}
+
+// deriveSeed derives a per-source seed from the session seed and source ID
+func deriveSeed(seed int64, sourceID string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(sourceID))
+	return seed ^ int64(h.Sum64())
+}
+
+// ValidateSources checks declared sources for unique IDs, known types and signals,
+// and parseable rates and skews
+func ValidateSources(sources []scenario.SourceConfig) error {
+	known := SignalNames()
+	seen := make(map[string]bool, len(sources))
+
+	for i, source := range sources {
+		if err := source.Validate(known); err != nil {
+			if source.ID == "" {
+				return fmt.Errorf("source %d: %w", i+1, err)
+			}
+			return fmt.Errorf("source %s: %w", source.ID, err)
+		}
+		if seen[source.ID] {
+			return fmt.Errorf("source %s: duplicate id", source.ID)
+		}
+		seen[source.ID] = true
+	}
+	return nil
+}
+
+// ParseSourceSpec parses a --source flag value such as
+// "type=wearable,id=watch-left,side=left,signals=ppg.hr_bpm+accel.xyz_mps2,rate=25hz,skew=20ms"
+func ParseSourceSpec(spec string) (scenario.SourceConfig, error) {
+	var source scenario.SourceConfig
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return source, fmt.Errorf("invalid source field %q (expected key=value)", part)
+		}
+		switch strings.TrimSpace(key) {
+		case "type":
+			source.Type = value
+		case "id":
+			source.ID = value
+		case "side":
+			source.Side = value
+		case "signals":
+			for _, name := range strings.Split(value, "+") {
+				if name = strings.TrimSpace(name); name != "" {
+					source.Signals = append(source.Signals, name)
+				}
+			}
+		case "rate":
+			source.Rate = value
+		case "skew":
+			source.Skew = value
+		default:
+			return source, fmt.Errorf("unknown source field %q", key)
+		}
+	}
+	return source, nil
+}
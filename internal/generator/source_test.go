@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/clock"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+func TestParseSourceSpec(t *testing.T) {
+	source, err := ParseSourceSpec("type=wearable,id=watch-right,side=right,signals=ppg.hr_bpm+accel.xyz_mps2,rate=25hz,skew=-150ms")
+	if err != nil {
+		t.Fatalf("ParseSourceSpec failed: %v", err)
+	}
+	if source.Type != "wearable" || source.ID != "watch-right" || source.Side != "right" {
+		t.Errorf("unexpected source: %+v", source)
+	}
+	if len(source.Signals) != 2 || source.Signals[1] != "accel.xyz_mps2" {
+		t.Errorf("unexpected signals: %v", source.Signals)
+	}
+	if source.Rate != "25hz" || source.Skew != "-150ms" {
+		t.Errorf("unexpected rate/skew: %s %s", source.Rate, source.Skew)
+	}
+
+	if _, err := ParseSourceSpec("type=phone,color=blue"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestValidateSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []scenario.SourceConfig
+		wantErr bool
+	}{
+		{"empty", nil, false},
+		{"valid", []scenario.SourceConfig{{ID: "phone", Type: "phone"}, {ID: "watch", Type: "wearable", Side: "left"}}, false},
+		{"missing id", []scenario.SourceConfig{{Type: "phone"}}, true},
+		{"duplicate id", []scenario.SourceConfig{{ID: "a", Type: "phone"}, {ID: "a", Type: "phone"}}, true},
+		{"bad type", []scenario.SourceConfig{{ID: "a", Type: "tablet"}}, true},
+		{"unknown signal", []scenario.SourceConfig{{ID: "a", Type: "phone", Signals: []string{"ppg.hr"}}}, true},
+		{"bad rate", []scenario.SourceConfig{{ID: "a", Type: "phone", Rate: "fast"}}, true},
+		{"bad skew", []scenario.SourceConfig{{ID: "a", Type: "phone", Skew: "soon"}}, true},
+	}
+
+	for _, test := range tests {
+		err := ValidateSources(test.sources)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: expected error=%v, got %v", test.name, test.wantErr, err)
+		}
+	}
+}
+
+func TestGenerateMultipleSources(t *testing.T) {
+	epoch := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewVirtual(epoch)
+	engine := scenario.NewEngineWithClock(testScenario(), clk)
+
+	gen := NewGenerator(engine, Config{
+		Seed:  1,
+		Clock: clk,
+		Sources: []scenario.SourceConfig{
+			{ID: "phone", Type: "phone", Signals: []string{"accel.xyz_mps2"}, Rate: "5hz"},
+			{ID: "watch", Type: "wearable", Side: "left", Signals: []string{"ppg.hr_bpm", "accel.xyz_mps2"}, Skew: "250ms"},
+		},
+	})
+	gen.resetEmitTimes()
+
+	counts := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		clk.Advance(100 * time.Millisecond)
+		for _, event := range gen.generateTick() {
+			counts[event.Source.ID+"/"+event.Signal.Name]++
+
+			if event.Session.RunID != gen.GetRunID() {
+				t.Errorf("expected shared run ID %s, got %s", gen.GetRunID(), event.Session.RunID)
+			}
+
+			ts, _ := time.Parse(time.RFC3339Nano, event.Timestamp)
+			want := clk.Now()
+			if event.Source.ID == "watch" {
+				want = want.Add(250 * time.Millisecond)
+			}
+			if !ts.Equal(want) {
+				t.Errorf("%s: expected timestamp %v, got %v", event.Source.ID, want, ts)
+			}
+		}
+	}
+
+	// Phone accel is capped at 5hz, watch accel follows the 10hz scenario rate
+	if counts["phone/accel.xyz_mps2"] != 5 {
+		t.Errorf("expected 5 phone accel events, got %d", counts["phone/accel.xyz_mps2"])
+	}
+	if counts["watch/accel.xyz_mps2"] != 10 {
+		t.Errorf("expected 10 watch accel events, got %d", counts["watch/accel.xyz_mps2"])
+	}
+	if counts["phone/ppg.hr_bpm"] != 0 {
+		t.Error("expected phone to emit only its declared signals")
+	}
+	if counts["watch/ppg.hr_bpm"] != 1 {
+		t.Errorf("expected 1 watch HR event, got %d", counts["watch/ppg.hr_bpm"])
+	}
+}
+
+func TestSourcesShareSubject(t *testing.T) {
+	scen := &scenario.Scenario{
+		Name:     "two wrists",
+		Duration: "10m",
+		Signals: map[string]*scenario.SignalConfig{
+			"motion.activity": {Rate: "1hz"},
+			"ppg.hr_bpm":      {Baseline: 70.0, Noise: 5.0, Rate: "1hz"},
+			"ppg.rr_ms":       {},
+		},
+	}
+	events := renderVirtualConfig(t, scen, Config{Seed: 7, Sources: []scenario.SourceConfig{
+		{ID: "watch-left", Type: "wearable", Side: "left"},
+		{ID: "watch-right", Type: "wearable", Side: "right"},
+	}}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	values := make(map[string]map[string]interface{})
+	var beats [2][]float64
+	for _, event := range events {
+		if event.Signal.Name == "ppg.rr_ms" {
+			i := 0
+			if event.Source.ID == "watch-right" {
+				i = 1
+			}
+			beats[i] = append(beats[i], event.Signal.Value.(float64))
+			continue
+		}
+		key := event.Timestamp + " " + event.Signal.Name
+		if values[key] == nil {
+			values[key] = make(map[string]interface{})
+		}
+		values[key][event.Source.ID] = event.Signal.Value
+	}
+
+	// The wrists agree on the motion state, and on the heart rate up to
+	// measurement noise
+	shared := 0
+	for key, bySource := range values {
+		if len(bySource) != 2 {
+			continue
+		}
+		shared++
+		left, right := bySource["watch-left"], bySource["watch-right"]
+		if hr, ok := left.(float64); ok {
+			if math.Abs(hr-right.(float64)) > 3 {
+				t.Errorf("%s: the wrists disagree on the heart rate: %.1f and %.1f", key, hr, right)
+			}
+		} else if left != right {
+			t.Fatalf("%s: the wrists disagree on the motion state: %v and %v", key, left, right)
+		}
+	}
+	if shared < 1180 {
+		t.Errorf("expected both wrists every second, got %d shared readings", shared)
+	}
+
+	// They detect the same beats, a few ms apart
+	if len(beats[0]) != len(beats[1]) {
+		t.Fatalf("expected the same beats on both wrists, got %d and %d", len(beats[0]), len(beats[1]))
+	}
+	for i := range beats[0] {
+		if math.Abs(beats[0][i]-beats[1][i]) > 20 {
+			t.Errorf("beat %d: RR %.0f and %.0f ms", i, beats[0][i], beats[1][i])
+		}
+	}
+}
@@ -1,155 +1,474 @@
 package generator
 
 import (
-	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/synheart/synheart-cli/internal/clock"
 	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
 )
 
-// Aggregator collects individual events and packages them for Flux
+// Physiology used to turn the aggregated signals into vendor scores
+const (
+	restingEnergy = 4.9   // kJ/min, about 7000 kJ a day
+	maxHeartRate  = 190.0 // bpm, for heart rate reserve
+	strainScale   = 40.0  // cardiovascular load reaching about 2/3 of the 21 point strain scale
+	sleepNeed     = 8 * time.Hour
+	hrvNorm       = 50.0 // ms, RMSSD scoring 66% recovery at the resting HR norm
+	rhrNorm       = 60.0 // bpm
+)
+
+// Step detection from the accelerometer: acceleration beyond gravity classifies
+// each sample as still, walking or running, which sets the cadence
+const (
+	stepWalkAccel = 0.8 // m/s², below the walking magnitudes kept by the correlations
+	stepRunAccel  = 3.0 // m/s², just under runAccel
+	walkCadence   = 1.8 // steps/s
+	runCadence    = 2.7 // steps/s
+	maxStepGap    = time.Second
+)
+
+// VendorFormatter renders an aggregated window in one vendor's payload format
+type VendorFormatter interface {
+	Name() string
+	ContentType() string
+	Format(w VendorWindow) ([]byte, error)
+}
+
+// vendorFormatters holds the formats available to --vendor
+var vendorFormatters = map[string]VendorFormatter{
+	"whoop":        whoopFormatter{},
+	"garmin":       garminFormatter{},
+	"oura":         ouraFormatter{},
+	"fitbit":       fitbitFormatter{},
+	"apple_health": appleHealthFormatter{},
+	"polar":        polarFormatter{},
+}
+
+// VendorNames returns the sorted names of the vendor formats
+func VendorNames() []string {
+	names := make([]string, 0, len(vendorFormatters))
+	for name := range vendorFormatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupVendor returns the formatter for a vendor name
+func LookupVendor(name string) (VendorFormatter, error) {
+	f, ok := vendorFormatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown vendor %q (expected %s)", name, strings.Join(VendorNames(), "|"))
+	}
+	return f, nil
+}
+
+// SleepSegment is a stretch of one sleep stage
+type SleepSegment struct {
+	Stage      string
+	Start, End time.Time
+}
+
+// VendorSleep is one sleep period assembled from the scenario's sleep stages.
+// ID is 0 for the default night reported before any sleep was staged.
+type VendorSleep struct {
+	ID       int64
+	Segments []SleepSegment
+}
+
+func (s VendorSleep) Start() time.Time {
+	return s.Segments[0].Start
+}
+
+func (s VendorSleep) End() time.Time {
+	return s.Segments[len(s.Segments)-1].End
+}
+
+// Stage returns the total time spent in a sleep stage
+func (s VendorSleep) Stage(stage string) time.Duration {
+	var total time.Duration
+	for _, seg := range s.Segments {
+		if seg.Stage == stage {
+			total += seg.End.Sub(seg.Start)
+		}
+	}
+	return total
+}
+
+func (s VendorSleep) InBed() time.Duration {
+	return s.End().Sub(s.Start())
+}
+
+func (s VendorSleep) Asleep() time.Duration {
+	return s.InBed() - s.Stage(scenario.SleepAwake)
+}
+
+// Disturbances counts the returns to awake after falling asleep
+func (s VendorSleep) Disturbances() int {
+	n := 0
+	for i := 1; i < len(s.Segments); i++ {
+		if s.Segments[i].Stage == scenario.SleepAwake && s.Segments[i-1].Stage != scenario.SleepAwake {
+			n++
+		}
+	}
+	return n
+}
+
+// Performance is the share of the sleep need met, in percent
+func (s VendorSleep) Performance() float64 {
+	return math.Min(100, 100*s.Asleep().Seconds()/sleepNeed.Seconds())
+}
+
+// Efficiency is the share of time in bed spent asleep, in percent
+func (s VendorSleep) Efficiency() float64 {
+	if s.InBed() <= 0 {
+		return 0
+	}
+	return 100 * s.Asleep().Seconds() / s.InBed().Seconds()
+}
+
+// defaultNight is the sleep reported before a scenario has staged any sleep:
+// 8 hours in bed through four cycles, ending at the given time
+func defaultNight(end time.Time) VendorSleep {
+	stages := []struct {
+		stage   string
+		minutes int
+	}{
+		{scenario.SleepAwake, 10}, {scenario.SleepLight, 70}, {scenario.SleepDeep, 60},
+		{scenario.SleepLight, 40}, {scenario.SleepREM, 40}, {scenario.SleepDeep, 60},
+		{scenario.SleepLight, 50}, {scenario.SleepREM, 50}, {scenario.SleepLight, 50},
+		{scenario.SleepAwake, 10}, {scenario.SleepREM, 30}, {scenario.SleepAwake, 10},
+	}
+	total := 0
+	for _, s := range stages {
+		total += s.minutes
+	}
+	at := end.Add(-time.Duration(total) * time.Minute)
+	night := VendorSleep{}
+	for _, s := range stages {
+		next := at.Add(time.Duration(s.minutes) * time.Minute)
+		night.Segments = append(night.Segments, SleepSegment{Stage: s.stage, Start: at, End: next})
+		at = next
+	}
+	return night
+}
+
+// VendorWindow holds the statistics of one aggregated window, which formatters
+// render as a payload. ID increments with each window.
+type VendorWindow struct {
+	ID         int64
+	Start, End time.Time
+	HRAvg      float64 // bpm
+	HRMax      float64
+	HRMin      float64
+	RestingHR  float64 // 10th percentile of the window
+	HRV        float64 // RMSSD, ms
+	SkinTemp   float64 // °C
+	Steps      int
+	Kilojoules float64
+	Strain     float64 // 0-21
+	Recovery   float64 // 1-99
+	Sleep      VendorSleep
+}
+
+// Duration returns the time covered by the window
+func (w VendorWindow) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// Kilocalories returns the window's energy expenditure in kcal
+func (w VendorWindow) Kilocalories() float64 {
+	return w.Kilojoules / 4.184
+}
+
+// ActiveKilocalories returns the energy above resting expenditure in kcal
+func (w VendorWindow) ActiveKilocalories() float64 {
+	return math.Max(0, w.Kilojoules-restingEnergy*w.Duration().Minutes()) / 4.184
+}
+
+// hrBinsPerBpm and maxBinnedHR set the resolution and range of the heart rate
+// histogram behind the resting heart rate and cardiovascular load
+const (
+	hrBinsPerBpm = 10
+	maxBinnedHR  = 300
+)
+
+// windowStats accumulates the statistics of the open window as events arrive,
+// so a window costs the same memory however long it is
+type windowStats struct {
+	count      int
+	start, end time.Time
+
+	hrCount             int
+	hrSum, energySum    float64 // energySum in kJ/min, averaged over the window
+	hrMin, hrMax        float64
+	hrBins              [maxBinnedHR * hrBinsPerBpm]int
+	hrvSum, tempSum     float64
+	hrvCount, tempCount int
+
+	steps     map[string]float64   // per source
+	lastAccel map[string]time.Time // per source, for the period of single samples
+}
+
+// Aggregator collects individual events and packages them for Flux. Payloads
+// describe the aggregated window: its time range, heart rate and HRV statistics,
+// energy and strain from heart rate, steps from the accelerometer, and the most
+// recent sleep staged by the scenario.
 type Aggregator struct {
-	events []models.Event
+	stats windowStats
+	clock clock.Clock
+
+	windowID int64 // incremented by Clear, shared by snapshots of an open window
+	sleepID  int64 // incremented for each sleep
+	first    time.Time
+
+	stage     string       // current sleep stage, empty while not in bed
+	sleep     *VendorSleep // sleep in progress, its last segment open
+	lastSleep *VendorSleep // most recent completed sleep
+
+	// carried into windows without the signal
+	lastHR, lastHRV, lastTemp float64
 }
 
-func NewAggregator() *Aggregator {
-	return &Aggregator{
-		events: make([]models.Event, 0),
+func NewAggregator(clk clock.Clock) *Aggregator {
+	a := &Aggregator{
+		clock:    clk,
+		windowID: 1,
+		lastHR:   rhrNorm,
+		lastHRV:  hrvNorm,
+		lastTemp: 33.0,
 	}
+	a.reset()
+	return a
 }
 
 func (a *Aggregator) Add(event models.Event) {
-	a.events = append(a.events, event)
-}
-
-// ToWhoopJSON converts collected events to a Whoop-like JSON
-func (a *Aggregator) ToWhoopJSON() (string, error) {
-	type WhoopPayload struct {
-		Sleep    []interface{} `json:"sleep"`
-		Recovery []interface{} `json:"recovery"`
-		Cycle    []interface{} `json:"cycle"`
-	}
-
-	payload := WhoopPayload{
-		Sleep:    make([]interface{}, 0),
-		Recovery: make([]interface{}, 0),
-		Cycle:    make([]interface{}, 0),
-	}
-
-	now := time.Now().UTC()
-	hrv, rhr := a.extractPhysiology()
-
-	payload.Recovery = append(payload.Recovery, map[string]interface{}{
-		"cycle_id":   1,
-		"created_at": now.Format(time.RFC3339),
-		"score": map[string]interface{}{
-			"recovery_score":     75.0,
-			"resting_heart_rate": rhr,
-			"hrv_rmssd_milli":    hrv,
-		},
-	})
-
-	payload.Cycle = append(payload.Cycle, map[string]interface{}{
-		"id":    1,
-		"start": now.Add(-12 * time.Hour).Format(time.RFC3339),
-		"end":   now.Format(time.RFC3339),
-		"score": map[string]interface{}{
-			"strain":             12.5,
-			"kilojoule":          8000.0,
-			"average_heart_rate": rhr + 10,
-			"max_heart_rate":     rhr + 50,
-		},
-	})
-
-	payload.Sleep = append(payload.Sleep, map[string]interface{}{
-		"id":    1,
-		"start": now.Add(-20 * time.Hour).Format(time.RFC3339),
-		"end":   now.Add(-12 * time.Hour).Format(time.RFC3339),
-		"score": map[string]interface{}{
-			"stage_summary": map[string]interface{}{
-				"total_in_bed_time_milli":          28800000,
-				"total_awake_time_milli":           1800000,
-				"total_light_sleep_time_milli":     12600000,
-				"total_slow_wave_sleep_time_milli": 7200000,
-				"total_rem_sleep_time_milli":       7200000,
-				"total_sleep_time_milli":           27000000,
-				"disturbance_count":                3,
-			},
-			"sleep_performance_percentage": 85.0,
-			"respiratory_rate":             14.5,
-		},
-	})
-
-	bytes, err := json.Marshal(payload)
-	return string(bytes), err
-}
-
-// ToGarminJSON converts collected events to a Garmin-like JSON
-func (a *Aggregator) ToGarminJSON() (string, error) {
-	type GarminPayload struct {
-		Dailies []map[string]interface{} `json:"dailies"`
-		Sleep   []map[string]interface{} `json:"sleep"`
-	}
-
-	hrv, rhr := a.extractPhysiology()
-	today := time.Now().Format("2006-01-02")
-	nowMs := time.Now().UnixMilli()
-
-	payload := GarminPayload{
-		Dailies: []map[string]interface{}{{
-			"calendarDate":            today,
-			"totalSteps":              8500,
-			"totalKilocalories":       2200,
-			"restingHeartRate":        int(rhr),
-			"restingHeartRateHrv":     hrv,
-			"averageHeartRate":        int(rhr + 10),
-			"maxHeartRate":            int(rhr + 50),
-			"bodyBatteryChargedValue": 72,
-			"trainingLoadBalance":     45.5,
-		}},
-		Sleep: []map[string]interface{}{{
-			"calendarDate":        today,
-			"sleepTimeSeconds":    25200,
-			"awakeSleepSeconds":   1800,
-			"lightSleepSeconds":   10800,
-			"deepSleepSeconds":    6300,
-			"remSleepSeconds":     6300,
-			"awakeCount":          2,
-			"avgSleepRespiration": 13.5,
-			"sleepScores": map[string]interface{}{
-				"overallScore": 78.0,
-			},
-			"sleepStartTimestampGmt": nowMs - (20 * 3600 * 1000),
-			"sleepEndTimestampGmt":   nowMs - (12 * 3600 * 1000),
-		}},
-	}
-
-	bytes, err := json.Marshal(payload)
-	return string(bytes), err
-}
-
-func (a *Aggregator) extractPhysiology() (float64, float64) {
-	hrv := 50.0
-	rhr := 60.0
-	for _, e := range a.events {
-		if e.Signal.Name == "ppg.hrv_rmssd_ms" {
-			if v, ok := e.Signal.Value.(float64); ok {
-				hrv = v
+	ts, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+	if err != nil {
+		return
+	}
+	if a.first.IsZero() {
+		a.first = ts
+	}
+
+	w := &a.stats
+	w.count++
+	if w.start.IsZero() || ts.Before(w.start) {
+		w.start = ts
+	}
+	if ts.After(w.end) {
+		w.end = ts
+	}
+
+	switch event.Signal.Name {
+	case "ppg.hr_bpm":
+		if v, ok := event.Signal.Value.(float64); ok {
+			if w.hrCount == 0 || v < w.hrMin {
+				w.hrMin = v
+			}
+			if w.hrCount == 0 || v > w.hrMax {
+				w.hrMax = v
 			}
+			w.hrCount++
+			w.hrSum += v
+			w.energySum += heartRateEnergy(v)
+			w.hrBins[hrBin(v)]++
+		}
+	case "ppg.hrv_rmssd_ms":
+		if v, ok := event.Signal.Value.(float64); ok {
+			w.hrvSum += v
+			w.hrvCount++
+		}
+	case "temp.skin_c":
+		if v, ok := event.Signal.Value.(float64); ok {
+			w.tempSum += v
+			w.tempCount++
 		}
-		if e.Signal.Name == "ppg.hr_bpm" {
-			if v, ok := e.Signal.Value.(float64); ok {
-				rhr = v
+	case "accel.xyz_mps2":
+		w.steps[event.Source.ID] += accelSteps(event, ts, w.lastAccel[event.Source.ID])
+		w.lastAccel[event.Source.ID] = ts
+	}
+}
+
+// hrBin returns the histogram bin of a heart rate
+func hrBin(hr float64) int {
+	return int(clamp(math.Floor(hr*hrBinsPerBpm), 0, maxBinnedHR*hrBinsPerBpm-1))
+}
+
+// heartRateEnergy returns the energy expenditure at a heart rate in kJ/min
+// (Keytel et al. for an average adult, floored at resting expenditure)
+func heartRateEnergy(hr float64) float64 {
+	return math.Max(restingEnergy, -55.0969+0.6309*hr+0.1988*75+0.2017*35)
+}
+
+// ObserveSleep records the scenario's sleep stage at a point in time: one of the
+// scenario.SleepStages, or empty when not in bed. Consecutive staged time forms
+// one sleep, completed when the stage returns to empty.
+func (a *Aggregator) ObserveSleep(stage string, at time.Time) {
+	if stage == a.stage {
+		return
+	}
+	if a.sleep != nil {
+		a.sleep.Segments[len(a.sleep.Segments)-1].End = at
+	}
+	switch {
+	case stage == "":
+		a.lastSleep = a.sleep
+		a.sleep = nil
+	case a.sleep == nil:
+		a.sleepID++
+		a.sleep = &VendorSleep{ID: a.sleepID}
+	}
+	if stage != "" {
+		a.sleep.Segments = append(a.sleep.Segments, SleepSegment{Stage: stage, Start: at, End: at})
+	}
+	a.stage = stage
+}
+
+// currentSleep returns the sleep to report: the latest completed one, else the
+// one in progress up to now, else a default night before the data began
+func (a *Aggregator) currentSleep() VendorSleep {
+	if a.lastSleep != nil {
+		return *a.lastSleep
+	}
+	if a.sleep != nil {
+		partial := VendorSleep{ID: a.sleep.ID, Segments: append([]SleepSegment(nil), a.sleep.Segments...)}
+		partial.Segments[len(partial.Segments)-1].End = a.clock.Now()
+		return partial
+	}
+
+	end := a.first
+	if end.IsZero() {
+		end = a.clock.Now()
+	}
+	return defaultNight(end)
+}
+
+// Payload renders the aggregated window with a vendor formatter
+func (a *Aggregator) Payload(f VendorFormatter) ([]byte, error) {
+	return f.Format(a.Window())
+}
+
+// Window computes the statistics of the events aggregated since the last Clear
+func (a *Aggregator) Window() VendorWindow {
+	stats := &a.stats
+	w := VendorWindow{ID: a.windowID, Sleep: a.currentSleep(), Start: stats.start, End: stats.end}
+	if w.Start.IsZero() {
+		w.Start = a.clock.Now()
+		w.End = w.Start
+	}
+
+	if stats.hrCount > 0 {
+		w.HRAvg = stats.hrSum / float64(stats.hrCount)
+		w.HRMin = stats.hrMin
+		w.HRMax = stats.hrMax
+		w.RestingHR = clamp(stats.percentileHR(0.1), w.HRMin, w.HRMax)
+		a.lastHR = w.RestingHR
+	} else {
+		w.HRAvg, w.HRMin, w.HRMax, w.RestingHR = a.lastHR, a.lastHR, a.lastHR, a.lastHR
+	}
+	if stats.hrvCount > 0 {
+		a.lastHRV = stats.hrvSum / float64(stats.hrvCount)
+	}
+	w.HRV = a.lastHRV
+	if stats.tempCount > 0 {
+		a.lastTemp = stats.tempSum / float64(stats.tempCount)
+	}
+	w.SkinTemp = a.lastTemp
+
+	// Several devices may see the same steps; count the busiest
+	for _, n := range stats.steps {
+		if int(n) > w.Steps {
+			w.Steps = int(n)
+		}
+	}
+
+	// Energy and load from heart rate over the window (TRIMP for the load)
+	minutes := w.Duration().Minutes()
+	energy, load := restingEnergy, 0.0
+	if stats.hrCount > 0 {
+		energy = stats.energySum / float64(stats.hrCount)
+		for bin, n := range stats.hrBins {
+			if n == 0 {
+				continue
 			}
+			hr := float64(bin) / hrBinsPerBpm
+			reserve := math.Max(0, (hr-w.RestingHR)/(maxHeartRate-w.RestingHR))
+			load += float64(n) * reserve * 0.64 * math.Exp(1.92*reserve)
+		}
+		load /= float64(stats.hrCount)
+	}
+	w.Kilojoules = energy * minutes
+	w.Strain = 21 * (1 - math.Exp(-load*minutes/strainScale))
+	w.Recovery = clamp(66+40*math.Log(w.HRV/hrvNorm)-1.5*(w.RestingHR-rhrNorm), 1, 99)
+	return w
+}
+
+// percentileHR returns the heart rate below which the fraction p of the
+// window's readings fall, to the resolution of the histogram
+func (w *windowStats) percentileHR(p float64) float64 {
+	rank := int(p * float64(w.hrCount))
+	seen := 0
+	for bin, n := range w.hrBins {
+		seen += n
+		if seen > rank {
+			return float64(bin) / hrBinsPerBpm
+		}
+	}
+	return w.hrMax
+}
+
+// accelSteps estimates the steps taken over an accelerometer event from its
+// acceleration beyond gravity and the time since the source's previous sample
+func accelSteps(e models.Event, ts, previous time.Time) float64 {
+	var samples [][]float64
+	period := ts.Sub(previous)
+	switch v := e.Signal.Value.(type) {
+	case []float64:
+		samples = [][]float64{v}
+	case [][]float64:
+		samples = v
+		period = time.Duration(e.Signal.SamplePeriodMs * float64(time.Millisecond))
+	}
+	if previous.IsZero() && !e.Signal.IsBlock() {
+		return 0
+	}
+	if period <= 0 || period > maxStepGap {
+		period = maxStepGap
+	}
+
+	steps := 0.0
+	for _, sample := range samples {
+		if len(sample) < 3 {
+			continue
+		}
+		dynamic := math.Abs(vectorMagnitude(sample) - gravity)
+		switch {
+		case dynamic >= stepRunAccel:
+			steps += runCadence * period.Seconds()
+		case dynamic >= stepWalkAccel:
+			steps += walkCadence * period.Seconds()
 		}
 	}
-	return hrv, rhr
+	return steps
 }
 
+// Clear empties the aggregator for the next window
 func (a *Aggregator) Clear() {
-	a.events = a.events[:0]
+	a.reset()
+	a.windowID++
+}
+
+// reset drops the statistics of the open window without using up its ID
+func (a *Aggregator) reset() {
+	a.stats = windowStats{steps: make(map[string]float64), lastAccel: make(map[string]time.Time)}
 }
 
 func (a *Aggregator) Count() int {
-	return len(a.events)
+	return a.stats.count
 }
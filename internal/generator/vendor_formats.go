@@ -0,0 +1,394 @@
+package generator
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// Payload shapes follow each vendor's public API closely enough for Flux and
+// client code, with the fields a window can fill; see the README for coverage.
+
+const (
+	contentTypeJSON = "application/json"
+	contentTypeXML  = "application/xml"
+)
+
+// whoopFormatter renders the Whoop developer API's cycle, recovery and sleep
+type whoopFormatter struct{}
+
+func (whoopFormatter) Name() string        { return "whoop" }
+func (whoopFormatter) ContentType() string { return contentTypeJSON }
+
+func (whoopFormatter) Format(w VendorWindow) ([]byte, error) {
+	sleep := w.Sleep
+	return json.Marshal(map[string]interface{}{
+		"recovery": []interface{}{map[string]interface{}{
+			"cycle_id":    w.ID,
+			"sleep_id":    sleep.ID,
+			"created_at":  w.End.UTC().Format(time.RFC3339),
+			"score_state": "SCORED",
+			"score": map[string]interface{}{
+				"recovery_score":     round(w.Recovery, 0),
+				"resting_heart_rate": round(w.RestingHR, 0),
+				"hrv_rmssd_milli":    round(w.HRV, 3),
+				"skin_temp_celsius":  round(w.SkinTemp, 2),
+			},
+		}},
+		"cycle": []interface{}{map[string]interface{}{
+			"id":          w.ID,
+			"start":       w.Start.UTC().Format(time.RFC3339),
+			"end":         w.End.UTC().Format(time.RFC3339),
+			"score_state": "SCORED",
+			"score": map[string]interface{}{
+				"strain":             round(w.Strain, 2),
+				"kilojoule":          round(w.Kilojoules, 1),
+				"average_heart_rate": round(w.HRAvg, 0),
+				"max_heart_rate":     round(w.HRMax, 0),
+			},
+		}},
+		"sleep": []interface{}{map[string]interface{}{
+			"id":          sleep.ID,
+			"start":       sleep.Start().UTC().Format(time.RFC3339),
+			"end":         sleep.End().UTC().Format(time.RFC3339),
+			"score_state": "SCORED",
+			"score": map[string]interface{}{
+				"stage_summary": map[string]interface{}{
+					"total_in_bed_time_milli":          sleep.InBed().Milliseconds(),
+					"total_awake_time_milli":           sleep.Stage(scenario.SleepAwake).Milliseconds(),
+					"total_light_sleep_time_milli":     sleep.Stage(scenario.SleepLight).Milliseconds(),
+					"total_slow_wave_sleep_time_milli": sleep.Stage(scenario.SleepDeep).Milliseconds(),
+					"total_rem_sleep_time_milli":       sleep.Stage(scenario.SleepREM).Milliseconds(),
+					"total_sleep_time_milli":           sleep.Asleep().Milliseconds(),
+					"disturbance_count":                sleep.Disturbances(),
+				},
+				"sleep_performance_percentage": round(sleep.Performance(), 0),
+				"sleep_efficiency_percentage":  round(sleep.Efficiency(), 1),
+				"respiratory_rate":             respirationHz * 60,
+			},
+		}},
+	})
+}
+
+// garminFormatter renders Garmin Health API dailies and sleeps
+type garminFormatter struct{}
+
+func (garminFormatter) Name() string        { return "garmin" }
+func (garminFormatter) ContentType() string { return contentTypeJSON }
+
+func (garminFormatter) Format(w VendorWindow) ([]byte, error) {
+	sleep := w.Sleep
+	return json.Marshal(map[string]interface{}{
+		"dailies": []interface{}{map[string]interface{}{
+			"summaryId":               fmt.Sprintf("daily-%d", w.ID),
+			"calendarDate":            w.Start.Format("2006-01-02"),
+			"startTimeInSeconds":      w.Start.Unix(),
+			"durationInSeconds":       int64(w.Duration().Seconds()),
+			"totalSteps":              w.Steps,
+			"totalKilocalories":       int(w.Kilocalories()),
+			"activeKilocalories":      int(w.ActiveKilocalories()),
+			"restingHeartRate":        int(math.Round(w.RestingHR)),
+			"restingHeartRateHrv":     round(w.HRV, 1),
+			"averageHeartRate":        int(math.Round(w.HRAvg)),
+			"minHeartRate":            int(math.Round(w.HRMin)),
+			"maxHeartRate":            int(math.Round(w.HRMax)),
+			"bodyBatteryChargedValue": int(math.Round(w.Recovery)),
+			"trainingLoadBalance":     round(w.Strain*3, 1),
+		}},
+		"sleep": []interface{}{map[string]interface{}{
+			"summaryId":           fmt.Sprintf("sleep-%d", sleep.ID),
+			"calendarDate":        sleep.End().Format("2006-01-02"),
+			"sleepTimeSeconds":    int64(sleep.Asleep().Seconds()),
+			"awakeSleepSeconds":   int64(sleep.Stage(scenario.SleepAwake).Seconds()),
+			"lightSleepSeconds":   int64(sleep.Stage(scenario.SleepLight).Seconds()),
+			"deepSleepSeconds":    int64(sleep.Stage(scenario.SleepDeep).Seconds()),
+			"remSleepSeconds":     int64(sleep.Stage(scenario.SleepREM).Seconds()),
+			"awakeCount":          sleep.Disturbances(),
+			"avgSleepRespiration": respirationHz * 60,
+			"sleepScores": map[string]interface{}{
+				"overallScore": round(sleep.Performance(), 0),
+			},
+			"sleepStartTimestampGmt": sleep.Start().UnixMilli(),
+			"sleepEndTimestampGmt":   sleep.End().UnixMilli(),
+		}},
+	})
+}
+
+// ouraFormatter renders Oura API v2 daily_activity, daily_readiness and sleep
+// documents
+type ouraFormatter struct{}
+
+func (ouraFormatter) Name() string        { return "oura" }
+func (ouraFormatter) ContentType() string { return contentTypeJSON }
+
+func (ouraFormatter) Format(w VendorWindow) ([]byte, error) {
+	sleep := w.Sleep
+	day := w.Start.Format("2006-01-02")
+	return json.Marshal(map[string]interface{}{
+		"daily_activity": []interface{}{map[string]interface{}{
+			"id":                          fmt.Sprintf("activity-%d", w.ID),
+			"day":                         day,
+			"timestamp":                   w.Start.UTC().Format(time.RFC3339),
+			"steps":                       w.Steps,
+			"total_calories":              int(w.Kilocalories()),
+			"active_calories":             int(w.ActiveKilocalories()),
+			"equivalent_walking_distance": int(float64(w.Steps) * 0.75),
+		}},
+		"daily_readiness": []interface{}{map[string]interface{}{
+			"id":                    fmt.Sprintf("readiness-%d", w.ID),
+			"day":                   day,
+			"timestamp":             w.End.UTC().Format(time.RFC3339),
+			"score":                 int(math.Round(w.Recovery)),
+			"temperature_deviation": round(w.SkinTemp-33.0, 2),
+			"contributors": map[string]interface{}{
+				"hrv_balance":        int(math.Round(clamp(100*w.HRV/(2*hrvNorm), 1, 100))),
+				"resting_heart_rate": int(math.Round(clamp(100-2*(w.RestingHR-rhrNorm), 1, 100))),
+				"previous_night":     int(math.Round(sleep.Performance())),
+				"sleep_balance":      int(math.Round(sleep.Performance())),
+			},
+		}},
+		"sleep": []interface{}{map[string]interface{}{
+			"id":                   fmt.Sprintf("sleep-%d", sleep.ID),
+			"day":                  sleep.End().Format("2006-01-02"),
+			"type":                 "long_sleep",
+			"bedtime_start":        sleep.Start().UTC().Format(time.RFC3339),
+			"bedtime_end":          sleep.End().UTC().Format(time.RFC3339),
+			"time_in_bed":          int64(sleep.InBed().Seconds()),
+			"total_sleep_duration": int64(sleep.Asleep().Seconds()),
+			"awake_time":           int64(sleep.Stage(scenario.SleepAwake).Seconds()),
+			"light_sleep_duration": int64(sleep.Stage(scenario.SleepLight).Seconds()),
+			"deep_sleep_duration":  int64(sleep.Stage(scenario.SleepDeep).Seconds()),
+			"rem_sleep_duration":   int64(sleep.Stage(scenario.SleepREM).Seconds()),
+			"efficiency":           int(math.Round(sleep.Efficiency())),
+			"restless_periods":     sleep.Disturbances(),
+			"average_heart_rate":   round(w.HRAvg, 1),
+			"lowest_heart_rate":    int(math.Round(w.HRMin)),
+			"average_hrv":          int(math.Round(w.HRV)),
+			"average_breath":       respirationHz * 60,
+		}},
+	})
+}
+
+// fitbitFormatter renders the Fitbit Web API daily activity summary, sleep log
+// and HRV responses side by side
+type fitbitFormatter struct{}
+
+func (fitbitFormatter) Name() string        { return "fitbit" }
+func (fitbitFormatter) ContentType() string { return contentTypeJSON }
+
+func (fitbitFormatter) Format(w VendorWindow) ([]byte, error) {
+	sleep := w.Sleep
+	minutes := func(d time.Duration) int { return int(d.Minutes()) }
+	stage := func(stage string) map[string]interface{} {
+		count := 0
+		for _, seg := range sleep.Segments {
+			if seg.Stage == stage {
+				count++
+			}
+		}
+		return map[string]interface{}{"count": count, "minutes": minutes(sleep.Stage(stage))}
+	}
+	local := "2006-01-02T15:04:05.000"
+	return json.Marshal(map[string]interface{}{
+		"activities-summary": map[string]interface{}{
+			"summary": map[string]interface{}{
+				"steps":            w.Steps,
+				"caloriesOut":      int(w.Kilocalories()),
+				"activityCalories": int(w.ActiveKilocalories()),
+				"restingHeartRate": int(math.Round(w.RestingHR)),
+			},
+		},
+		"sleep": []interface{}{map[string]interface{}{
+			"logId":         sleep.ID,
+			"dateOfSleep":   sleep.End().Format("2006-01-02"),
+			"startTime":     sleep.Start().Format(local),
+			"endTime":       sleep.End().Format(local),
+			"duration":      sleep.InBed().Milliseconds(),
+			"timeInBed":     minutes(sleep.InBed()),
+			"minutesAsleep": minutes(sleep.Asleep()),
+			"minutesAwake":  minutes(sleep.Stage(scenario.SleepAwake)),
+			"efficiency":    int(math.Round(sleep.Efficiency())),
+			"isMainSleep":   true,
+			"type":          "stages",
+			"levels": map[string]interface{}{
+				"summary": map[string]interface{}{
+					"wake":  stage(scenario.SleepAwake),
+					"light": stage(scenario.SleepLight),
+					"deep":  stage(scenario.SleepDeep),
+					"rem":   stage(scenario.SleepREM),
+				},
+			},
+		}},
+		"hrv": []interface{}{map[string]interface{}{
+			"dateTime": w.Start.Format("2006-01-02"),
+			"value": map[string]interface{}{
+				"dailyRmssd": round(w.HRV, 3),
+			},
+		}},
+	})
+}
+
+// polarFormatter renders Polar AccessLink daily activity, sleep and nightly
+// recharge
+type polarFormatter struct{}
+
+func (polarFormatter) Name() string        { return "polar" }
+func (polarFormatter) ContentType() string { return contentTypeJSON }
+
+func (polarFormatter) Format(w VendorWindow) ([]byte, error) {
+	sleep := w.Sleep
+	seconds := func(d time.Duration) int64 { return int64(d.Seconds()) }
+	local := "2006-01-02T15:04:05"
+	ansCharge := clamp((w.Recovery-50)/5, -10, 10)
+	return json.Marshal(map[string]interface{}{
+		"activity": []interface{}{map[string]interface{}{
+			"id":              w.ID,
+			"polar-user":      "https://www.polaraccesslink.com/v3/users/1",
+			"date":            w.Start.Format("2006-01-02"),
+			"created":         w.End.Format(local),
+			"duration":        isoDuration(w.Duration()),
+			"calories":        int(w.Kilocalories()),
+			"active-calories": int(w.ActiveKilocalories()),
+			"active-steps":    w.Steps,
+		}},
+		"nights": []interface{}{map[string]interface{}{
+			"polar_user":                  "https://www.polaraccesslink.com/v3/users/1",
+			"date":                        sleep.End().Format("2006-01-02"),
+			"sleep_start_time":            sleep.Start().Format(time.RFC3339),
+			"sleep_end_time":              sleep.End().Format(time.RFC3339),
+			"light_sleep":                 seconds(sleep.Stage(scenario.SleepLight)),
+			"deep_sleep":                  seconds(sleep.Stage(scenario.SleepDeep)),
+			"rem_sleep":                   seconds(sleep.Stage(scenario.SleepREM)),
+			"unrecognized_sleep_stage":    0,
+			"total_interruption_duration": seconds(sleep.Stage(scenario.SleepAwake)),
+			"sleep_score":                 int(math.Round(sleep.Performance())),
+			"sleep_charge":                int(math.Round(clamp(sleep.Performance()/20, 1, 5))),
+			"continuity":                  round(clamp(5-float64(sleep.Disturbances())/2, 1, 5), 1),
+		}},
+		"recharges": []interface{}{map[string]interface{}{
+			"polar_user":                 "https://www.polaraccesslink.com/v3/users/1",
+			"date":                       sleep.End().Format("2006-01-02"),
+			"heart_rate_avg":             int(math.Round(w.RestingHR)),
+			"beat_to_beat_avg":           int(math.Round(60000 / w.RestingHR)),
+			"heart_rate_variability_avg": int(math.Round(w.HRV)),
+			"breathing_rate_avg":         respirationHz * 60,
+			"ans_charge":                 round(ansCharge, 1),
+			"nightly_recharge_status":    int(math.Round(clamp(w.Recovery/100*6, 1, 6))),
+		}},
+	})
+}
+
+// Apple Health export date layout
+const appleDate = "2006-01-02 15:04:05 -0700"
+
+// appleSleepValues maps sleep stages to HKCategoryValueSleepAnalysis values
+var appleSleepValues = map[string]string{
+	scenario.SleepAwake: "HKCategoryValueSleepAnalysisAwake",
+	scenario.SleepLight: "HKCategoryValueSleepAnalysisAsleepCore",
+	scenario.SleepDeep:  "HKCategoryValueSleepAnalysisAsleepDeep",
+	scenario.SleepREM:   "HKCategoryValueSleepAnalysisAsleepREM",
+}
+
+type appleHealthData struct {
+	XMLName    xml.Name      `xml:"HealthData"`
+	Locale     string        `xml:"locale,attr"`
+	ExportDate appleValue    `xml:"ExportDate"`
+	Records    []appleRecord `xml:"Record"`
+}
+
+type appleValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type appleRecord struct {
+	Type         string `xml:"type,attr"`
+	SourceName   string `xml:"sourceName,attr"`
+	Unit         string `xml:"unit,attr,omitempty"`
+	CreationDate string `xml:"creationDate,attr"`
+	StartDate    string `xml:"startDate,attr"`
+	EndDate      string `xml:"endDate,attr"`
+	Value        string `xml:"value,attr"`
+}
+
+// appleHealthFormatter renders the records of an Apple Health export.xml; HRV
+// is reported as SDNN, which HealthKit stores, using the window's RMSSD
+type appleHealthFormatter struct{}
+
+func (appleHealthFormatter) Name() string        { return "apple_health" }
+func (appleHealthFormatter) ContentType() string { return contentTypeXML }
+
+func (appleHealthFormatter) Format(w VendorWindow) ([]byte, error) {
+	created := w.End.Format(appleDate)
+	quantity := func(kind, unit string, start, end time.Time, value float64) appleRecord {
+		return appleRecord{
+			Type:         "HKQuantityTypeIdentifier" + kind,
+			SourceName:   "Synheart Mock",
+			Unit:         unit,
+			CreationDate: created,
+			StartDate:    start.Format(appleDate),
+			EndDate:      end.Format(appleDate),
+			Value:        fmt.Sprint(value),
+		}
+	}
+
+	data := appleHealthData{
+		Locale:     "en_US",
+		ExportDate: appleValue{Value: created},
+		Records: []appleRecord{
+			quantity("HeartRate", "count/min", w.Start, w.End, round(w.HRAvg, 0)),
+			quantity("RestingHeartRate", "count/min", w.Start, w.End, round(w.RestingHR, 0)),
+			quantity("HeartRateVariabilitySDNN", "ms", w.Start, w.End, round(w.HRV, 3)),
+			quantity("StepCount", "count", w.Start, w.End, float64(w.Steps)),
+			quantity("ActiveEnergyBurned", "kcal", w.Start, w.End, round(w.ActiveKilocalories(), 3)),
+			quantity("BasalEnergyBurned", "kcal", w.Start, w.End, round(w.Kilocalories()-w.ActiveKilocalories(), 3)),
+			quantity("AppleSleepingWristTemperature", "degC", w.Start, w.End, round(w.SkinTemp, 2)),
+		},
+	}
+	data.Records = append(data.Records, quantity("RespiratoryRate", "count/min", w.Sleep.Start(), w.Sleep.End(), respirationHz*60))
+	for _, seg := range w.Sleep.Segments {
+		data.Records = append(data.Records, appleRecord{
+			Type:         "HKCategoryTypeIdentifierSleepAnalysis",
+			SourceName:   "Synheart Mock",
+			CreationDate: seg.End.Format(appleDate),
+			StartDate:    seg.Start.Format(appleDate),
+			EndDate:      seg.End.Format(appleDate),
+			Value:        appleSleepValues[seg.Stage],
+		})
+	}
+
+	// One line per payload, so records stay NDJSON friendly
+	out, err := xml.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header[:len(xml.Header)-1]), out...), nil
+}
+
+// isoDuration formats a duration as ISO 8601, e.g. PT1H2M3S
+func isoDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	out := "PT"
+	if h > 0 {
+		out += fmt.Sprintf("%dH", h)
+	}
+	if m > 0 {
+		out += fmt.Sprintf("%dM", m)
+	}
+	if s > 0 || out == "PT" {
+		out += fmt.Sprintf("%dS", s)
+	}
+	return out
+}
+
+// round rounds to the given number of decimals
+func round(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
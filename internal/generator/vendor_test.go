@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/clock"
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+var vendorEpoch = time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+func vendorEvent(at time.Duration, source, signal string, value interface{}) models.Event {
+	return models.NewEventAt(vendorEpoch.Add(at), "evt", models.Source{ID: source}, models.Session{}, models.Signal{Name: signal, Value: value, Quality: 1}, 0)
+}
+
+// walkingMinute adds a minute of heart rate rising 60 to 89 bpm and walking
+// accelerometer samples at 1hz from two sources
+func walkingMinute(a *Aggregator) {
+	for i := 0; i < 60; i++ {
+		at := time.Duration(i) * time.Second
+		if i%2 == 0 {
+			a.Add(vendorEvent(at, "watch", "ppg.hr_bpm", 60+float64(i)/2))
+		}
+		a.Add(vendorEvent(at, "watch", "accel.xyz_mps2", []float64{0, 0, 11.5}))
+		a.Add(vendorEvent(at, "phone", "accel.xyz_mps2", []float64{0, 0, 9.81}))
+	}
+	a.Add(vendorEvent(30*time.Second, "watch", "ppg.hrv_rmssd_ms", 40.0))
+	a.Add(vendorEvent(30*time.Second, "watch", "temp.skin_c", 33.5))
+}
+
+func decodePayload(t *testing.T, payload []byte) map[string][]map[string]interface{} {
+	t.Helper()
+	var decoded map[string][]map[string]interface{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("invalid payload %s: %v", payload, err)
+	}
+	return decoded
+}
+
+func TestWhoopPayloadFromWindow(t *testing.T) {
+	a := NewAggregator(clock.NewVirtual(vendorEpoch.Add(time.Minute)))
+	walkingMinute(a)
+	payload, err := a.Payload(whoopFormatter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	whoop := decodePayload(t, payload)
+
+	cycle := whoop["cycle"][0]
+	if cycle["start"] != "2025-01-01T08:00:00Z" || cycle["end"] != "2025-01-01T08:00:59Z" {
+		t.Errorf("expected the cycle to span the events, got %v to %v", cycle["start"], cycle["end"])
+	}
+	score := cycle["score"].(map[string]interface{})
+	if score["max_heart_rate"] != 89.0 || score["average_heart_rate"] != 75.0 {
+		t.Errorf("expected max 89 and average 75 bpm, got %v and %v", score["max_heart_rate"], score["average_heart_rate"])
+	}
+	if strain := score["strain"].(float64); strain <= 0 || strain >= 21 {
+		t.Errorf("expected strain within (0, 21), got %v", strain)
+	}
+
+	recovery := whoop["recovery"][0]["score"].(map[string]interface{})
+	if recovery["resting_heart_rate"] != 63.0 || recovery["hrv_rmssd_milli"] != 40.0 || recovery["skin_temp_celsius"] != 33.5 {
+		t.Errorf("expected resting HR 63, HRV 40 and skin temperature 33.5, got %v", recovery)
+	}
+
+	a.Clear()
+	payload, _ = a.Payload(whoopFormatter{})
+	whoop = decodePayload(t, payload)
+	if id := whoop["cycle"][0]["id"]; id != 2.0 {
+		t.Errorf("expected the second payload to be cycle 2, got %v", id)
+	}
+	if hrv := whoop["recovery"][0]["score"].(map[string]interface{})["hrv_rmssd_milli"]; hrv != 40.0 {
+		t.Errorf("expected HRV carried into an empty window, got %v", hrv)
+	}
+}
+
+func TestGarminStepsFromAccel(t *testing.T) {
+	a := NewAggregator(clock.NewVirtual(vendorEpoch.Add(time.Minute)))
+	walkingMinute(a)
+	payload, err := a.Payload(garminFormatter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	daily := decodePayload(t, payload)["dailies"][0]
+
+	// 59 one-second intervals at walking cadence on the watch; the phone is still
+	if steps := daily["totalSteps"].(float64); math.Abs(steps-59*walkCadence) > 1 {
+		t.Errorf("expected about %.0f steps, got %v", 59*walkCadence, steps)
+	}
+	if daily["durationInSeconds"] != 59.0 || daily["summaryId"] != "daily-1" {
+		t.Errorf("unexpected daily window: %v", daily)
+	}
+}
+
+func TestSleepFromStagedPhases(t *testing.T) {
+	clk := clock.NewVirtual(vendorEpoch)
+	a := NewAggregator(clk)
+	stages := []struct {
+		stage string
+		d     time.Duration
+	}{
+		{scenario.SleepAwake, 10 * time.Minute},
+		{scenario.SleepLight, 3 * time.Hour},
+		{scenario.SleepDeep, 2 * time.Hour},
+		{scenario.SleepAwake, 5 * time.Minute},
+		{scenario.SleepREM, time.Hour},
+		{"", 0},
+	}
+	for _, s := range stages {
+		a.ObserveSleep(s.stage, clk.Now())
+		clk.Advance(s.d)
+	}
+
+	payload, err := a.Payload(whoopFormatter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sleep := decodePayload(t, payload)["sleep"][0]
+	if sleep["id"] != 1.0 || sleep["start"] != "2025-01-01T08:00:00Z" || sleep["end"] != "2025-01-01T14:15:00Z" {
+		t.Errorf("unexpected sleep period: %v", sleep)
+	}
+	summary := sleep["score"].(map[string]interface{})["stage_summary"].(map[string]interface{})
+	want := map[string]float64{
+		"total_in_bed_time_milli":          (6*time.Hour + 15*time.Minute).Seconds() * 1000,
+		"total_awake_time_milli":           (15 * time.Minute).Seconds() * 1000,
+		"total_slow_wave_sleep_time_milli": (2 * time.Hour).Seconds() * 1000,
+		"total_rem_sleep_time_milli":       time.Hour.Seconds() * 1000,
+		"disturbance_count":                1,
+	}
+	for key, value := range want {
+		if summary[key] != value {
+			t.Errorf("expected %s %v, got %v", key, value, summary[key])
+		}
+	}
+}
+
+func TestDefaultNightBeforeData(t *testing.T) {
+	a := NewAggregator(clock.NewVirtual(vendorEpoch))
+	a.Add(vendorEvent(0, "watch", "ppg.hr_bpm", 70.0))
+	payload, _ := a.Payload(garminFormatter{})
+	sleep := decodePayload(t, payload)["sleep"][0]
+	if sleep["sleepEndTimestampGmt"] != float64(vendorEpoch.UnixMilli()) || sleep["sleepTimeSeconds"] != 27000.0 {
+		t.Errorf("expected a 7.5 hour night ending at the first event, got %v", sleep)
+	}
+}
+
+func TestLookupVendor(t *testing.T) {
+	if _, err := LookupVendor("jawbone"); err == nil || !strings.Contains(err.Error(), "apple_health|fitbit|garmin|oura|polar|whoop") {
+		t.Errorf("expected unknown vendor listing the formats, got %v", err)
+	}
+
+	a := NewAggregator(clock.NewVirtual(vendorEpoch.Add(time.Minute)))
+	walkingMinute(a)
+	for _, name := range VendorNames() {
+		formatter, err := LookupVendor(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload, err := a.Payload(formatter)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if bytes.ContainsRune(payload, '\n') {
+			t.Errorf("%s: expected a single line payload", name)
+		}
+		var decoded interface{}
+		switch formatter.ContentType() {
+		case contentTypeXML:
+			err = xml.Unmarshal(payload, new(appleHealthData))
+		default:
+			err = json.Unmarshal(payload, &decoded)
+		}
+		if err != nil {
+			t.Errorf("%s: invalid payload %s: %v", name, payload, err)
+		}
+	}
+}
+
+func TestAppleHealthSleepSegments(t *testing.T) {
+	a := NewAggregator(clock.NewVirtual(vendorEpoch))
+	a.Add(vendorEvent(0, "watch", "ppg.hr_bpm", 70.0))
+	payload, err := a.Payload(appleHealthFormatter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data appleHealthData
+	if err := xml.Unmarshal(payload, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	night := defaultNight(vendorEpoch)
+	var sleep []appleRecord
+	for _, record := range data.Records {
+		if record.Type == "HKCategoryTypeIdentifierSleepAnalysis" {
+			sleep = append(sleep, record)
+		}
+	}
+	if len(sleep) != len(night.Segments) {
+		t.Fatalf("expected a sleep record per segment, got %d of %d", len(sleep), len(night.Segments))
+	}
+	if sleep[1].Value != "HKCategoryValueSleepAnalysisAsleepCore" || sleep[1].StartDate != "2025-01-01 00:10:00 +0000" {
+		t.Errorf("unexpected light sleep record: %+v", sleep[1])
+	}
+}
@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+// Window units for vendor aggregation
+const (
+	WindowPhase = "phase" // one window per scenario phase
+	WindowDay   = "day"   // one window per calendar day (UTC) of the clock
+)
+
+// WindowConfig controls how events are grouped into vendor payloads. Windows
+// follow scenario time: By is a duration such as "1m" (aligned to the scenario
+// start), "phase" or "day". A window is flushed as a payload when the next one
+// begins.
+type WindowConfig struct {
+	By string // defaults to one minute
+	// Update also emits snapshots of the open window this often, sharing its ID,
+	// the way vendor APIs refresh a day's summary through the day
+	Update time.Duration
+	// SkipPartial drops the window still open when the run ends instead of
+	// flushing it
+	SkipPartial bool
+	// MinEvents drops windows with fewer events, e.g. after a scenario switch
+	MinEvents int
+}
+
+// every returns the window length for duration windows, or 0 for phase and day windows
+func (w WindowConfig) every() time.Duration {
+	switch w.By {
+	case "":
+		return time.Minute
+	case WindowPhase, WindowDay:
+		return 0
+	}
+	d, _ := time.ParseDuration(w.By)
+	return d
+}
+
+// key identifies the window a tick belongs to; a change of key closes the window
+func (w WindowConfig) key(engine *scenario.Engine, elapsed time.Duration, now time.Time) int64 {
+	switch w.By {
+	case WindowPhase:
+		return int64(engine.GetPhaseStart())
+	case WindowDay:
+		return now.UTC().Truncate(24 * time.Hour).Unix()
+	}
+	return int64(elapsed / w.every())
+}
+
+// Validate checks the window configuration
+func (w WindowConfig) Validate() error {
+	switch w.By {
+	case "", WindowPhase, WindowDay:
+	default:
+		if d, err := time.ParseDuration(w.By); err != nil || d <= 0 {
+			return fmt.Errorf("invalid window %q (expected a duration such as 1m, phase or day)", w.By)
+		}
+	}
+	if w.Update < 0 {
+		return fmt.Errorf("invalid window update %s: must not be negative", w.Update)
+	}
+	if w.MinEvents < 0 {
+		return fmt.Errorf("invalid window min_events %d: must not be negative", w.MinEvents)
+	}
+	return nil
+}
+
+// String describes the window for startup banners
+func (w WindowConfig) String() string {
+	by := w.By
+	switch by {
+	case "":
+		by = "1m"
+	case WindowPhase:
+		by = "per phase"
+	case WindowDay:
+		by = "per day"
+	}
+	parts := []string{by}
+	if w.Update > 0 {
+		parts = append(parts, "updates every "+w.Update.String())
+	}
+	if w.SkipPartial {
+		parts = append(parts, "no partial window")
+	}
+	if w.MinEvents > 0 {
+		parts = append(parts, fmt.Sprintf("at least %d events", w.MinEvents))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseWindowSpec parses a --window flag value such as "phase" or
+// "by=day,update=10m,partial=false,min_events=100"
+func ParseWindowSpec(spec string) (WindowConfig, error) {
+	var window WindowConfig
+	for i, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			if i > 0 {
+				return window, fmt.Errorf("invalid window field %q (expected key=value)", part)
+			}
+			key, value = "by", part
+		}
+		switch strings.TrimSpace(key) {
+		case "by":
+			window.By = value
+		case "update":
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return window, fmt.Errorf("invalid window update %q", value)
+			}
+			window.Update = d
+		case "partial":
+			partial, err := strconv.ParseBool(value)
+			if err != nil {
+				return window, fmt.Errorf("invalid window partial %q", value)
+			}
+			window.SkipPartial = !partial
+		case "min_events":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return window, fmt.Errorf("invalid window min_events %q", value)
+			}
+			window.MinEvents = n
+		default:
+			return window, fmt.Errorf("unknown window field %q", key)
+		}
+	}
+	return window, window.Validate()
+}
+
+// vendorWindows feeds an Aggregator and flushes it as payloads at window boundaries
+type vendorWindows struct {
+	agg        *Aggregator
+	config     WindowConfig
+	formatter  VendorFormatter
+	scenario   *scenario.Scenario // a switch through the control plane closes the window
+	key        int64
+	open       bool
+	lastUpdate time.Time
+}
+
+func newVendorWindows(agg *Aggregator, config WindowConfig, formatter VendorFormatter) *vendorWindows {
+	return &vendorWindows{agg: agg, config: config, formatter: formatter}
+}
+
+// advance moves to the window of the current tick, returning the payload of
+// the window it closes, if any
+func (v *vendorWindows) advance(engine *scenario.Engine, elapsed time.Duration, now time.Time) []byte {
+	key, scen := v.config.key(engine, elapsed, now), engine.GetScenario()
+	if v.open && key == v.key && scen == v.scenario {
+		return nil
+	}
+	var payload []byte
+	if v.open {
+		payload = v.flush()
+	}
+	v.key, v.scenario, v.open, v.lastUpdate = key, scen, true, now
+	return payload
+}
+
+// snapshot returns a payload for the open window when an update is due
+func (v *vendorWindows) snapshot(now time.Time) []byte {
+	if v.config.Update <= 0 || now.Sub(v.lastUpdate) < v.config.Update || v.agg.Count() == 0 {
+		return nil
+	}
+	v.lastUpdate = now
+	payload, err := v.agg.Payload(v.formatter)
+	if err != nil {
+		return nil
+	}
+	return payload
+}
+
+// finish returns the payload of the window open when the run ends, if any
+func (v *vendorWindows) finish() []byte {
+	if !v.open || v.config.SkipPartial {
+		return nil
+	}
+	v.open = false
+	return v.flush()
+}
+
+// flush renders and clears the current window; windows below MinEvents are
+// dropped without using up an ID
+func (v *vendorWindows) flush() []byte {
+	if v.agg.Count() == 0 || v.agg.Count() < v.config.MinEvents {
+		v.agg.reset()
+		return nil
+	}
+	payload, err := v.agg.Payload(v.formatter)
+	v.agg.Clear()
+	if err != nil {
+		return nil
+	}
+	return payload
+}
@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/synheart/synheart-cli/internal/clock"
+	"github.com/synheart/synheart-cli/internal/scenario"
+)
+
+var windowEpoch = time.Date(2025, 1, 1, 23, 58, 0, 0, time.UTC)
+
+func windowScenario() *scenario.Scenario {
+	return &scenario.Scenario{
+		Name:     "windows",
+		Duration: "4m30s",
+		Signals: map[string]*scenario.SignalConfig{
+			"ppg.hr_bpm": {Rate: "1hz"},
+			"eda.us":     {Rate: "1hz"},
+		},
+		Phases: []scenario.Phase{
+			{Name: "a", Duration: "90s"},
+			{Name: "b", Duration: "3m"},
+		},
+	}
+}
+
+// renderCycles renders windowScenario as Whoop payloads and returns their cycles
+func renderCycles(t *testing.T, window WindowConfig) []map[string]interface{} {
+	t.Helper()
+	clk := clock.NewVirtual(windowEpoch)
+	engine := scenario.NewEngineWithClock(windowScenario(), clk)
+	gen := NewGenerator(engine, Config{Seed: 1, SourceType: "wearable", SourceID: "test-watch", Clock: clk, Deterministic: true, Window: window})
+
+	records := make(chan []byte)
+	done := make(chan error, 1)
+	go func() {
+		done <- gen.GenerateVirtual(context.Background(), clk, 100*time.Millisecond, nil, records)
+		close(records)
+	}()
+
+	var cycles []map[string]interface{}
+	for record := range records {
+		var payload struct {
+			Cycle []map[string]interface{} `json:"cycle"`
+		}
+		if err := json.Unmarshal(record, &payload); err != nil {
+			t.Fatal(err)
+		}
+		cycles = append(cycles, payload.Cycle[0])
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("GenerateVirtual failed: %v", err)
+	}
+	return cycles
+}
+
+func cycleRanges(cycles []map[string]interface{}) []string {
+	ranges := make([]string, len(cycles))
+	for i, cycle := range cycles {
+		ranges[i] = cycle["start"].(string)[11:19] + "-" + cycle["end"].(string)[11:19]
+	}
+	return ranges
+}
+
+func TestVendorWindows(t *testing.T) {
+	tests := []struct {
+		name   string
+		window WindowConfig
+		want   []string
+	}{
+		{"minute", WindowConfig{}, []string{"23:58:00-23:58:59", "23:59:00-23:59:59", "00:00:00-00:00:59", "00:01:00-00:01:59", "00:02:00-00:02:29"}},
+		{"phase", WindowConfig{By: WindowPhase}, []string{"23:58:00-23:59:29", "23:59:30-00:02:29"}},
+		{"day", WindowConfig{By: WindowDay}, []string{"23:58:00-23:59:59", "00:00:00-00:02:29"}},
+		{"no partial", WindowConfig{By: "2m", SkipPartial: true}, []string{"23:58:00-23:59:59", "00:00:00-00:01:59"}},
+		{"min events", WindowConfig{By: "2m", MinEvents: 100}, []string{"23:58:00-23:59:59", "00:00:00-00:01:59"}},
+	}
+	for _, test := range tests {
+		cycles := renderCycles(t, test.window)
+		got := cycleRanges(cycles)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: expected windows %v, got %v", test.name, test.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%s: expected windows %v, got %v", test.name, test.want, got)
+				break
+			}
+			if id := cycles[i]["id"]; id != float64(i+1) {
+				t.Errorf("%s: expected window %d to have ID %d, got %v", test.name, i, i+1, id)
+			}
+		}
+	}
+}
+
+func TestVendorWindowUpdates(t *testing.T) {
+	cycles := renderCycles(t, WindowConfig{By: WindowPhase, Update: time.Minute})
+
+	// Phase a: a snapshot after a minute, then the final 90 seconds; phase b:
+	// snapshots at 1m and 2m, then the final window
+	want := []string{"23:58:00-23:59:00", "23:58:00-23:59:29", "23:59:30-00:00:29", "23:59:30-00:01:29", "23:59:30-00:02:29"}
+	ids := []float64{1, 1, 2, 2, 2}
+	got := cycleRanges(cycles)
+	if len(got) != len(want) {
+		t.Fatalf("expected payloads %v, got %v", want, got)
+	}
+	for i := range got {
+		if got[i] != want[i] || cycles[i]["id"] != ids[i] {
+			t.Errorf("payload %d: expected %s with ID %v, got %s with ID %v", i, want[i], ids[i], got[i], cycles[i]["id"])
+		}
+	}
+}
+
+func TestParseWindowSpec(t *testing.T) {
+	window, err := ParseWindowSpec("day,update=10m,partial=false,min_events=50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if window.By != WindowDay || window.Update != 10*time.Minute || !window.SkipPartial || window.MinEvents != 50 {
+		t.Errorf("unexpected window %+v", window)
+	}
+
+	for _, spec := range []string{"fortnight", "by=0s", "phase,update=soon", "phase,size=3", "phase,day"} {
+		if _, err := ParseWindowSpec(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
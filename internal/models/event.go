@@ -27,25 +27,41 @@ type Session struct {
 	Seed     int64  `json:"seed"`
 }
 
-// Signal represents a single sensor measurement
+// Signal represents a single sensor measurement, or a block of samples when
+// SamplePeriodMs is set
 type Signal struct {
 	Name    string      `json:"name"`  // e.g., "ppg.hr_bpm"
 	Unit    string      `json:"unit"`  // e.g., "bpm"
 	Value   interface{} `json:"value"` // Can be number, string, or array
 	Quality float64     `json:"quality"`
+	// SamplePeriodMs is the spacing of the samples in Value for a block; the
+	// event timestamp is that of the first sample
+	SamplePeriodMs float64 `json:"sample_period_ms,omitempty"`
+}
+
+// IsBlock reports whether the signal carries a block of samples
+func (s Signal) IsBlock() bool {
+	return s.SamplePeriodMs > 0
 }
 
 // Meta contains additional event metadata
 type Meta struct {
-	Sequence int64 `json:"sequence"`
+	Sequence   int64    `json:"sequence"`
+	Injections []string `json:"injections,omitempty"` // scenario events shaping this value
+	Faults     []string `json:"faults,omitempty"`     // fault types applied to this event
 }
 
 // NewEvent creates a new Event with current timestamp
 func NewEvent(eventID string, source Source, session Session, signal Signal, sequence int64) Event {
+	return NewEventAt(time.Now(), eventID, source, session, signal, sequence)
+}
+
+// NewEventAt creates a new Event with the given timestamp
+func NewEventAt(ts time.Time, eventID string, source Source, session Session, signal Signal, sequence int64) Event {
 	return Event{
 		SchemaVersion: "hsi.input.v1",
 		EventID:       eventID,
-		Timestamp:     time.Now().UTC().Format(time.RFC3339Nano),
+		Timestamp:     ts.UTC().Format(time.RFC3339Nano),
 		Source:        source,
 		Session:       session,
 		Signal:        signal,
@@ -234,13 +234,15 @@ func (x *Session) GetSeed() int64 {
 }
 
 type Signal struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Unit          string                 `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"`
-	Value         *SignalValue           `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	Quality       float64                `protobuf:"fixed64,4,opt,name=quality,proto3" json:"quality,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Unit    string                 `protobuf:"bytes,2,opt,name=unit,proto3" json:"unit,omitempty"`
+	Value   *SignalValue           `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Quality float64                `protobuf:"fixed64,4,opt,name=quality,proto3" json:"quality,omitempty"`
+	// spacing of block samples; the event ts is that of the first sample
+	SamplePeriodMs float64 `protobuf:"fixed64,5,opt,name=sample_period_ms,json=samplePeriodMs,proto3" json:"sample_period_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Signal) Reset() {
@@ -301,7 +303,14 @@ func (x *Signal) GetQuality() float64 {
 	return 0
 }
 
-// signal value can be scalar, string, or vector
+func (x *Signal) GetSamplePeriodMs() float64 {
+	if x != nil {
+		return x.SamplePeriodMs
+	}
+	return 0
+}
+
+// signal value can be scalar, string, vector, or a block of scalar or vector samples
 type SignalValue struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Types that are valid to be assigned to Kind:
@@ -309,6 +318,8 @@ type SignalValue struct {
 	//	*SignalValue_Scalar
 	//	*SignalValue_Text
 	//	*SignalValue_Vector
+	//	*SignalValue_Samples
+	//	*SignalValue_Vectors
 	Kind          isSignalValue_Kind `protobuf_oneof:"kind"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -378,6 +389,24 @@ func (x *SignalValue) GetVector() *Vector3 {
 	return nil
 }
 
+func (x *SignalValue) GetSamples() *ScalarBlock {
+	if x != nil {
+		if x, ok := x.Kind.(*SignalValue_Samples); ok {
+			return x.Samples
+		}
+	}
+	return nil
+}
+
+func (x *SignalValue) GetVectors() *VectorBlock {
+	if x != nil {
+		if x, ok := x.Kind.(*SignalValue_Vectors); ok {
+			return x.Vectors
+		}
+	}
+	return nil
+}
+
 type isSignalValue_Kind interface {
 	isSignalValue_Kind()
 }
@@ -394,12 +423,112 @@ type SignalValue_Vector struct {
 	Vector *Vector3 `protobuf:"bytes,3,opt,name=vector,proto3,oneof"`
 }
 
+type SignalValue_Samples struct {
+	Samples *ScalarBlock `protobuf:"bytes,4,opt,name=samples,proto3,oneof"`
+}
+
+type SignalValue_Vectors struct {
+	Vectors *VectorBlock `protobuf:"bytes,5,opt,name=vectors,proto3,oneof"`
+}
+
 func (*SignalValue_Scalar) isSignalValue_Kind() {}
 
 func (*SignalValue_Text) isSignalValue_Kind() {}
 
 func (*SignalValue_Vector) isSignalValue_Kind() {}
 
+func (*SignalValue_Samples) isSignalValue_Kind() {}
+
+func (*SignalValue_Vectors) isSignalValue_Kind() {}
+
+type ScalarBlock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []float64              `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScalarBlock) Reset() {
+	*x = ScalarBlock{}
+	mi := &file_proto_hsi_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScalarBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScalarBlock) ProtoMessage() {}
+
+func (x *ScalarBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_hsi_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScalarBlock.ProtoReflect.Descriptor instead.
+func (*ScalarBlock) Descriptor() ([]byte, []int) {
+	return file_proto_hsi_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ScalarBlock) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type VectorBlock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []*Vector3             `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VectorBlock) Reset() {
+	*x = VectorBlock{}
+	mi := &file_proto_hsi_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VectorBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VectorBlock) ProtoMessage() {}
+
+func (x *VectorBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_hsi_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VectorBlock.ProtoReflect.Descriptor instead.
+func (*VectorBlock) Descriptor() ([]byte, []int) {
+	return file_proto_hsi_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VectorBlock) GetValues() []*Vector3 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
 type Vector3 struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
@@ -411,7 +540,7 @@ type Vector3 struct {
 
 func (x *Vector3) Reset() {
 	*x = Vector3{}
-	mi := &file_proto_hsi_proto_msgTypes[5]
+	mi := &file_proto_hsi_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -423,7 +552,7 @@ func (x *Vector3) String() string {
 func (*Vector3) ProtoMessage() {}
 
 func (x *Vector3) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_hsi_proto_msgTypes[5]
+	mi := &file_proto_hsi_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -436,7 +565,7 @@ func (x *Vector3) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Vector3.ProtoReflect.Descriptor instead.
 func (*Vector3) Descriptor() ([]byte, []int) {
-	return file_proto_hsi_proto_rawDescGZIP(), []int{5}
+	return file_proto_hsi_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Vector3) GetX() float64 {
@@ -463,13 +592,15 @@ func (x *Vector3) GetZ() float64 {
 type Meta struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Sequence      int64                  `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Injections    []string               `protobuf:"bytes,2,rep,name=injections,proto3" json:"injections,omitempty"`
+	Faults        []string               `protobuf:"bytes,3,rep,name=faults,proto3" json:"faults,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *Meta) Reset() {
 	*x = Meta{}
-	mi := &file_proto_hsi_proto_msgTypes[6]
+	mi := &file_proto_hsi_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -481,7 +612,7 @@ func (x *Meta) String() string {
 func (*Meta) ProtoMessage() {}
 
 func (x *Meta) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_hsi_proto_msgTypes[6]
+	mi := &file_proto_hsi_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -494,7 +625,7 @@ func (x *Meta) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Meta.ProtoReflect.Descriptor instead.
 func (*Meta) Descriptor() ([]byte, []int) {
-	return file_proto_hsi_proto_rawDescGZIP(), []int{6}
+	return file_proto_hsi_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Meta) GetSequence() int64 {
@@ -504,6 +635,20 @@ func (x *Meta) GetSequence() int64 {
 	return 0
 }
 
+func (x *Meta) GetInjections() []string {
+	if x != nil {
+		return x.Injections
+	}
+	return nil
+}
+
+func (x *Meta) GetFaults() []string {
+	if x != nil {
+		return x.Faults
+	}
+	return nil
+}
+
 var File_proto_hsi_proto protoreflect.FileDescriptor
 
 const file_proto_hsi_proto_rawDesc = "" +
@@ -525,23 +670,34 @@ const file_proto_hsi_proto_rawDesc = "" +
 	"\aSession\x12\x15\n" +
 	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x1a\n" +
 	"\bscenario\x18\x02 \x01(\tR\bscenario\x12\x12\n" +
-	"\x04seed\x18\x03 \x01(\x03R\x04seed\"r\n" +
+	"\x04seed\x18\x03 \x01(\x03R\x04seed\"\x9c\x01\n" +
 	"\x06Signal\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04unit\x18\x02 \x01(\tR\x04unit\x12&\n" +
 	"\x05value\x18\x03 \x01(\v2\x10.hsi.SignalValueR\x05value\x12\x18\n" +
-	"\aquality\x18\x04 \x01(\x01R\aquality\"m\n" +
+	"\aquality\x18\x04 \x01(\x01R\aquality\x12(\n" +
+	"\x10sample_period_ms\x18\x05 \x01(\x01R\x0esamplePeriodMs\"\xc9\x01\n" +
 	"\vSignalValue\x12\x18\n" +
 	"\x06scalar\x18\x01 \x01(\x01H\x00R\x06scalar\x12\x14\n" +
 	"\x04text\x18\x02 \x01(\tH\x00R\x04text\x12&\n" +
-	"\x06vector\x18\x03 \x01(\v2\f.hsi.Vector3H\x00R\x06vectorB\x06\n" +
-	"\x04kind\"3\n" +
+	"\x06vector\x18\x03 \x01(\v2\f.hsi.Vector3H\x00R\x06vector\x12,\n" +
+	"\asamples\x18\x04 \x01(\v2\x10.hsi.ScalarBlockH\x00R\asamples\x12,\n" +
+	"\avectors\x18\x05 \x01(\v2\x10.hsi.VectorBlockH\x00R\avectorsB\x06\n" +
+	"\x04kind\"%\n" +
+	"\vScalarBlock\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\x01R\x06values\"3\n" +
+	"\vVectorBlock\x12$\n" +
+	"\x06values\x18\x01 \x03(\v2\f.hsi.Vector3R\x06values\"3\n" +
 	"\aVector3\x12\f\n" +
 	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
 	"\x01y\x18\x02 \x01(\x01R\x01y\x12\f\n" +
-	"\x01z\x18\x03 \x01(\x01R\x01z\"\"\n" +
+	"\x01z\x18\x03 \x01(\x01R\x01z\"Z\n" +
 	"\x04Meta\x12\x1a\n" +
-	"\bsequence\x18\x01 \x01(\x03R\bsequenceB5Z3github.com/synheart/synheart-cli/internal/proto/hsib\x06proto3"
+	"\bsequence\x18\x01 \x01(\x03R\bsequence\x12\x1e\n" +
+	"\n" +
+	"injections\x18\x02 \x03(\tR\n" +
+	"injections\x12\x16\n" +
+	"\x06faults\x18\x03 \x03(\tR\x06faultsB5Z3github.com/synheart/synheart-cli/internal/proto/hsib\x06proto3"
 
 var (
 	file_proto_hsi_proto_rawDescOnce sync.Once
@@ -555,28 +711,33 @@ func file_proto_hsi_proto_rawDescGZIP() []byte {
 	return file_proto_hsi_proto_rawDescData
 }
 
-var file_proto_hsi_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_hsi_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_hsi_proto_goTypes = []any{
 	(*Event)(nil),       // 0: hsi.Event
 	(*Source)(nil),      // 1: hsi.Source
 	(*Session)(nil),     // 2: hsi.Session
 	(*Signal)(nil),      // 3: hsi.Signal
 	(*SignalValue)(nil), // 4: hsi.SignalValue
-	(*Vector3)(nil),     // 5: hsi.Vector3
-	(*Meta)(nil),        // 6: hsi.Meta
+	(*ScalarBlock)(nil), // 5: hsi.ScalarBlock
+	(*VectorBlock)(nil), // 6: hsi.VectorBlock
+	(*Vector3)(nil),     // 7: hsi.Vector3
+	(*Meta)(nil),        // 8: hsi.Meta
 }
 var file_proto_hsi_proto_depIdxs = []int32{
 	1, // 0: hsi.Event.source:type_name -> hsi.Source
 	2, // 1: hsi.Event.session:type_name -> hsi.Session
 	3, // 2: hsi.Event.signal:type_name -> hsi.Signal
-	6, // 3: hsi.Event.meta:type_name -> hsi.Meta
+	8, // 3: hsi.Event.meta:type_name -> hsi.Meta
 	4, // 4: hsi.Signal.value:type_name -> hsi.SignalValue
-	5, // 5: hsi.SignalValue.vector:type_name -> hsi.Vector3
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	7, // 5: hsi.SignalValue.vector:type_name -> hsi.Vector3
+	5, // 6: hsi.SignalValue.samples:type_name -> hsi.ScalarBlock
+	6, // 7: hsi.SignalValue.vectors:type_name -> hsi.VectorBlock
+	7, // 8: hsi.VectorBlock.values:type_name -> hsi.Vector3
+	9, // [9:9] is the sub-list for method output_type
+	9, // [9:9] is the sub-list for method input_type
+	9, // [9:9] is the sub-list for extension type_name
+	9, // [9:9] is the sub-list for extension extendee
+	0, // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_hsi_proto_init() }
@@ -589,6 +750,8 @@ func file_proto_hsi_proto_init() {
 		(*SignalValue_Scalar)(nil),
 		(*SignalValue_Text)(nil),
 		(*SignalValue_Vector)(nil),
+		(*SignalValue_Samples)(nil),
+		(*SignalValue_Vectors)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -596,7 +759,7 @@ func file_proto_hsi_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_hsi_proto_rawDesc), len(file_proto_hsi_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   7,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
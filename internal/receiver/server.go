@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/synheart/synheart-cli/internal/encoding"
 	"github.com/synheart/synheart-cli/internal/models"
 )
 
@@ -163,6 +164,17 @@ func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// CBOR and MessagePack exports use the JSON field names
+	if format, _ := encoding.ParseContentType(r.Header.Get("Content-Type")); format != encoding.FormatJSON {
+		if body, err = encoding.ToJSON(body, format); err != nil {
+			s.mu.Lock()
+			s.stats.TotalErrors++
+			s.mu.Unlock()
+			s.writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s: %v", format, err))
+			return
+		}
+	}
+
 	// Parse and validate payload
 	var export models.HSIExport
 	if err := json.Unmarshal(body, &export); err != nil {
@@ -229,9 +241,9 @@ func (s *Server) validateAuth(r *http.Request) bool {
 }
 
 func (s *Server) validateHeaders(r *http.Request) error {
-	contentType := r.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "application/json") {
-		return fmt.Errorf("Content-Type must be application/json")
+	format, err := encoding.ParseContentType(r.Header.Get("Content-Type"))
+	if err != nil || format == encoding.FormatProtobuf {
+		return fmt.Errorf("Content-Type must be application/json, application/cbor or application/msgpack")
 	}
 
 	schema := r.Header.Get("X-Synheart-Schema")
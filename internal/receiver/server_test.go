@@ -6,9 +6,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestHandleImport_ValidPayload(t *testing.T) {
@@ -395,3 +398,58 @@ func TestIdempotencyStore(t *testing.T) {
 		t.Error("key2 should not exist")
 	}
 }
+
+func TestHandleImport_BinaryPayloads(t *testing.T) {
+	export := models.HSIExport{
+		Schema:       "synheart.hsi.export.v1",
+		ExportID:     "binary-test",
+		CreatedAtUTC: "2026-01-16T12:00:00Z",
+		Range: models.ExportRange{
+			FromUTC: "2026-01-15T00:00:00Z",
+			ToUTC:   "2026-01-16T00:00:00Z",
+		},
+		Device: models.ExportDevice{
+			Platform:   "ios",
+			AppVersion: "1.0.0",
+		},
+		Summaries: []models.Summary{},
+		Insights:  []models.Insight{},
+	}
+	text, _ := json.Marshal(export)
+	var doc map[string]any
+	json.Unmarshal(text, &doc)
+
+	cborBody, _ := cbor.Marshal(doc)
+	msgpackBody, _ := msgpack.Marshal(doc)
+	tests := []struct {
+		contentType string
+		body        []byte
+		status      int
+	}{
+		{"application/cbor", cborBody, http.StatusOK},
+		{"application/msgpack", msgpackBody, http.StatusOK},
+		{"application/cbor", text, http.StatusBadRequest},
+		{"application/x-protobuf", text, http.StatusBadRequest},
+		{"text/plain", text, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		server := NewServer(Config{Token: "test-token", Format: "json"}, NewStdoutWriter(&buf, "json"))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/hsi/import", bytes.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		req.Header.Set("Authorization", "Bearer test-token")
+		req.Header.Set("X-Synheart-Export-Id", "binary-test")
+
+		rr := httptest.NewRecorder()
+		server.handleImport(rr, req)
+
+		if rr.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.contentType, tt.status, rr.Code, rr.Body.String())
+			continue
+		}
+		if tt.status == http.StatusOK && !strings.Contains(buf.String(), `"export_id": "binary-test"`) {
+			t.Errorf("%s: export not written: %s", tt.contentType, buf.String())
+		}
+	}
+}
@@ -6,55 +6,63 @@ import (
 	"fmt"
 	"os"
 	"sync"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/transport"
 )
 
-// Recorder writes events to an NDJSON file
+// Recorder writes events to an NDJSON file, or a length-delimited protobuf
+// file for binary recordings
 type Recorder struct {
-	file   *os.File
-	writer *bufio.Writer
-	mu     sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	records encoding.RecordWriter
+	mu      sync.Mutex
 }
 
-// NewRecorder creates a new recorder
+// NewRecorder creates a new NDJSON recorder
 func NewRecorder(filename string) (*Recorder, error) {
+	return NewRecorderWithEncoding(filename, encoding.FormatJSON)
+}
+
+// NewRecorderWithEncoding creates a recorder framing records for the format:
+// one per line for JSON, length-delimited for protobuf
+func NewRecorderWithEncoding(filename string, format encoding.Format) (*Recorder, error) {
 	file, err := os.Create(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create recording file: %w", err)
 	}
 
+	writer := bufio.NewWriter(file)
 	return &Recorder{
-		file:   file,
-		writer: bufio.NewWriter(file),
+		file:    file,
+		writer:  writer,
+		records: encoding.NewRecordWriter(writer, format),
 	}, nil
 }
 
-// Record writes a raw byte payload to the file followed by a newline
+// Record writes an encoded record to the file
 func (r *Recorder) Record(data []byte) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, err := r.writer.Write(data); err != nil {
+	if err := r.records.Write(data); err != nil {
 		return fmt.Errorf("failed to write event: %w", err)
 	}
-
-	if _, err := r.writer.WriteString("\n"); err != nil {
-		return fmt.Errorf("failed to write newline: %w", err)
-	}
-
 	return nil
 }
 
-// RecordFromChannel reads data from a channel and records it
-func (r *Recorder) RecordFromChannel(ctx context.Context, dataStream <-chan []byte, onEntry func()) error {
+// RecordFromChannel reads records from a channel and records their data
+func (r *Recorder) RecordFromChannel(ctx context.Context, dataStream <-chan transport.Record, onEntry func()) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return r.Close()
-		case data, ok := <-dataStream:
+		case record, ok := <-dataStream:
 			if !ok {
 				return r.Close() // Channel closed
 			}
-			if err := r.Record(data); err != nil {
+			if err := r.Record(record.Data); err != nil {
 				return err
 			}
 			if onEntry != nil {
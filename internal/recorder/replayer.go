@@ -1,15 +1,21 @@
 package recorder
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/transport"
 )
 
-// Replayer reads and replays records from an NDJSON file
+// Replayer reads and replays records from an NDJSON file, or a
+// length-delimited protobuf file of raw events. Records are replayed as
+// JSON either way; events decoded from a binary recording are passed along
+// so the transports encode them for binary clients without parsing the JSON.
 type Replayer struct {
 	filename string
 	speed    float64
@@ -26,7 +32,7 @@ func NewReplayer(filename string, speed float64, loop bool) *Replayer {
 }
 
 // Replay reads records and sends them to the output channel with timing
-func (r *Replayer) Replay(ctx context.Context, output chan<- []byte) error {
+func (r *Replayer) Replay(ctx context.Context, output chan<- transport.Record) error {
 	for {
 		if err := r.replayOnce(ctx, output); err != nil {
 			return err
@@ -47,23 +53,66 @@ func (r *Replayer) Replay(ctx context.Context, output chan<- []byte) error {
 	return nil
 }
 
-func (r *Replayer) replayOnce(ctx context.Context, output chan<- []byte) error {
+// open opens the recording and detects its encoding
+func (r *Replayer) open() (*os.File, encoding.RecordReader, encoding.Format, error) {
 	file, err := os.Open(r.filename)
 	if err != nil {
-		return fmt.Errorf("failed to open recording file: %w", err)
+		return nil, nil, "", fmt.Errorf("failed to open recording file: %w", err)
+	}
+	format, stream, err := encoding.DetectStreamFormat(file)
+	if err != nil {
+		file.Close()
+		return nil, nil, "", fmt.Errorf("error reading file: %w", err)
+	}
+	return file, encoding.NewRecordReader(stream, format), format, nil
+}
+
+// Format returns the encoding of the recording
+func (r *Replayer) Format() (encoding.Format, error) {
+	file, _, format, err := r.open()
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+	return format, nil
+}
+
+// next reads the next record as JSON, decoding binary events
+func next(records encoding.RecordReader, format encoding.Format) (transport.Record, error) {
+	data, err := records.Next()
+	if err != nil || !format.Binary() {
+		return transport.Record{Data: data}, err
+	}
+	event, err := encoding.NewDecoder(format).Decode(data)
+	if err != nil {
+		return transport.Record{}, fmt.Errorf("failed to decode event: %w", err)
+	}
+	data, err = json.Marshal(event)
+	return transport.Record{Data: data, Event: &event}, err
+}
+
+func (r *Replayer) replayOnce(ctx context.Context, output chan<- transport.Record) error {
+	file, records, format, err := r.open()
+	if err != nil {
+		return err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
 	var lastTimestamp time.Time
 	lineNum := 0
 
-	for scanner.Scan() {
+	for {
+		record, err := next(records, format)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading file: %w", err)
+		}
 		lineNum++
-		data := scanner.Bytes()
 
 		// Attempt to extract timestamp for timing
-		timestamp := r.extractTimestamp(data)
+		timestamp := r.extractTimestamp(record.Data)
 		if timestamp.IsZero() {
 			// Fallback: 100ms between records if no timestamp found
 			if lineNum > 1 {
@@ -93,14 +142,10 @@ func (r *Replayer) replayOnce(ctx context.Context, output chan<- []byte) error {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case output <- append([]byte(nil), data...):
+		case output <- record:
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file: %w", err)
-	}
-
 	return nil
 }
 
@@ -132,40 +177,43 @@ func (r *Replayer) extractTimestamp(data []byte) time.Time {
 
 // CountEvents returns the number of records in the recording
 func (r *Replayer) CountEvents() (int, error) {
-	file, err := os.Open(r.filename)
+	file, records, _, err := r.open()
 	if err != nil {
-		return 0, fmt.Errorf("failed to open recording file: %w", err)
+		return 0, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
 	count := 0
-	for scanner.Scan() {
+	for {
+		_, err := records.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("error reading file: %w", err)
+		}
 		count++
 	}
-
-	if err := scanner.Err(); err != nil {
-		return 0, fmt.Errorf("error reading file: %w", err)
-	}
-
-	return count, nil
 }
 
 // GetFirstRecordInfo returns the first record as a map for info display
 func (r *Replayer) GetFirstRecordInfo() (map[string]interface{}, error) {
-	file, err := os.Open(r.filename)
+	file, records, format, err := r.open()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open recording file: %w", err)
+		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	if !scanner.Scan() {
+	record, err := next(records, format)
+	if err == io.EOF {
 		return nil, fmt.Errorf("recording file is empty")
 	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
 
 	var m map[string]interface{}
-	if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+	if err := json.Unmarshal(record.Data, &m); err != nil {
 		return nil, fmt.Errorf("failed to parse first record: %w", err)
 	}
 
@@ -0,0 +1,73 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/models"
+	"github.com/synheart/synheart-cli/internal/transport"
+)
+
+func TestReplayBinaryRecording(t *testing.T) {
+	side := "left"
+	events := []models.Event{
+		{SchemaVersion: "hsi.input.v1", EventID: "1", Timestamp: "2025-01-01T00:00:00Z",
+			Source: models.Source{Type: "wearable", ID: "watch", Side: &side},
+			Signal: models.Signal{Name: "ppg.hr_bpm", Unit: "bpm", Value: 72.0, Quality: 0.9}},
+		{SchemaVersion: "hsi.input.v1", EventID: "2", Timestamp: "2025-01-01T00:00:00.01Z",
+			Source: models.Source{Type: "wearable", ID: "watch", Side: &side},
+			Signal: models.Signal{Name: "accel.xyz_mps2", Unit: "m/s2", Value: [][]float64{{0, 0, 9.8}, {0, 0.1, 9.7}}, Quality: 0.9, SamplePeriodMs: 5}},
+	}
+
+	for _, format := range encoding.Formats {
+		path := filepath.Join(t.TempDir(), "recording")
+		rec, err := NewRecorderWithEncoding(path, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc := encoding.NewEncoder(format)
+		for _, event := range events {
+			data, err := enc.Encode(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := rec.Record(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := rec.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		rep := NewReplayer(path, 1000, false)
+		if detected, err := rep.Format(); err != nil || detected != format {
+			t.Errorf("%s: detected %q (%v)", format, detected, err)
+		}
+		if count, err := rep.CountEvents(); err != nil || count != len(events) {
+			t.Errorf("%s: counted %d events (%v)", format, count, err)
+		}
+
+		out := make(chan transport.Record, len(events))
+		if err := rep.Replay(context.Background(), out); err != nil {
+			t.Fatal(err)
+		}
+		close(out)
+		i := 0
+		for record := range out {
+			want, _ := json.Marshal(events[i])
+			if string(record.Data) != string(want) {
+				t.Errorf("%s: record %d\n got %s\nwant %s", format, i, record.Data, want)
+			}
+			if (record.Event != nil) != format.Binary() {
+				t.Errorf("%s: record %d: expected the decoded event only for binary recordings", format, i)
+			}
+			i++
+		}
+		if i != len(events) {
+			t.Errorf("%s: replayed %d of %d records", format, i, len(events))
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolve returns a flattened copy of the named scenario: signals and defaults
+// inherited through extends, phase templates imported through includes, and
+// phases expanded from templates and repeats. stack holds the scenarios being
+// resolved and is used to detect cycles.
+func (r *Registry) resolve(name string, stack []string) (*Scenario, error) {
+	for _, seen := range stack {
+		if seen == name {
+			return nil, fmt.Errorf("scenario cycle: %s", strings.Join(append(stack, name), " -> "))
+		}
+	}
+
+	if failure := r.broken[name]; failure != nil {
+		if len(stack) > 0 {
+			return nil, fmt.Errorf("scenario '%s' references scenario '%s', which failed: %w", stack[len(stack)-1], name, failure)
+		}
+		return nil, failure
+	}
+	raw, ok := r.scenarios[name]
+	if !ok {
+		if len(stack) > 0 {
+			return nil, fmt.Errorf("scenario '%s' references unknown scenario '%s'", stack[len(stack)-1], name)
+		}
+		return nil, fmt.Errorf("scenario '%s' not found", name)
+	}
+	stack = append(stack, name)
+
+	flat := &Scenario{
+		Signals:   make(map[string]*SignalConfig),
+		Templates: make(map[string][]Phase),
+	}
+	if raw.Extends != "" {
+		parent, err := r.resolve(raw.Extends, stack)
+		if err != nil {
+			return nil, err
+		}
+		flat = parent
+	}
+
+	for _, include := range raw.Includes {
+		lib, err := r.resolve(include, stack)
+		if err != nil {
+			return nil, err
+		}
+		for templateName, phases := range lib.Templates {
+			flat.Templates[templateName] = copyPhases(phases)
+		}
+	}
+
+	flat.Name = raw.Name
+	flat.Extends = raw.Extends
+	flat.Includes = raw.Includes
+	if raw.Description != "" {
+		flat.Description = raw.Description
+	}
+	if raw.Duration != "" {
+		flat.Duration = raw.Duration
+	}
+	if raw.DefaultRate != "" {
+		flat.DefaultRate = raw.DefaultRate
+	}
+	for signalName, config := range raw.Signals {
+		if base, ok := flat.Signals[signalName]; ok {
+			flat.Signals[signalName] = mergeSignalConfig(base, config)
+		} else {
+			flat.Signals[signalName] = copySignalConfig(config)
+		}
+	}
+	for templateName, phases := range raw.Templates {
+		flat.Templates[templateName] = copyPhases(phases)
+	}
+	if len(raw.Sources) > 0 {
+		flat.Sources = append([]SourceConfig(nil), raw.Sources...)
+	}
+
+	for _, event := range raw.Events {
+		flat.Events = replaceEvent(flat.Events, event)
+	}
+	if raw.Correlations != nil || raw.IncludeDefaultCorrelations {
+		flat.Correlations = append([]CorrelationRule{}, raw.Correlations...)
+		flat.IncludeDefaultCorrelations = raw.IncludeDefaultCorrelations
+	}
+	if len(raw.Faults) > 0 {
+		flat.Faults = append([]FaultConfig(nil), raw.Faults...)
+	}
+
+	if len(raw.Phases) > 0 {
+		phases, err := expandPhases(raw.Phases, flat.Templates)
+		if err != nil {
+			return nil, fmt.Errorf("scenario '%s': %w", name, err)
+		}
+		flat.Phases = phases
+	}
+
+	for _, phase := range flat.Phases {
+		for signalName := range phase.Overrides {
+			if _, ok := flat.Signals[signalName]; !ok {
+				return nil, fmt.Errorf("scenario '%s': phase %q overrides signal %q which is not declared in signals", name, phase.Name, signalName)
+			}
+		}
+	}
+
+	for _, event := range flat.Events {
+		for signalName := range event.Effects {
+			if _, ok := flat.Signals[signalName]; !ok {
+				return nil, fmt.Errorf("scenario '%s': event %q affects signal %q which is not declared in signals", name, event.Name, signalName)
+			}
+		}
+		for _, phaseName := range event.Phases {
+			if !hasPhase(flat.Phases, phaseName) {
+				return nil, fmt.Errorf("scenario '%s': event %q references unknown phase %q", name, event.Name, phaseName)
+			}
+		}
+	}
+
+	for _, rule := range flat.Correlations {
+		for _, signalName := range []string{rule.Source, rule.Target} {
+			if _, ok := flat.Signals[signalName]; !ok {
+				return nil, fmt.Errorf("scenario '%s': correlation %q references signal %q which is not declared in signals", name, rule.Label(), signalName)
+			}
+		}
+	}
+
+	return flat, nil
+}
+
+// replaceEvent returns events with event added, replacing an inherited event of the same name
+func replaceEvent(events []EventConfig, event EventConfig) []EventConfig {
+	for i, existing := range events {
+		if existing.Name == event.Name {
+			replaced := append([]EventConfig(nil), events...)
+			replaced[i] = event
+			return replaced
+		}
+	}
+	return append(events, event)
+}
+
+func hasPhase(phases []Phase, name string) bool {
+	for _, phase := range phases {
+		if phaseMatches(name, phase.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandPhases replaces template references with the template's phases and
+// unrolls repeats. Repeated phases are numbered: sprint-1, sprint-2, ...
+func expandPhases(phases []Phase, templates map[string][]Phase) ([]Phase, error) {
+	expanded := make([]Phase, 0, len(phases))
+
+	for _, phase := range phases {
+		if phase.Template == "" {
+			expanded = append(expanded, repeatPhases([]Phase{phase}, phase.Repeat)...)
+			continue
+		}
+
+		template, ok := templates[phase.Template]
+		if !ok {
+			return nil, fmt.Errorf("unknown template %q", phase.Template)
+		}
+		if phase.Duration != "" && len(template) > 1 {
+			return nil, fmt.Errorf("template %q: duration can only be set when the template has a single phase", phase.Template)
+		}
+
+		steps := make([]Phase, 0, len(template))
+		for _, step := range template {
+			if step.Template != "" {
+				return nil, fmt.Errorf("template %q: templates cannot reference other templates", phase.Template)
+			}
+			step = copyPhase(step)
+			switch {
+			case len(template) == 1 && phase.Name != "":
+				step.Name = phase.Name
+			case phase.Name != "":
+				step.Name = phase.Name + "-" + step.Name
+			}
+			if phase.Duration != "" {
+				step.Duration = phase.Duration
+			}
+			if len(phase.Faults) > 0 {
+				step.Faults = append(append([]FaultConfig(nil), step.Faults...), phase.Faults...)
+			}
+			for signalName, override := range phase.Overrides {
+				if step.Overrides == nil {
+					step.Overrides = make(map[string]*SignalConfig)
+				}
+				if base, ok := step.Overrides[signalName]; ok {
+					step.Overrides[signalName] = mergeSignalConfig(base, override)
+				} else {
+					step.Overrides[signalName] = copySignalConfig(override)
+				}
+			}
+			steps = append(steps, repeatPhases([]Phase{step}, step.Repeat)...)
+		}
+		expanded = append(expanded, repeatPhases(steps, phase.Repeat)...)
+	}
+
+	return expanded, nil
+}
+
+// repeatPhases returns copies of phases repeated n times, numbering the names
+// when n is greater than one
+func repeatPhases(phases []Phase, n int) []Phase {
+	if n < 1 {
+		n = 1
+	}
+
+	repeated := make([]Phase, 0, len(phases)*n)
+	for i := 1; i <= n; i++ {
+		for _, phase := range phases {
+			phase = copyPhase(phase)
+			phase.Template = ""
+			phase.Repeat = 0
+			if n > 1 {
+				phase.Name = fmt.Sprintf("%s-%d", phase.Name, i)
+			}
+			repeated = append(repeated, phase)
+		}
+	}
+	return repeated
+}
+
+// mergeSignalConfig layers the fields set in override over base
+func mergeSignalConfig(base, override *SignalConfig) *SignalConfig {
+	if override == nil {
+		return copySignalConfig(base)
+	}
+	merged := mergeOverride(base, override)
+	if override.Rate != "" {
+		merged.Rate = override.Rate
+	}
+	if override.Unit != "" {
+		merged.Unit = override.Unit
+	}
+	if override.Block != 0 {
+		merged.Block = override.Block
+	}
+	return merged
+}
+
+func copySignalConfig(config *SignalConfig) *SignalConfig {
+	if config == nil {
+		return &SignalConfig{}
+	}
+	c := *config
+	return &c
+}
+
+func copyPhase(phase Phase) Phase {
+	if phase.Overrides == nil {
+		return phase
+	}
+	overrides := make(map[string]*SignalConfig, len(phase.Overrides))
+	for signalName, override := range phase.Overrides {
+		overrides[signalName] = copySignalConfig(override)
+	}
+	phase.Overrides = overrides
+	return phase
+}
+
+func copyPhases(phases []Phase) []Phase {
+	copied := make([]Phase, len(phases))
+	for i, phase := range phases {
+		copied[i] = copyPhase(phase)
+	}
+	return copied
+}
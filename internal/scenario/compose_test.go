@@ -0,0 +1,257 @@
+package scenario
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveExtends(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(&Scenario{
+		Name:        "parent",
+		Description: "parent scenario",
+		Duration:    "unlimited",
+		DefaultRate: "50hz",
+		Signals: map[string]*SignalConfig{
+			"ppg.hr_bpm": {Baseline: 72, Noise: 3, Rate: "1hz", Unit: "bpm"},
+			"eda.us":     {Baseline: 2.0, Rate: "0.5hz"},
+		},
+		Phases: []Phase{{Name: "idle", Duration: "unlimited"}},
+	})
+	registry.Add(&Scenario{
+		Name:     "child",
+		Extends:  "parent",
+		Duration: "10m",
+		Signals: map[string]*SignalConfig{
+			"ppg.hr_bpm": {Baseline: 60},
+		},
+		Phases: []Phase{
+			{Name: "rest", Duration: "10m", Overrides: map[string]*SignalConfig{"eda.us": {Add: 1}}},
+		},
+	})
+
+	child, err := registry.Get("child")
+	if err != nil {
+		t.Fatalf("Get(child): %v", err)
+	}
+
+	if child.Description != "parent scenario" || child.DefaultRate != "50hz" {
+		t.Errorf("expected inherited defaults, got description=%q default_rate=%q", child.Description, child.DefaultRate)
+	}
+	if child.Duration != "10m" {
+		t.Errorf("expected own duration 10m, got %s", child.Duration)
+	}
+	hr := child.Signals["ppg.hr_bpm"]
+	if hr.Baseline != 60 || hr.Noise != 3 || hr.Rate != "1hz" || hr.Unit != "bpm" {
+		t.Errorf("expected merged hr config, got %+v", hr)
+	}
+	if _, ok := child.Signals["eda.us"]; !ok {
+		t.Error("expected inherited eda.us signal")
+	}
+	if len(child.Phases) != 1 || child.Phases[0].Name != "rest" {
+		t.Errorf("expected own phases to replace the parent's, got %+v", child.Phases)
+	}
+
+	// Resolving must not modify the parent
+	parent, err := registry.Get("parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent.Signals["ppg.hr_bpm"].Baseline != 72 {
+		t.Errorf("parent baseline modified: %v", parent.Signals["ppg.hr_bpm"].Baseline)
+	}
+}
+
+func TestResolveTemplates(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(&Scenario{
+		Name: "library",
+		Templates: map[string][]Phase{
+			"interval": {
+				{Name: "sprint", Duration: "30s", Overrides: map[string]*SignalConfig{"ppg.hr_bpm": {Add: 60}}},
+				{Name: "recover", Duration: "90s"},
+			},
+		},
+	})
+	registry.Add(&Scenario{
+		Name:     "intervals",
+		Includes: []string{"library"},
+		Duration: "12m",
+		Signals:  map[string]*SignalConfig{"ppg.hr_bpm": {Baseline: 70}},
+		Templates: map[string][]Phase{
+			"easy": {{Name: "easy", Duration: "1m"}},
+		},
+		Phases: []Phase{
+			{Template: "easy", Name: "warmup", Duration: "2m"},
+			{Template: "interval", Repeat: 3, Overrides: map[string]*SignalConfig{"ppg.hr_bpm": {Multiply: 1.1}}},
+			{Name: "cooldown", Duration: "2m"},
+			{Name: "stretch", Duration: "30s", Repeat: 2},
+		},
+	})
+
+	scen, err := registry.Get("intervals")
+	if err != nil {
+		t.Fatalf("Get(intervals): %v", err)
+	}
+
+	expected := []string{
+		"warmup",
+		"sprint-1", "recover-1",
+		"sprint-2", "recover-2",
+		"sprint-3", "recover-3",
+		"cooldown",
+		"stretch-1", "stretch-2",
+	}
+	if len(scen.Phases) != len(expected) {
+		t.Fatalf("expected %d phases, got %d: %+v", len(expected), len(scen.Phases), scen.Phases)
+	}
+	for i, name := range expected {
+		if scen.Phases[i].Name != name {
+			t.Errorf("phase %d: expected %s, got %s", i, name, scen.Phases[i].Name)
+		}
+		if scen.Phases[i].Template != "" || scen.Phases[i].Repeat != 0 {
+			t.Errorf("phase %s: expected template and repeat to be cleared", scen.Phases[i].Name)
+		}
+	}
+
+	if scen.Phases[0].Duration != "2m" {
+		t.Errorf("expected warmup duration 2m, got %s", scen.Phases[0].Duration)
+	}
+	sprint := scen.Phases[1].Overrides["ppg.hr_bpm"]
+	if sprint.Add != 60 || sprint.Multiply != 1.1 {
+		t.Errorf("expected reference overrides merged over template, got %+v", sprint)
+	}
+	recover := scen.Phases[2].Overrides["ppg.hr_bpm"]
+	if recover == nil || recover.Multiply != 1.1 {
+		t.Errorf("expected reference overrides applied to every template phase, got %+v", recover)
+	}
+
+	// Repeated phases must not share override configs
+	scen.Phases[1].Overrides["ppg.hr_bpm"].Add = 0
+	if scen.Phases[3].Overrides["ppg.hr_bpm"].Add != 60 {
+		t.Error("repeated phases share override configs")
+	}
+
+	if offset, err := scen.GetPhaseOffset("sprint-2"); err != nil || offset.Minutes() != 4 {
+		t.Errorf("expected sprint-2 at 4m, got %v (%v)", offset, err)
+	}
+}
+
+func TestResolveCorrelations(t *testing.T) {
+	registry := NewRegistry()
+	registry.Add(&Scenario{
+		Name:                       "parent",
+		Signals:                    map[string]*SignalConfig{"accel.xyz_mps2": {}, "eda.us": {}, "ppg.hr_bpm": {}},
+		IncludeDefaultCorrelations: true,
+		Correlations: []CorrelationRule{
+			{Name: "hr_exertion", Source: "accel.xyz_mps2", Target: "ppg.hr_bpm", Transform: TransformLinear, Coefficient: 1},
+			{Name: "hr_follows_eda", Source: "eda.us", Target: "ppg.hr_bpm", Transform: TransformLinear, Coefficient: 3},
+		},
+	})
+	registry.Add(&Scenario{Name: "child", Extends: "parent"})
+	registry.Add(&Scenario{Name: "own", Extends: "parent", Correlations: []CorrelationRule{
+		{Name: "only", Source: "eda.us", Target: "ppg.hr_bpm", Transform: TransformLinear},
+	}})
+
+	for _, name := range []string{"parent", "child"} {
+		scen, err := registry.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rules := scen.CorrelationRules()
+		var labels []string
+		for _, rule := range rules {
+			labels = append(labels, rule.Label())
+		}
+		if strings.Join(labels, ",") != "hr_exertion,hrv_stress,hr_follows_eda" {
+			t.Fatalf("%s: expected the defaults then the added rule, got %v", name, labels)
+		}
+		if rules[0].Transform != TransformLinear {
+			t.Errorf("%s: expected the declared hr_exertion to replace the default, got %+v", name, rules[0])
+		}
+	}
+
+	// Declaring rules without the flag replaces the inherited ones and the defaults
+	own, err := registry.Get("own")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := own.CorrelationRules(); len(rules) != 1 || rules[0].Name != "only" {
+		t.Errorf("expected only the declared rule, got %+v", rules)
+	}
+	if len(DefaultCorrelations()) != 2 || DefaultCorrelations()[0].Transform != TransformThreshold {
+		t.Error("merging must not modify the defaults")
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		scenarios []*Scenario
+		get       string
+		contains  string
+	}{
+		{
+			name: "cycle",
+			scenarios: []*Scenario{
+				{Name: "a", Extends: "b"},
+				{Name: "b", Extends: "c"},
+				{Name: "c", Extends: "a"},
+			},
+			get:      "a",
+			contains: "scenario cycle: a -> b -> c -> a",
+		},
+		{
+			name:      "self include",
+			scenarios: []*Scenario{{Name: "a", Includes: []string{"a"}}},
+			get:       "a",
+			contains:  "scenario cycle: a -> a",
+		},
+		{
+			name:      "unknown parent",
+			scenarios: []*Scenario{{Name: "a", Extends: "missing"}},
+			get:       "a",
+			contains:  "references unknown scenario 'missing'",
+		},
+		{
+			name:      "unknown template",
+			scenarios: []*Scenario{{Name: "a", Phases: []Phase{{Template: "missing"}}}},
+			get:       "a",
+			contains:  `unknown template "missing"`,
+		},
+		{
+			name: "phase sharing a prefix",
+			scenarios: []*Scenario{{
+				Name:   "a",
+				Phases: []Phase{{Name: "focus-break", Duration: "1m"}},
+				Events: []EventConfig{{Name: "ping", At: []string{"0s"}, Phases: []string{"focus"}}},
+			}},
+			get:      "a",
+			contains: `references unknown phase "focus"`,
+		},
+		{
+			name: "undeclared override",
+			scenarios: []*Scenario{
+				{Name: "parent", Signals: map[string]*SignalConfig{"ppg.hr_bpm": {}}},
+				{Name: "a", Extends: "parent", Phases: []Phase{{Name: "p", Overrides: map[string]*SignalConfig{"eda.us": {Add: 1}}}}},
+			},
+			get:      "a",
+			contains: `overrides signal "eda.us"`,
+		},
+	}
+
+	for _, test := range tests {
+		registry := NewRegistry()
+		for _, scen := range test.scenarios {
+			registry.Add(scen)
+		}
+		_, err := registry.Get(test.get)
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.contains) {
+			t.Errorf("%s: expected error containing %q, got %v", test.name, test.contains, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Correlation transforms
+const (
+	TransformLinear    = "linear"    // target changes by coefficient * (source - offset)
+	TransformThreshold = "threshold" // as linear, from threshold and only while the source exceeds it
+	TransformLag       = "lag"       // as linear, using the source value from delay ago
+)
+
+// Correlation effects
+const (
+	EffectAdd      = "add"      // the change is added to the target
+	EffectMultiply = "multiply" // the target is scaled by 1 + change
+)
+
+// DiscreteSignals take string values; as correlation sources they are mapped to
+// numbers through Levels, and they can't be correlation targets
+var DiscreteSignals = []string{"app.activity", "motion.activity", "screen.state"}
+
+// CorrelationRule declares how one signal drives another. Vector sources use
+// their magnitude, discrete sources the number Levels assigns to their state. The
+// change is clamped to EffectMin/EffectMax and the result to Min/Max.
+type CorrelationRule struct {
+	Name        string             `yaml:"name,omitempty" json:"name,omitempty"`
+	Source      string             `yaml:"source" json:"source"`
+	Target      string             `yaml:"target" json:"target"`
+	Transform   string             `yaml:"transform" json:"transform"`
+	Coefficient float64            `yaml:"coefficient" json:"coefficient"`
+	Offset      float64            `yaml:"offset,omitempty" json:"offset,omitempty"`       // linear and lag: source value with no effect
+	Threshold   float64            `yaml:"threshold,omitempty" json:"threshold,omitempty"` // threshold: source value the effect starts from
+	Delay       string             `yaml:"delay,omitempty" json:"delay,omitempty"`         // lag: how far behind the source the target follows
+	Levels      map[string]float64 `yaml:"levels,omitempty" json:"levels,omitempty"`       // numeric value of each discrete source state, others are 0
+	Effect      string             `yaml:"effect,omitempty" json:"effect,omitempty"`       // add (default) or multiply
+	EffectMin   *float64           `yaml:"effect_min,omitempty" json:"effect_min,omitempty"`
+	EffectMax   *float64           `yaml:"effect_max,omitempty" json:"effect_max,omitempty"`
+	Min         *float64           `yaml:"min,omitempty" json:"min,omitempty"`
+	Max         *float64           `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// DefaultCorrelations returns the rules applied to scenarios that don't declare
+// any: exertion raises heart rate, and stress (elevated EDA) lowers HRV.
+func DefaultCorrelations() []CorrelationRule {
+	return []CorrelationRule{
+		{
+			Name:        "hr_exertion",
+			Source:      "accel.xyz_mps2",
+			Target:      "ppg.hr_bpm",
+			Transform:   TransformThreshold,
+			Threshold:   11,
+			Coefficient: 2,
+			Min:         floatPtr(40),
+			Max:         floatPtr(200),
+		},
+		{
+			Name:        "hrv_stress",
+			Source:      "eda.us",
+			Target:      "ppg.hrv_rmssd_ms",
+			Transform:   TransformThreshold,
+			Threshold:   4,
+			Coefficient: -0.05,
+			Effect:      EffectMultiply,
+			EffectMin:   floatPtr(-0.4),
+			Min:         floatPtr(10),
+			Max:         floatPtr(150),
+		},
+	}
+}
+
+// CorrelationRules returns the scenario's rules, or the defaults if it declares
+// none. An explicit empty list disables correlations. With
+// IncludeDefaultCorrelations the declared rules are merged into the defaults:
+// a rule named like a default replaces it, others are added after them.
+func (s *Scenario) CorrelationRules() []CorrelationRule {
+	if s.Correlations == nil {
+		return DefaultCorrelations()
+	}
+	if s.IncludeDefaultCorrelations {
+		rules := DefaultCorrelations()
+		for _, rule := range s.Correlations {
+			rules = replaceCorrelation(rules, rule)
+		}
+		return rules
+	}
+	return s.Correlations
+}
+
+// replaceCorrelation replaces the rule with the same name, or appends the rule
+// if it is unnamed or new
+func replaceCorrelation(rules []CorrelationRule, rule CorrelationRule) []CorrelationRule {
+	if rule.Name != "" {
+		for i, existing := range rules {
+			if existing.Name == rule.Name {
+				rules[i] = rule
+				return rules
+			}
+		}
+	}
+	return append(rules, rule)
+}
+
+// DelayDuration returns the lag delay, 0 if unset or invalid
+func (r CorrelationRule) DelayDuration() time.Duration {
+	d, err := time.ParseDuration(r.Delay)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Label returns the rule's name, or a description of it if unnamed
+func (r CorrelationRule) Label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Source + " -> " + r.Target
+}
+
+// Validate checks the rule's transform, effect, delay and clamps. Signal names
+// are checked by the caller, which knows the available signals.
+func (r CorrelationRule) Validate() error {
+	var problems []string
+	if r.Source == "" || r.Target == "" {
+		problems = append(problems, "source and target are required")
+	}
+	if containsString(DiscreteSignals, r.Target) {
+		problems = append(problems, fmt.Sprintf("target %s is discrete and can't be correlated", r.Target))
+	}
+	switch r.Transform {
+	case TransformLinear, TransformThreshold:
+		if r.Delay != "" {
+			problems = append(problems, "delay is only used by the lag transform")
+		}
+	case TransformLag:
+		if d, err := time.ParseDuration(r.Delay); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid delay %q (expected a positive duration like 10s)", r.Delay))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("unknown transform %q (expected linear, threshold or lag)", r.Transform))
+	}
+	if r.Effect != "" && r.Effect != EffectAdd && r.Effect != EffectMultiply {
+		problems = append(problems, fmt.Sprintf("unknown effect %q (expected add or multiply)", r.Effect))
+	}
+	if r.EffectMin != nil && r.EffectMax != nil && *r.EffectMin > *r.EffectMax {
+		problems = append(problems, "effect_min is greater than effect_max")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		problems = append(problems, "min is greater than max")
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
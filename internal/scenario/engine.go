@@ -3,53 +3,93 @@ package scenario
 import (
 	"sync"
 	"time"
+
+	"github.com/synheart/synheart-cli/internal/clock"
 )
 
 // Engine executes a scenario and tracks progression through phases
 type Engine struct {
 	scenario  *Scenario
+	clock     clock.Clock
 	startTime time.Time
+	offset    time.Duration // elapsed time accumulated before startTime
+	paused    bool
 	mu        sync.RWMutex
 }
 
-// NewEngine creates a new scenario engine
+// NewEngine creates a new scenario engine driven by the wall clock
 func NewEngine(scenario *Scenario) *Engine {
+	return NewEngineWithClock(scenario, clock.Real())
+}
+
+// NewEngineWithClock creates a new scenario engine driven by the given clock
+func NewEngineWithClock(scenario *Scenario, clk clock.Clock) *Engine {
 	return &Engine{
 		scenario:  scenario,
-		startTime: time.Now(),
+		clock:     clk,
+		startTime: clk.Now(),
 	}
 }
 
-// GetElapsed returns the time elapsed since scenario start
+// GetElapsed returns the time elapsed since scenario start, excluding paused time
 func (e *Engine) GetElapsed() time.Duration {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return time.Since(e.startTime)
+	return e.elapsedLocked()
+}
+
+func (e *Engine) elapsedLocked() time.Duration {
+	if e.paused {
+		return e.offset
+	}
+	return e.offset + e.clock.Now().Sub(e.startTime)
+}
+
+// snapshot returns the current scenario and elapsed time under a single lock
+func (e *Engine) snapshot() (*Scenario, time.Duration) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.scenario, e.elapsedLocked()
 }
 
 // GetCurrentPhase returns the current phase based on elapsed time
 func (e *Engine) GetCurrentPhase() *Phase {
-	elapsed := e.GetElapsed()
-	return e.scenario.getCurrentPhase(elapsed)
+	scen, elapsed := e.snapshot()
+	return scen.getCurrentPhase(elapsed)
+}
+
+// GetPhaseStart returns the scenario offset at which the current phase began
+func (e *Engine) GetPhaseStart() time.Duration {
+	scen, elapsed := e.snapshot()
+	return scen.GetPhaseStart(elapsed)
+}
+
+// GetPhaseElapsed returns the time elapsed since the current phase began
+func (e *Engine) GetPhaseElapsed() time.Duration {
+	scen, elapsed := e.snapshot()
+	return elapsed - scen.GetPhaseStart(elapsed)
 }
 
 // GetSignalConfig returns the effective signal configuration at current time
 func (e *Engine) GetSignalConfig(signalName string) *SignalConfig {
-	elapsed := e.GetElapsed()
-	return e.scenario.GetEffectiveConfig(signalName, elapsed)
+	scen, elapsed := e.snapshot()
+	return scen.GetEffectiveConfig(signalName, elapsed)
 }
 
 // IsComplete returns true if the scenario has finished
 func (e *Engine) IsComplete() bool {
-	duration, unlimited := ParseDuration(e.scenario.Duration)
+	scen, elapsed := e.snapshot()
+	duration, unlimited := ParseDuration(scen.Duration)
 	if unlimited {
 		return false
 	}
-	return e.GetElapsed() >= duration
+	return elapsed >= duration
 }
 
 // GetScenario returns the underlying scenario
 func (e *Engine) GetScenario() *Scenario {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	return e.scenario
 }
 
@@ -57,5 +97,57 @@ func (e *Engine) GetScenario() *Scenario {
 func (e *Engine) Reset() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	e.startTime = time.Now()
+	e.offset = 0
+	e.startTime = e.clock.Now()
+}
+
+// Pause freezes the scenario clock
+func (e *Engine) Pause() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.paused {
+		return
+	}
+	e.offset = e.elapsedLocked()
+	e.paused = true
+}
+
+// Resume restarts the scenario clock from where it was paused
+func (e *Engine) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.paused {
+		return
+	}
+	e.startTime = e.clock.Now()
+	e.paused = false
+}
+
+// IsPaused returns true if the scenario clock is paused
+func (e *Engine) IsPaused() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.paused
+}
+
+// SetScenario swaps the running scenario and restarts it from the beginning
+func (e *Engine) SetScenario(scenario *Scenario) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scenario = scenario
+	e.offset = 0
+	e.startTime = e.clock.Now()
+}
+
+// JumpToPhase moves the scenario clock to the start of the named phase
+func (e *Engine) JumpToPhase(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	offset, err := e.scenario.GetPhaseOffset(name)
+	if err != nil {
+		return err
+	}
+	e.offset = offset
+	e.startTime = e.clock.Now()
+	return nil
 }
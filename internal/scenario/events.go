@@ -0,0 +1,106 @@
+package scenario
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventConfig declares a transient injection layered over the current phase, such
+// as a motion artifact or a notification turning the screen on. Occurrences happen
+// at fixed offsets (At), as a Poisson process with mean interval Every, or both.
+type EventConfig struct {
+	Name     string                   `yaml:"name" json:"name"`
+	At       []string                 `yaml:"at,omitempty" json:"at,omitempty"`         // offsets from the scenario start, e.g. ["2m", "7m30s"]
+	Every    string                   `yaml:"every,omitempty" json:"every,omitempty"`   // mean interval between random occurrences
+	Duration string                   `yaml:"duration" json:"duration"`                 // how long each occurrence lasts
+	Phases   []string                 `yaml:"phases,omitempty" json:"phases,omitempty"` // only active during these phases
+	Effects  map[string]*SignalConfig `yaml:"effects" json:"effects"`                   // per-signal modifiers while active
+}
+
+// Offsets returns the fixed occurrence offsets in ascending order, skipping invalid values
+func (e EventConfig) Offsets() []time.Duration {
+	offsets := make([]time.Duration, 0, len(e.At))
+	for _, at := range e.At {
+		if d, err := time.ParseDuration(at); err == nil && d >= 0 {
+			offsets = append(offsets, d)
+		}
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
+}
+
+// Schedule describes when the event occurs, for display
+func (e EventConfig) Schedule() string {
+	var parts []string
+	if len(e.At) > 0 {
+		parts = append(parts, "at "+strings.Join(e.At, ", "))
+	}
+	if e.Every != "" {
+		parts = append(parts, "every ~"+e.Every+" (random)")
+	}
+	return strings.Join(parts, " and ")
+}
+
+// ApplyEffect layers an event effect over a signal config. Unlike phase overrides,
+// add and multiply accumulate with the phase's modifiers; baseline, noise,
+// value and morphology replace them.
+func ApplyEffect(config, effect *SignalConfig) *SignalConfig {
+	result := *config
+	result.Add += effect.Add
+	if effect.Multiply != 0 {
+		result.Multiply = multiplier(config.Multiply) * effect.Multiply
+	}
+	if effect.Value != "" {
+		result.Value = effect.Value
+	}
+	if effect.Baseline != nil {
+		result.Baseline = effect.Baseline
+	}
+	if effect.Noise != nil {
+		result.Noise = effect.Noise
+	}
+	if effect.Morphology != nil {
+		result.Morphology = effect.Morphology
+	}
+	if effect.Markov != nil {
+		result.Markov = effect.Markov
+	}
+	return &result
+}
+
+// MatchesPhase reports whether the event may be active during the named phase.
+// A phase listed by its base name also matches its repeats (see phaseMatches).
+func (e EventConfig) MatchesPhase(phase string) bool {
+	if len(e.Phases) == 0 {
+		return true
+	}
+	for _, name := range e.Phases {
+		if phaseMatches(name, phase) {
+			return true
+		}
+	}
+	return false
+}
+
+// phaseMatches reports whether a phase name refers to an expanded phase: the
+// phase itself or one of its numbered repeats, so "sprint" matches "sprint-1"
+// and "sprint-2-3" but not "sprint-cooldown"
+func phaseMatches(name, phase string) bool {
+	suffix, ok := strings.CutPrefix(phase, name)
+	if !ok {
+		return false
+	}
+	for suffix != "" {
+		rest, ok := strings.CutPrefix(suffix, "-")
+		if !ok {
+			return false
+		}
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		if digits == 0 {
+			return false
+		}
+		suffix = rest[digits:]
+	}
+	return true
+}
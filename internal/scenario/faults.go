@@ -0,0 +1,121 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Fault types
+const (
+	FaultDropout   = "dropout"   // events are lost
+	FaultStuck     = "stuck"     // the sensor flatlines at its value when the fault began
+	FaultSaturate  = "saturate"  // values are pinned at the signal's range limit
+	FaultDuplicate = "duplicate" // events are delivered twice with the same ID and sequence
+	FaultTimeJump  = "time_jump" // timestamps are shifted by Offset
+	FaultSkew      = "skew"      // timestamps drift by Offset per minute
+	FaultReorder   = "reorder"   // events are held back by Delay and delivered out of order
+)
+
+// FaultTypes lists the supported fault types
+var FaultTypes = []string{FaultDropout, FaultStuck, FaultSaturate, FaultDuplicate, FaultTimeJump, FaultSkew, FaultReorder}
+
+// FaultConfig declares a sensor or delivery fault applied to generated events.
+// Faults declared on the scenario last the whole run and faults declared on a
+// phase last that phase. Without Every the fault is active throughout; with it,
+// the fault occurs in episodes of Duration as a Poisson process.
+type FaultConfig struct {
+	Type        string   `yaml:"type" json:"type"`
+	Signals     []string `yaml:"signals,omitempty" json:"signals,omitempty"`         // empty for every signal
+	Every       string   `yaml:"every,omitempty" json:"every,omitempty"`             // mean interval between episodes
+	Duration    string   `yaml:"duration,omitempty" json:"duration,omitempty"`       // how long each episode lasts
+	Probability float64  `yaml:"probability,omitempty" json:"probability,omitempty"` // share of events affected while active, 0 for all
+	Offset      string   `yaml:"offset,omitempty" json:"offset,omitempty"`           // time_jump: shift; skew: drift per minute
+	Limit       string   `yaml:"limit,omitempty" json:"limit,omitempty"`             // saturate: "high" (default) or "low"
+	Delay       string   `yaml:"delay,omitempty" json:"delay,omitempty"`             // reorder: how late held events arrive (default 1s)
+	Quality     *float64 `yaml:"quality,omitempty" json:"quality,omitempty"`         // quality of affected events, nil for the type's default
+}
+
+// Validate checks the fault's type, schedule and parameters, and its signals
+// against known. An empty known list skips the signal check.
+func (f FaultConfig) Validate(known []string) error {
+	var problems []string
+	if !containsString(FaultTypes, f.Type) {
+		problems = append(problems, fmt.Sprintf("unknown type %q (expected %s)", f.Type, strings.Join(FaultTypes, ", ")))
+	}
+	if len(known) > 0 {
+		for _, name := range f.Signals {
+			if !containsString(known, name) {
+				problems = append(problems, fmt.Sprintf("unknown signal %q", name))
+			}
+		}
+	}
+
+	switch {
+	case f.Every != "":
+		if d, err := time.ParseDuration(f.Every); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid every %q (expected a positive duration like 2m)", f.Every))
+		}
+		if d, err := time.ParseDuration(f.Duration); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid duration %q (expected a positive duration like 10s)", f.Duration))
+		}
+	case f.Duration != "":
+		problems = append(problems, "duration is only used with every")
+	}
+	if f.Probability < 0 || f.Probability > 1 {
+		problems = append(problems, fmt.Sprintf("invalid probability %g (expected 0 to 1)", f.Probability))
+	}
+	if f.Quality != nil && (*f.Quality < 0 || *f.Quality > 1) {
+		problems = append(problems, fmt.Sprintf("invalid quality %g (expected 0 to 1)", *f.Quality))
+	}
+
+	if f.Type == FaultTimeJump || f.Type == FaultSkew {
+		if d, err := time.ParseDuration(f.Offset); err != nil || d == 0 {
+			problems = append(problems, fmt.Sprintf("invalid offset %q (expected a non-zero duration like 2s or -500ms)", f.Offset))
+		}
+	} else if f.Offset != "" {
+		problems = append(problems, "offset is only used by time_jump and skew")
+	}
+	if f.Type == FaultSaturate {
+		if f.Limit != "" && f.Limit != "high" && f.Limit != "low" {
+			problems = append(problems, fmt.Sprintf("invalid limit %q (expected high or low)", f.Limit))
+		}
+	} else if f.Limit != "" {
+		problems = append(problems, "limit is only used by saturate")
+	}
+	if f.Type == FaultReorder {
+		if f.Delay != "" {
+			if d, err := time.ParseDuration(f.Delay); err != nil || d <= 0 {
+				problems = append(problems, fmt.Sprintf("invalid delay %q (expected a positive duration like 1s)", f.Delay))
+			}
+		}
+	} else if f.Delay != "" {
+		problems = append(problems, "delay is only used by reorder")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// AffectsSignal reports whether the fault applies to the named signal
+func (f FaultConfig) AffectsSignal(signalName string) bool {
+	return len(f.Signals) == 0 || containsString(f.Signals, signalName)
+}
+
+// Label describes the fault for display, e.g. "dropout of ppg.hr_bpm every ~2m for 10s"
+func (f FaultConfig) Label() string {
+	var b strings.Builder
+	b.WriteString(f.Type)
+	if len(f.Signals) > 0 {
+		b.WriteString(" of " + strings.Join(f.Signals, ", "))
+	}
+	if f.Every != "" {
+		fmt.Fprintf(&b, " every ~%s for %s", f.Every, f.Duration)
+	}
+	if f.Probability > 0 {
+		fmt.Fprintf(&b, " (p=%g)", f.Probability)
+	}
+	return b.String()
+}
@@ -2,49 +2,128 @@ package scenario
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
+// OriginBuiltin marks scenarios loaded from the definitions embedded in the binary
+const OriginBuiltin = "built-in"
+
+// LoadError describes a scenario file or directory the registry failed to load
+type LoadError struct {
+	Path string
+	Name string // the scenario the file defines, empty for a directory
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("failed to load scenario from %s: %v", e.Path, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
 // Registry holds all available scenarios
 type Registry struct {
-	scenarios map[string]*Scenario
+	scenarios    map[string]*Scenario
+	origins      map[string]string
+	broken       map[string]*LoadError // by scenario name, hiding any earlier scenario
+	failures     []*LoadError
+	knownSignals []string
 }
 
 // NewRegistry creates a new scenario registry
 func NewRegistry() *Registry {
 	return &Registry{
 		scenarios: make(map[string]*Scenario),
+		origins:   make(map[string]string),
+		broken:    make(map[string]*LoadError),
+	}
+}
+
+// SetKnownSignals restricts loaded scenarios to the given signal names
+func (r *Registry) SetKnownSignals(names []string) {
+	r.knownSignals = names
+}
+
+// LoadFromPath loads a scenario file, or every scenario in a directory
+func (r *Registry) LoadFromPath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return r.fail(path, "", err)
+	}
+	if info.IsDir() {
+		return r.LoadFromDir(path)
 	}
+	return r.LoadFromFile(path)
 }
 
 // LoadFromFile loads a scenario from a YAML file
 func (r *Registry) LoadFromFile(path string) error {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("failed to read scenario file: %w", err)
+		return r.fail(path, fileScenarioName(path), fmt.Errorf("failed to read scenario file: %w", err))
 	}
+	_, err = r.load(path, data, path)
+	return err
+}
 
-	var scenario Scenario
-	if err := yaml.Unmarshal(data, &scenario); err != nil {
-		return fmt.Errorf("failed to parse scenario YAML: %w", err)
+// load validates scenario YAML and registers it, replacing any scenario with the
+// same name. Warnings are ignored; any error-level issue rejects the file, which
+// is recorded as a failure of the scenario it defines.
+func (r *Registry) load(path string, data []byte, origin string) (*Scenario, error) {
+	scenario, issues := NewValidator(r.knownSignals).Validate(path, data)
+	if errs := issues.Errors(); len(errs) > 0 {
+		name := fileScenarioName(path)
+		if scenario != nil && scenario.Name != "" {
+			name = scenario.Name
+		}
+		return nil, r.fail(path, name, fmt.Errorf("invalid scenario:\n%w", errs))
 	}
 
-	r.scenarios[scenario.Name] = &scenario
-	return nil
+	r.scenarios[scenario.Name] = scenario
+	r.origins[scenario.Name] = origin
+	delete(r.broken, scenario.Name)
+	return scenario, nil
+}
+
+// fail records a path that failed to load. A named scenario is unavailable
+// until a later file defines it again, so it never falls back to a scenario
+// the failed file was meant to override.
+func (r *Registry) fail(path, name string, err error) *LoadError {
+	failure := &LoadError{Path: path, Name: name, Err: err}
+	r.failures = append(r.failures, failure)
+	if name != "" {
+		r.broken[name] = failure
+	}
+	return failure
+}
+
+// fileScenarioName returns the scenario name a file is assumed to define when
+// it can't be read: its base name without the extension
+func fileScenarioName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
 }
 
-// LoadFromDir loads all scenarios from a directory
+// Failures returns the files and directories that failed to load, in order
+func (r *Registry) Failures() []*LoadError {
+	return r.failures
+}
+
+// LoadFromDir loads all scenarios from a directory. Files that fail to load are
+// skipped; their errors are joined in the result and kept in Failures.
 func (r *Registry) LoadFromDir(dir string) error {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
-		return fmt.Errorf("failed to read scenarios directory: %w", err)
+		return r.fail(dir, "", fmt.Errorf("failed to read scenarios directory: %w", err))
 	}
 
+	var errs []error
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -55,11 +134,11 @@ func (r *Registry) LoadFromDir(dir string) error {
 
 		path := filepath.Join(dir, entry.Name())
 		if err := r.LoadFromFile(path); err != nil {
-			return fmt.Errorf("failed to load scenario from %s: %w", path, err)
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // LoadFromEmbedded loads scenarios from embedded filesystem
@@ -77,46 +156,84 @@ func (r *Registry) LoadFromEmbedded(fs embed.FS, dir string) error {
 			continue
 		}
 
-		path := filepath.Join(dir, entry.Name())
-		data, err := fs.ReadFile(path)
+		// embed.FS paths always use forward slashes
+		name := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(name)
 		if err != nil {
-			return fmt.Errorf("failed to read embedded file %s: %w", path, err)
+			return fmt.Errorf("failed to read embedded file %s: %w", name, err)
 		}
 
-		var scenario Scenario
-		if err := yaml.Unmarshal(data, &scenario); err != nil {
-			return fmt.Errorf("failed to parse scenario YAML from %s: %w", path, err)
+		if _, err := r.load(name, data, OriginBuiltin); err != nil {
+			return err
 		}
-
-		r.scenarios[scenario.Name] = &scenario
 	}
 
 	return nil
 }
 
-// Get retrieves a scenario by name
-func (r *Registry) Get(name string) (*Scenario, error) {
-	scenario, ok := r.scenarios[name]
-	if !ok {
-		return nil, fmt.Errorf("scenario '%s' not found", name)
+// Add registers a scenario, replacing any existing scenario with the same name
+func (r *Registry) Add(scenario *Scenario) {
+	r.scenarios[scenario.Name] = scenario
+	delete(r.origins, scenario.Name)
+}
+
+// Resolve returns the scenario named by value. A value that looks like a file
+// path (see IsScenarioPath) is loaded from disk and registered under its name.
+func (r *Registry) Resolve(value string) (*Scenario, error) {
+	if !IsScenarioPath(value) {
+		return r.Get(value)
 	}
-	return scenario, nil
+
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario file: %w", err)
+	}
+	scenario, err := r.load(value, data, value)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(scenario.Name)
+}
+
+// IsScenarioPath reports whether a --scenario value refers to a file rather than a scenario name
+func IsScenarioPath(value string) bool {
+	return strings.ContainsRune(value, '/') ||
+		strings.ContainsRune(value, filepath.Separator) ||
+		strings.HasSuffix(value, ".yaml") ||
+		strings.HasSuffix(value, ".yml")
+}
+
+// Origin returns where a scenario was loaded from: OriginBuiltin, a file path,
+// or an empty string for scenarios registered with Add
+func (r *Registry) Origin(name string) string {
+	return r.origins[name]
 }
 
-// List returns all scenario names
+// Get retrieves a scenario by name, flattened with its parents, includes and
+// phase templates resolved. Each call returns a fresh copy.
+func (r *Registry) Get(name string) (*Scenario, error) {
+	return r.resolve(name, nil)
+}
+
+// List returns all scenario names, leaving out those that failed to load
 func (r *Registry) List() []string {
 	names := make([]string, 0, len(r.scenarios))
 	for name := range r.scenarios {
-		names = append(names, name)
+		if r.broken[name] == nil {
+			names = append(names, name)
+		}
 	}
 	return names
 }
 
-// ListWithDescriptions returns all scenarios with their descriptions
+// ListWithDescriptions returns all scenarios with their descriptions, leaving
+// out those that failed to load
 func (r *Registry) ListWithDescriptions() map[string]string {
 	result := make(map[string]string)
 	for name, scenario := range r.scenarios {
-		result[name] = scenario.Description
+		if r.broken[name] == nil {
+			result[name] = scenario.Description
+		}
 	}
 	return result
 }
@@ -0,0 +1,118 @@
+package scenario
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/synheart/synheart-cli/scenarios"
+)
+
+func TestLoadFromEmbedded(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.LoadFromEmbedded(scenarios.FS, "."); err != nil {
+		t.Fatalf("failed to load built-in scenarios: %v", err)
+	}
+
+	scen, err := registry.Get("baseline")
+	if err != nil {
+		t.Fatalf("expected built-in baseline scenario: %v", err)
+	}
+	if len(scen.Signals) == 0 {
+		t.Error("expected baseline signals")
+	}
+	if origin := registry.Origin("baseline"); origin != OriginBuiltin {
+		t.Errorf("expected origin %q, got %q", OriginBuiltin, origin)
+	}
+}
+
+func TestUserScenarioOverridesBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.yaml")
+	writeFile(t, path, "name: baseline\ndescription: custom\n")
+
+	registry := NewRegistry()
+	if err := registry.LoadFromEmbedded(scenarios.FS, "."); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.LoadFromDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	scen, err := registry.Get("baseline")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scen.Description != "custom" {
+		t.Errorf("expected user scenario to override built-in, got %q", scen.Description)
+	}
+	if origin := registry.Origin("baseline"); origin != path {
+		t.Errorf("expected origin %q, got %q", path, origin)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom.yaml")
+	writeFile(t, path, "name: my_custom\nduration: 1m\n")
+
+	registry := NewRegistry()
+	scen, err := registry.Resolve(path)
+	if err != nil {
+		t.Fatalf("Resolve(%s): %v", path, err)
+	}
+	if scen.Name != "my_custom" {
+		t.Errorf("expected my_custom, got %s", scen.Name)
+	}
+
+	// Once loaded, the scenario is also available by name
+	if _, err := registry.Resolve("my_custom"); err != nil {
+		t.Errorf("Resolve(my_custom): %v", err)
+	}
+	if _, err := registry.Resolve("missing"); err == nil {
+		t.Error("expected error for unknown scenario name")
+	}
+	if _, err := registry.Resolve(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for missing scenario file")
+	}
+}
+
+func TestLoadFromDirSkipsBrokenFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "good.yaml"), "name: good\nduration: 1m\n")
+	writeFile(t, filepath.Join(dir, "broken.yaml"), "name: broken\nduration: soon\n")
+	writeFile(t, filepath.Join(dir, "sleep_night.yaml"), "name: sleep_night\nduration: [\n")
+
+	registry := NewRegistry()
+	if err := registry.LoadFromEmbedded(scenarios.FS, "."); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.LoadFromDir(dir); err == nil {
+		t.Error("expected the broken files reported")
+	}
+	if err := registry.LoadFromPath(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing path")
+	}
+
+	if _, err := registry.Get("good"); err != nil {
+		t.Errorf("expected the valid file loaded: %v", err)
+	}
+	if _, err := registry.Get("stress_spike"); err != nil {
+		t.Errorf("expected the built-ins loaded: %v", err)
+	}
+	// A broken override hides the built-in rather than falling back to it
+	for _, name := range []string{"broken", "sleep_night"} {
+		var failure *LoadError
+		if _, err := registry.Get(name); !errors.As(err, &failure) || failure.Name != name {
+			t.Errorf("Get(%s): expected its load error, got %v", name, err)
+		}
+	}
+	for _, name := range registry.List() {
+		if name == "broken" || name == "sleep_night" {
+			t.Errorf("expected %s left out of the list", name)
+		}
+	}
+	if n := len(registry.Failures()); n != 3 {
+		t.Errorf("expected 3 failures, got %d", n)
+	}
+}
@@ -0,0 +1,133 @@
+package scenario
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Markov configures the state chain of a discrete signal. Each state lasts a
+// random dwell time around its mean, then moves to another state drawn by the
+// relative weights of its transitions. The chain starts in Initial, and enters it
+// from any state it doesn't know (e.g. after a phase switches chains); without
+// Initial, that state is drawn in proportion to the mean dwells.
+type Markov struct {
+	Initial     string                        `yaml:"initial,omitempty" json:"initial,omitempty"`         // optional starting state
+	Dwell       map[string]string             `yaml:"dwell" json:"dwell"`                                 // mean time spent in each state
+	Transitions map[string]map[string]float64 `yaml:"transitions,omitempty" json:"transitions,omitempty"` // next-state weights by current state
+}
+
+// DefaultMarkov returns the chain used by a discrete signal whose config doesn't
+// declare one, or nil if the signal isn't discrete
+func DefaultMarkov(signalName string) *Markov {
+	switch signalName {
+	case "screen.state":
+		return &Markov{
+			Dwell: map[string]string{"on": "5m", "off": "20s"},
+			Transitions: map[string]map[string]float64{
+				"on":  {"off": 1},
+				"off": {"on": 1},
+			},
+		}
+	case "app.activity":
+		return &Markov{
+			Dwell: map[string]string{"foreground": "40s", "typing": "25s", "scrolling": "45s", "background": "2m"},
+			Transitions: map[string]map[string]float64{
+				"foreground": {"typing": 3, "scrolling": 3, "background": 2},
+				"typing":     {"foreground": 2, "scrolling": 1, "background": 1},
+				"scrolling":  {"foreground": 2, "typing": 1, "background": 1},
+				"background": {"foreground": 1},
+			},
+		}
+	case "motion.activity":
+		return &Markov{
+			Dwell: map[string]string{"still": "5m", "walk": "2m", "run": "1m"},
+			Transitions: map[string]map[string]float64{
+				"still": {"walk": 9, "run": 1},
+				"walk":  {"still": 4, "run": 1},
+				"run":   {"walk": 1},
+			},
+		}
+	}
+	return nil
+}
+
+// States returns the chain's states in sorted order
+func (m *Markov) States() []string {
+	states := make([]string, 0, len(m.Dwell))
+	for state := range m.Dwell {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	return states
+}
+
+// DwellDuration returns the mean dwell of a state, 0 if unknown or invalid
+func (m *Markov) DwellDuration(state string) time.Duration {
+	d, err := time.ParseDuration(m.Dwell[state])
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Validate checks the dwell times, the initial state and that every transition
+// connects declared states with non-negative weights
+func (m *Markov) Validate() error {
+	var problems []string
+	if len(m.Dwell) == 0 {
+		problems = append(problems, "dwell must declare at least one state")
+	}
+	for _, state := range m.States() {
+		if d, err := time.ParseDuration(m.Dwell[state]); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid dwell %q for %s (expected a positive duration like 30s)", m.Dwell[state], state))
+		}
+	}
+	if m.Initial != "" {
+		if _, ok := m.Dwell[m.Initial]; !ok {
+			problems = append(problems, fmt.Sprintf("initial state %q has no dwell", m.Initial))
+		}
+	}
+
+	from := make([]string, 0, len(m.Transitions))
+	for state := range m.Transitions {
+		from = append(from, state)
+	}
+	sort.Strings(from)
+	for _, state := range from {
+		if _, ok := m.Dwell[state]; !ok {
+			problems = append(problems, fmt.Sprintf("transitions from %q, which has no dwell", state))
+			continue
+		}
+		total := 0.0
+		for next, weight := range m.Transitions[state] {
+			switch {
+			case next == state:
+				problems = append(problems, fmt.Sprintf("%s transitions to itself (lengthen its dwell instead)", state))
+			case weight < 0:
+				problems = append(problems, fmt.Sprintf("negative weight from %s to %s", state, next))
+			}
+			if _, ok := m.Dwell[next]; !ok {
+				problems = append(problems, fmt.Sprintf("%s transitions to %q, which has no dwell", state, next))
+			}
+			total += weight
+		}
+		if total <= 0 {
+			problems = append(problems, fmt.Sprintf("%s has no transition with a positive weight", state))
+		}
+	}
+	if len(m.Dwell) > 1 {
+		for _, state := range m.States() {
+			if _, ok := m.Transitions[state]; !ok {
+				problems = append(problems, fmt.Sprintf("%s has no transitions", state))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
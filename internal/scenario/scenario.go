@@ -1,24 +1,67 @@
 package scenario
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Scenario defines a complete scenario with phases and signal configurations
 type Scenario struct {
-	Name        string                   `yaml:"name"`
-	Description string                   `yaml:"description"`
-	Duration    string                   `yaml:"duration"` // e.g., "8m", "unlimited"
-	DefaultRate string                   `yaml:"default_rate"`
-	Signals     map[string]*SignalConfig `yaml:"signals"`
-	Phases      []Phase                  `yaml:"phases"`
+	Name                       string                   `yaml:"name"`
+	Description                string                   `yaml:"description"`
+	Extends                    string                   `yaml:"extends,omitempty"`  // scenario to inherit signals and defaults from
+	Includes                   []string                 `yaml:"includes,omitempty"` // scenarios to import phase templates from
+	Duration                   string                   `yaml:"duration"`           // e.g., "8m", "unlimited"
+	DefaultRate                string                   `yaml:"default_rate"`
+	Signals                    map[string]*SignalConfig `yaml:"signals"`
+	Templates                  map[string][]Phase       `yaml:"templates,omitempty"` // reusable phase sequences
+	Phases                     []Phase                  `yaml:"phases"`
+	Sources                    []SourceConfig           `yaml:"sources,omitempty"`
+	Events                     []EventConfig            `yaml:"events,omitempty"`                       // transient injections over the phases
+	Correlations               []CorrelationRule        `yaml:"correlations,omitempty"`                 // nil for DefaultCorrelations
+	IncludeDefaultCorrelations bool                     `yaml:"include_default_correlations,omitempty"` // merge Correlations into the defaults by name
+	Faults                     []FaultConfig            `yaml:"faults,omitempty"`                       // sensor and delivery faults for the whole run
 }
 
-// Phase represents a time-bounded stage of a scenario with specific overrides
+// SourceConfig declares a device that emits a subset of the scenario signals.
+// All sources in a scenario share one session run ID.
+type SourceConfig struct {
+	ID      string   `yaml:"id" json:"id"`
+	Type    string   `yaml:"type" json:"type"`                           // "wearable" or "phone"
+	Side    string   `yaml:"side,omitempty" json:"side,omitempty"`       // "left" or "right" for wearables
+	Signals []string `yaml:"signals,omitempty" json:"signals,omitempty"` // empty means every scenario signal
+	Rate    string   `yaml:"rate,omitempty" json:"rate,omitempty"`       // caps the emission rate of every signal
+	Skew    string   `yaml:"skew,omitempty" json:"skew,omitempty"`       // clock offset applied to timestamps, e.g. "-150ms"
+}
+
+// Phase represents a time-bounded stage of a scenario with specific overrides.
+// A phase referencing a template expands to the template's phases, and Repeat
+// expands it that many times; both are resolved by the Registry.
 type Phase struct {
 	Name      string                   `yaml:"name"`
 	Duration  string                   `yaml:"duration"`
 	Overrides map[string]*SignalConfig `yaml:"overrides,omitempty"`
+	Template  string                   `yaml:"template,omitempty" json:",omitempty"`
+	Repeat    int                      `yaml:"repeat,omitempty" json:",omitempty"`
+	Faults    []FaultConfig            `yaml:"faults,omitempty" json:",omitempty"` // active while in this phase
+	// SleepStage marks the phase as time in bed for vendor sleep summaries
+	SleepStage string `yaml:"sleep_stage,omitempty" json:",omitempty"`
 }
 
+// Sleep stages a phase can report through SleepStage
+const (
+	SleepAwake = "awake"
+	SleepLight = "light"
+	SleepDeep  = "deep"
+	SleepREM   = "rem"
+)
+
+// SleepStages lists the valid values of Phase.SleepStage
+var SleepStages = []string{SleepAwake, SleepLight, SleepDeep, SleepREM}
+
 // SignalConfig defines the configuration for a signal
 type SignalConfig struct {
 	Baseline interface{} `yaml:"baseline,omitempty"` // Can be number or array
@@ -32,6 +75,54 @@ type SignalConfig struct {
 	Value          string  `yaml:"value,omitempty"` // For discrete values like "on"/"off"
 	Ramp           string  `yaml:"ramp,omitempty"`  // Ramp duration
 	RampToBaseline string  `yaml:"ramp_to_baseline,omitempty"`
+
+	// Sampled signals (see BlockSignals)
+	Block      int         `yaml:"block,omitempty"`      // samples per event, 0 for one event per sample
+	Morphology *Morphology `yaml:"morphology,omitempty"` // ppg.raw pulse shape, nil for the defaults
+
+	// Discrete signals (see DiscreteSignals)
+	Markov *Markov `yaml:"markov,omitempty"` // state chain, nil for DefaultMarkov
+}
+
+// Morphology shapes the ppg.raw pulse wave. Zero fields use the defaults.
+type Morphology struct {
+	Amplitude float64 `yaml:"amplitude,omitempty"` // systolic peak above the trough (default 1)
+	Dicrotic  float64 `yaml:"dicrotic,omitempty"`  // diastolic wave relative to the systolic peak (default 0.4)
+	Notch     float64 `yaml:"notch,omitempty"`     // dicrotic notch position as a fraction of the beat (default 0.35)
+	Wander    float64 `yaml:"wander,omitempty"`    // respiratory baseline wander amplitude (default 0.1)
+	Motion    float64 `yaml:"motion,omitempty"`    // artifact per m/s² of acceleration beyond gravity (default 0.5)
+}
+
+// Validate checks the source for a known type and side, signals from known,
+// and parseable rate and skew. An empty known list skips the signal check.
+func (c SourceConfig) Validate(known []string) error {
+	if c.ID == "" {
+		return fmt.Errorf("id is required")
+	}
+	if c.Type != "wearable" && c.Type != "phone" {
+		return fmt.Errorf("invalid type %q (expected: wearable|phone)", c.Type)
+	}
+	if c.Side != "" && c.Side != "left" && c.Side != "right" {
+		return fmt.Errorf("invalid side %q (expected: left|right)", c.Side)
+	}
+	if len(known) > 0 {
+		for _, name := range c.Signals {
+			if !containsString(known, name) {
+				return fmt.Errorf("unknown signal %q", name)
+			}
+		}
+	}
+	if c.Rate != "" {
+		if _, err := ParseRate(c.Rate); err != nil {
+			return err
+		}
+	}
+	if c.Skew != "" {
+		if _, err := time.ParseDuration(c.Skew); err != nil {
+			return fmt.Errorf("invalid skew %q", c.Skew)
+		}
+	}
+	return nil
 }
 
 // ParseDuration parses duration strings like "8m", "30s", "unlimited"
@@ -46,7 +137,34 @@ func ParseDuration(s string) (time.Duration, bool) {
 	return d, false
 }
 
-// GetEffectiveConfig returns the signal config for a given signal name at a specific time
+// MaxRate is the highest rate ParseRate accepts, in Hz; faster rates would
+// round to intervals too short to tick
+const MaxRate = 10000.0
+
+// ParseRate parses rate strings like "50hz" or "0.2hz" into an emission interval.
+// It is the one rate parser for scenarios, the generator and the command line,
+// and rejects anything after the unit.
+func ParseRate(rate string) (time.Duration, error) {
+	number, ok := strings.CutSuffix(rate, "hz")
+	if !ok {
+		return 0, fmt.Errorf("invalid rate %q: expected a frequency like 50hz", rate)
+	}
+	hz, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: expected a frequency like 50hz", rate)
+	}
+	if !(hz > 0) || math.IsInf(hz, 0) {
+		return 0, fmt.Errorf("invalid rate %q: must be positive", rate)
+	}
+	if hz > MaxRate {
+		return 0, fmt.Errorf("invalid rate %q: must be at most %ghz", rate, MaxRate)
+	}
+	return time.Duration(float64(time.Second) / hz), nil
+}
+
+// GetEffectiveConfig returns the signal config for a given signal name at a specific time.
+// Phase overrides with a ramp are interpolated from the previous phase's values using
+// the phase-relative elapsed time, and ramp_to_baseline decays them back to the base config.
 func (s *Scenario) GetEffectiveConfig(signalName string, elapsed time.Duration) *SignalConfig {
 	// Start with base signal config
 	baseConfig := s.Signals[signalName]
@@ -55,60 +173,228 @@ func (s *Scenario) GetEffectiveConfig(signalName string, elapsed time.Duration)
 	}
 
 	// Find current phase
-	currentPhase := s.getCurrentPhase(elapsed)
-	if currentPhase == nil {
+	index, phaseStart := s.getPhaseAt(elapsed)
+	if index < 0 {
 		return baseConfig
 	}
 
 	// Apply phase overrides if they exist
-	if override, ok := currentPhase.Overrides[signalName]; ok {
-		// Merge override with base config
-		merged := *baseConfig
-		if override.Add != 0 {
-			merged.Add = override.Add
-		}
-		if override.Multiply != 0 {
-			merged.Multiply = override.Multiply
-		}
-		if override.Value != "" {
-			merged.Value = override.Value
-		}
-		if override.Ramp != "" {
-			merged.Ramp = override.Ramp
-		}
-		if override.RampToBaseline != "" {
-			merged.RampToBaseline = override.RampToBaseline
+	target := baseConfig
+	if override, ok := s.Phases[index].Overrides[signalName]; ok {
+		target = mergeOverride(baseConfig, override)
+	}
+
+	phaseElapsed := elapsed - phaseStart
+	rampDuration := parseRampDuration(target.Ramp)
+	decayDuration := parseRampDuration(target.RampToBaseline)
+	if rampDuration == 0 && decayDuration == 0 {
+		return target
+	}
+
+	result := *target
+
+	// Ramp in from wherever the previous phase left off
+	if rampDuration > 0 && index > 0 && phaseElapsed < rampDuration {
+		previous := s.GetEffectiveConfig(signalName, phaseStart-time.Nanosecond)
+		progress := float64(phaseElapsed) / float64(rampDuration)
+		interpolateModifiers(&result, previous, target, progress)
+	}
+
+	// Decay back towards the base config once the ramp in has finished
+	if decayDuration > 0 && phaseElapsed > rampDuration {
+		progress := float64(phaseElapsed-rampDuration) / float64(decayDuration)
+		if progress > 1 {
+			progress = 1
 		}
-		if override.Baseline != nil {
-			merged.Baseline = override.Baseline
+		from := result
+		interpolateModifiers(&result, &from, baseConfig, progress)
+	}
+
+	return &result
+}
+
+// GetPhaseStart returns the scenario offset at which the phase active at elapsed began
+func (s *Scenario) GetPhaseStart(elapsed time.Duration) time.Duration {
+	_, start := s.getPhaseAt(elapsed)
+	return start
+}
+
+// GetPhaseOffset returns the scenario offset at which the named phase begins
+func (s *Scenario) GetPhaseOffset(name string) (time.Duration, error) {
+	var offset time.Duration
+	for _, phase := range s.Phases {
+		if phase.Name == name {
+			return offset, nil
 		}
-		if override.Noise != nil {
-			merged.Noise = override.Noise
+		duration, unlimited := ParseDuration(phase.Duration)
+		if unlimited {
+			break
 		}
-		return &merged
+		offset += duration
 	}
-
-	return baseConfig
+	return 0, fmt.Errorf("phase '%s' not found in scenario '%s'", name, s.Name)
 }
 
 func (s *Scenario) getCurrentPhase(elapsed time.Duration) *Phase {
-	if len(s.Phases) == 0 {
+	index, _ := s.getPhaseAt(elapsed)
+	if index < 0 {
 		return nil
 	}
+	return &s.Phases[index]
+}
+
+// getPhaseAt returns the index and start offset of the phase active at elapsed,
+// or -1 if the scenario has no phases
+func (s *Scenario) getPhaseAt(elapsed time.Duration) (int, time.Duration) {
+	if len(s.Phases) == 0 {
+		return -1, 0
+	}
 
 	var currentTime time.Duration
 	for i := range s.Phases {
 		phaseDuration, unlimited := ParseDuration(s.Phases[i].Duration)
 		if unlimited {
-			return &s.Phases[i]
+			return i, currentTime
 		}
 
 		if elapsed < currentTime+phaseDuration {
-			return &s.Phases[i]
+			return i, currentTime
 		}
-		currentTime += phaseDuration
+		if i < len(s.Phases)-1 {
+			currentTime += phaseDuration
+		}
+	}
+
+	// Stay in the last phase if we've exceeded total duration
+	return len(s.Phases) - 1, currentTime
+}
+
+// mergeOverride merges a phase override on top of a base config
+func mergeOverride(base, override *SignalConfig) *SignalConfig {
+	merged := *base
+	if override.Add != 0 {
+		merged.Add = override.Add
+	}
+	if override.Multiply != 0 {
+		merged.Multiply = override.Multiply
+	}
+	if override.Value != "" {
+		merged.Value = override.Value
+	}
+	if override.Ramp != "" {
+		merged.Ramp = override.Ramp
+	}
+	if override.RampToBaseline != "" {
+		merged.RampToBaseline = override.RampToBaseline
 	}
+	if override.Baseline != nil {
+		merged.Baseline = override.Baseline
+	}
+	if override.Noise != nil {
+		merged.Noise = override.Noise
+	}
+	if override.Morphology != nil {
+		merged.Morphology = override.Morphology
+	}
+	if override.Markov != nil {
+		merged.Markov = override.Markov
+	}
+	return &merged
+}
+
+// parseRampDuration parses a ramp window, treating empty or invalid values as no ramp
+func parseRampDuration(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// interpolateModifiers sets add, multiply and baseline on dst to the linear
+// interpolation between from and to at progress (0-1)
+func interpolateModifiers(dst, from, to *SignalConfig, progress float64) {
+	dst.Add = lerp(from.Add, to.Add, progress)
+	dst.Multiply = lerp(multiplier(from.Multiply), multiplier(to.Multiply), progress)
+	dst.Baseline = lerpBaseline(from.Baseline, to.Baseline, progress)
+}
 
-	// Return last phase if we've exceeded total duration
-	return &s.Phases[len(s.Phases)-1]
+// multiplier maps an unset (zero) multiply modifier to its neutral value
+func multiplier(m float64) float64 {
+	if m == 0 {
+		return 1
+	}
+	return m
+}
+
+func lerp(from, to, progress float64) float64 {
+	return from + (to-from)*progress
+}
+
+// lerpBaseline interpolates numeric or vector baselines, falling back to the
+// target baseline when the two values can't be interpolated
+func lerpBaseline(from, to interface{}, progress float64) interface{} {
+	if from == nil || to == nil {
+		return to
+	}
+
+	if f, ok := toFloat(from); ok {
+		if t, ok := toFloat(to); ok {
+			return lerp(f, t, progress)
+		}
+		return to
+	}
+
+	fromVec, ok1 := toFloatSlice(from)
+	toVec, ok2 := toFloatSlice(to)
+	if !ok1 || !ok2 || len(fromVec) != len(toVec) {
+		return to
+	}
+	result := make([]float64, len(toVec))
+	for i := range toVec {
+		result[i] = lerp(fromVec[i], toVec[i], progress)
+	}
+	return result
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func toFloatSlice(v interface{}) ([]float64, bool) {
+	switch vals := v.(type) {
+	case []float64:
+		return vals, true
+	case []interface{}:
+		result := make([]float64, len(vals))
+		for i, val := range vals {
+			f, ok := toFloat(val)
+			if !ok {
+				return nil, false
+			}
+			result[i] = f
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -29,6 +29,26 @@ func TestParseDuration(t *testing.T) {
 	}
 }
 
+func TestParseRate(t *testing.T) {
+	valid := map[string]time.Duration{
+		"50hz":  20 * time.Millisecond,
+		"1hz":   time.Second,
+		"0.2hz": 5 * time.Second,
+		"1e4hz": 100 * time.Microsecond,
+	}
+	for input, expected := range valid {
+		if interval, err := ParseRate(input); err != nil || interval != expected {
+			t.Errorf("ParseRate(%s): expected %v, got %v (%v)", input, expected, interval, err)
+		}
+	}
+
+	for _, input := range []string{"", "50", "hz", "50hzxyz", "50 hz", "50Hz", "0hz", "-1hz", "NaNhz", "Infhz", "10001hz", "1e10hz", "1e12hz"} {
+		if _, err := ParseRate(input); err == nil {
+			t.Errorf("ParseRate(%q): expected an error", input)
+		}
+	}
+}
+
 func TestGetEffectiveConfig(t *testing.T) {
 	scenario := &Scenario{
 		Name: "test",
@@ -102,3 +122,228 @@ func TestScenarioEngine(t *testing.T) {
 		t.Errorf("Expected baseline 72.0, got %v", config.Baseline)
 	}
 }
+
+func TestGetEffectiveConfigRamp(t *testing.T) {
+	scenario := &Scenario{
+		Name: "test",
+		Signals: map[string]*SignalConfig{
+			"ppg.hr_bpm": {
+				Baseline: 72.0,
+			},
+		},
+		Phases: []Phase{
+			{
+				Name:     "baseline",
+				Duration: "2m",
+			},
+			{
+				Name:     "spike",
+				Duration: "30s",
+				Overrides: map[string]*SignalConfig{
+					"ppg.hr_bpm": {
+						Add:  30.0,
+						Ramp: "10s",
+					},
+				},
+			},
+			{
+				Name:     "recovery",
+				Duration: "5m",
+				Overrides: map[string]*SignalConfig{
+					"ppg.hr_bpm": {
+						Add:            10.0,
+						Ramp:           "20s",
+						RampToBaseline: "1m",
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		add     float64
+	}{
+		{2 * time.Minute, 0},
+		{2*time.Minute + 5*time.Second, 15},
+		{2*time.Minute + 10*time.Second, 30},
+		{2*time.Minute + 30*time.Second, 30},
+		{2*time.Minute + 40*time.Second, 20},
+		{2*time.Minute + 50*time.Second, 10},
+		{2*time.Minute + 80*time.Second, 5},
+		{4 * time.Minute, 0},
+	}
+
+	for _, test := range tests {
+		config := scenario.GetEffectiveConfig("ppg.hr_bpm", test.elapsed)
+		if diff := config.Add - test.add; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("at %v: expected add=%v, got %v", test.elapsed, test.add, config.Add)
+		}
+	}
+}
+
+func TestGetEffectiveConfigRampBaseline(t *testing.T) {
+	scenario := &Scenario{
+		Name: "test",
+		Signals: map[string]*SignalConfig{
+			"accel.xyz_mps2": {
+				Baseline: []interface{}{0, 0, 9.81},
+			},
+			"ppg.hrv_rmssd_ms": {
+				Baseline: 50.0,
+			},
+		},
+		Phases: []Phase{
+			{
+				Name:     "rest",
+				Duration: "1m",
+			},
+			{
+				Name:     "move",
+				Duration: "1m",
+				Overrides: map[string]*SignalConfig{
+					"accel.xyz_mps2": {
+						Baseline: []interface{}{2.0, 0, 9.81},
+						Ramp:     "10s",
+					},
+					"ppg.hrv_rmssd_ms": {
+						Multiply: 0.5,
+						Ramp:     "10s",
+					},
+				},
+			},
+		},
+	}
+
+	config := scenario.GetEffectiveConfig("accel.xyz_mps2", time.Minute+5*time.Second)
+	vec, ok := config.Baseline.([]float64)
+	if !ok || len(vec) != 3 {
+		t.Fatalf("Expected interpolated vector baseline, got %#v", config.Baseline)
+	}
+	if vec[0] != 1.0 || vec[2] != 9.81 {
+		t.Errorf("Expected baseline [1 0 9.81], got %v", vec)
+	}
+
+	config = scenario.GetEffectiveConfig("ppg.hrv_rmssd_ms", time.Minute+5*time.Second)
+	if config.Multiply != 0.75 {
+		t.Errorf("Expected multiply=0.75 halfway through ramp, got %v", config.Multiply)
+	}
+}
+
+func TestGetPhaseStart(t *testing.T) {
+	scenario := &Scenario{
+		Name: "test",
+		Phases: []Phase{
+			{Name: "a", Duration: "2m"},
+			{Name: "b", Duration: "30s"},
+			{Name: "c", Duration: "1m"},
+		},
+	}
+
+	tests := []struct {
+		elapsed time.Duration
+		start   time.Duration
+	}{
+		{0, 0},
+		{time.Minute, 0},
+		{2 * time.Minute, 2 * time.Minute},
+		{2*time.Minute + 45*time.Second, 2*time.Minute + 30*time.Second},
+		{10 * time.Minute, 2*time.Minute + 30*time.Second},
+	}
+
+	for _, test := range tests {
+		if start := scenario.GetPhaseStart(test.elapsed); start != test.start {
+			t.Errorf("GetPhaseStart(%v): expected %v, got %v", test.elapsed, test.start, start)
+		}
+	}
+}
+
+func TestEnginePauseAndJump(t *testing.T) {
+	scenario := &Scenario{
+		Name:     "test",
+		Duration: "5m",
+		Phases: []Phase{
+			{Name: "rest", Duration: "2m"},
+			{Name: "spike", Duration: "1m"},
+			{Name: "recovery", Duration: "2m"},
+		},
+	}
+
+	engine := NewEngine(scenario)
+	engine.Pause()
+	if !engine.IsPaused() {
+		t.Fatal("Expected engine to be paused")
+	}
+
+	elapsed := engine.GetElapsed()
+	time.Sleep(10 * time.Millisecond)
+	if engine.GetElapsed() != elapsed {
+		t.Error("Expected elapsed time to be frozen while paused")
+	}
+
+	if err := engine.JumpToPhase("spike"); err != nil {
+		t.Fatalf("JumpToPhase failed: %v", err)
+	}
+	if phase := engine.GetCurrentPhase(); phase.Name != "spike" {
+		t.Errorf("Expected phase spike, got %s", phase.Name)
+	}
+	if engine.GetPhaseStart() != 2*time.Minute || engine.GetPhaseElapsed() != 0 {
+		t.Errorf("Expected paused engine at start of spike, got start=%v elapsed=%v", engine.GetPhaseStart(), engine.GetPhaseElapsed())
+	}
+
+	engine.Resume()
+	time.Sleep(10 * time.Millisecond)
+	if engine.GetPhaseElapsed() <= 0 {
+		t.Error("Expected clock to advance after resume")
+	}
+
+	if err := engine.JumpToPhase("missing"); err == nil {
+		t.Error("Expected error for unknown phase")
+	}
+}
+
+func TestApplyEffect(t *testing.T) {
+	config := &SignalConfig{Baseline: 72.0, Noise: 3.0, Add: 10, Multiply: 1.5}
+
+	result := ApplyEffect(config, &SignalConfig{Add: 5, Multiply: 2, Noise: 8.0})
+	if result.Add != 15 {
+		t.Errorf("expected add to accumulate to 15, got %v", result.Add)
+	}
+	if result.Multiply != 3 {
+		t.Errorf("expected multiply to compound to 3, got %v", result.Multiply)
+	}
+	if result.Noise != 8.0 || result.Baseline != 72.0 {
+		t.Errorf("expected noise replaced and baseline kept, got %v and %v", result.Noise, result.Baseline)
+	}
+	if config.Add != 10 || config.Noise != 3.0 {
+		t.Error("ApplyEffect modified the input config")
+	}
+
+	unscaled := ApplyEffect(&SignalConfig{}, &SignalConfig{Multiply: 0.5})
+	if unscaled.Multiply != 0.5 {
+		t.Errorf("expected multiply 0.5 over an unscaled config, got %v", unscaled.Multiply)
+	}
+}
+
+func TestMatchesPhase(t *testing.T) {
+	event := EventConfig{Phases: []string{"sprint", "focus"}}
+	for phase, want := range map[string]bool{
+		"sprint":          true,
+		"sprint-2":        true,
+		"sprint-1-3":      true,
+		"focus":           true,
+		"focus-break":     false,
+		"sprint-cooldown": false,
+		"sprint-2x":       false,
+		"sprint-":         false,
+		"sprinter":        false,
+		"warmup":          false,
+	} {
+		if got := event.MatchesPhase(phase); got != want {
+			t.Errorf("MatchesPhase(%q) = %v, want %v", phase, got, want)
+		}
+	}
+	if !(EventConfig{}).MatchesPhase("anything") {
+		t.Error("expected an event without phases to match every phase")
+	}
+}
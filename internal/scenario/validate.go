@@ -0,0 +1,687 @@
+package scenario
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity levels for validation issues
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue describes a problem found while validating a scenario file
+type Issue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (i Issue) Error() string {
+	if i.Line > 0 && i.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", i.File, i.Line, i.Column, i.Message)
+	}
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.File, i.Message)
+}
+
+// Issues is a list of validation issues usable as an error
+type Issues []Issue
+
+func (is Issues) Error() string {
+	msgs := make([]string, len(is))
+	for i, issue := range is {
+		msgs[i] = issue.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Errors returns only the issues with error severity
+func (is Issues) Errors() Issues {
+	var errs Issues
+	for _, issue := range is {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	return errs
+}
+
+// Validator checks scenario YAML against the scenario schema
+type Validator struct {
+	knownSignals []string
+}
+
+// NewValidator creates a validator. When knownSignals is empty, signal names are not checked.
+func NewValidator(knownSignals []string) *Validator {
+	return &Validator{knownSignals: knownSignals}
+}
+
+var yamlLinePattern = regexp.MustCompile(`line (\d+): `)
+
+// Validate parses scenario YAML strictly and checks it for unknown fields, unknown
+// signals, unparseable rates and durations, and phase durations that don't add up.
+// The scenario is returned whenever the YAML could be decoded, even if issues were found.
+func (v *Validator) Validate(file string, data []byte) (*Scenario, Issues) {
+	c := &checker{file: file, validator: v}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		c.addYAMLError(err)
+		return nil, c.issues
+	}
+	if len(root.Content) == 0 {
+		c.add(nil, SeverityError, "scenario file is empty")
+		return nil, c.issues
+	}
+
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		c.add(doc, SeverityError, "scenario must be a mapping")
+		return nil, c.issues
+	}
+
+	c.checkFields(doc, reflect.TypeOf(Scenario{}), "")
+
+	var scenario Scenario
+	if err := doc.Decode(&scenario); err != nil {
+		c.addYAMLError(err)
+		return nil, c.issues
+	}
+
+	c.checkScenario(doc, &scenario)
+	return &scenario, c.issues
+}
+
+type checker struct {
+	file      string
+	validator *Validator
+	issues    Issues
+}
+
+func (c *checker) add(node *yaml.Node, severity, format string, args ...interface{}) {
+	issue := Issue{
+		File:     c.file,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if node != nil {
+		issue.Line = node.Line
+		issue.Column = node.Column
+	}
+	c.issues = append(c.issues, issue)
+}
+
+// addYAMLError converts yaml.v3 syntax and type errors into issues, keeping line numbers
+func (c *checker) addYAMLError(err error) {
+	messages := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	}
+
+	for _, msg := range messages {
+		issue := Issue{File: c.file, Severity: SeverityError, Message: strings.TrimPrefix(msg, "yaml: ")}
+		if m := yamlLinePattern.FindStringSubmatch(issue.Message); m != nil {
+			issue.Line, _ = strconv.Atoi(m[1])
+			issue.Message = strings.Replace(issue.Message, m[0], "", 1)
+		}
+		c.issues = append(c.issues, issue)
+	}
+}
+
+// checkFields reports mapping keys that don't correspond to a yaml-tagged field of t,
+// recursing through nested structs, maps and slices
+func (c *checker) checkFields(node *yaml.Node, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				c.add(key, SeverityError, "unknown field %q%s", key.Value, describePath(path))
+				continue
+			}
+			c.checkFields(value, fieldType, joinPath(path, key.Value))
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			c.checkFields(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			c.checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// yamlFields maps yaml keys to field types for a struct, flattening inline fields
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(field.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " in " + path
+}
+
+// mappingValue returns the key and value nodes for key in a mapping node
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func (c *checker) checkScenario(doc *yaml.Node, s *Scenario) {
+	if s.Name == "" {
+		c.add(doc, SeverityError, "name is required")
+	}
+
+	_, durationNode := mappingValue(doc, "duration")
+	scenarioDuration, scenarioUnlimited, ok := c.checkDuration(durationNode, s.Duration, "duration")
+
+	if _, rateNode := mappingValue(doc, "default_rate"); rateNode != nil {
+		c.checkRate(rateNode, s.DefaultRate)
+	}
+
+	_, signalsNode := mappingValue(doc, "signals")
+	c.checkSignals(signalsNode)
+
+	_, templatesNode := mappingValue(doc, "templates")
+	templates := c.checkTemplates(templatesNode, s)
+
+	_, phasesNode := mappingValue(doc, "phases")
+	phases := c.checkPhases(phasesNode, s, templates)
+
+	_, sourcesNode := mappingValue(doc, "sources")
+	c.checkSources(sourcesNode, s.Sources)
+
+	_, eventsNode := mappingValue(doc, "events")
+	c.checkEvents(eventsNode, s, scenarioDuration, ok && !scenarioUnlimited)
+
+	_, correlationsNode := mappingValue(doc, "correlations")
+	c.checkCorrelations(correlationsNode, s)
+
+	_, faultsNode := mappingValue(doc, "faults")
+	c.checkFaults(faultsNode, s.Faults)
+
+	// Phase durations should cover the scenario duration exactly
+	if ok && phases.ok && !scenarioUnlimited && len(s.Phases) > 0 {
+		if phases.unlimited {
+			c.add(phasesNode, SeverityWarning, "phases include an unlimited phase but scenario duration is %s", s.Duration)
+		} else if phases.total != scenarioDuration {
+			c.add(phasesNode, SeverityWarning, "phase durations sum to %s but scenario duration is %s", phases.total, scenarioDuration)
+		}
+	}
+}
+
+// checkDuration validates a duration value, returning the parsed duration, whether it
+// is unlimited, and whether it parsed
+func (c *checker) checkDuration(node *yaml.Node, value, field string) (time.Duration, bool, bool) {
+	if value == "" || value == "unlimited" {
+		return 0, true, true
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		c.add(node, SeverityError, "invalid %s %q (expected a positive duration like 30s or 5m, or unlimited)", field, value)
+		return 0, false, false
+	}
+	return d, false, true
+}
+
+func (c *checker) checkRate(node *yaml.Node, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := ParseRate(value); err != nil {
+		c.add(node, SeverityError, "invalid rate %q (expected a frequency like 1hz or 0.2hz)", value)
+	}
+}
+
+func (c *checker) checkRamp(node *yaml.Node, field, value string) {
+	if value == "" {
+		return
+	}
+	if d, err := time.ParseDuration(value); err != nil || d < 0 {
+		c.add(node, SeverityError, "invalid %s %q (expected a duration like 10s)", field, value)
+	}
+}
+
+func (c *checker) checkSignalName(node *yaml.Node, name string) bool {
+	known := c.validator.knownSignals
+	if len(known) == 0 || containsString(known, name) {
+		return true
+	}
+	c.add(node, SeverityError, "unknown signal %q (known signals: %s)", name, strings.Join(known, ", "))
+	return false
+}
+
+// checkNumeric reports baseline and noise values that are neither a number nor a list of numbers
+func (c *checker) checkNumeric(node *yaml.Node, field string) {
+	values := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		values = node.Content
+	}
+	for _, value := range values {
+		if value.Kind != yaml.ScalarNode || (value.Tag != "!!int" && value.Tag != "!!float") {
+			c.add(node, SeverityError, "invalid %s: expected a number or a list of numbers", field)
+			return
+		}
+	}
+}
+
+// checkSignalConfig validates the value fields of a signal config node
+func (c *checker) checkSignalConfig(node *yaml.Node) {
+	if _, value := mappingValue(node, "rate"); value != nil {
+		c.checkRate(value, value.Value)
+	}
+	for _, field := range []string{"baseline", "noise"} {
+		if _, value := mappingValue(node, field); value != nil {
+			c.checkNumeric(value, field)
+		}
+	}
+	for _, field := range []string{"ramp", "ramp_to_baseline"} {
+		if _, value := mappingValue(node, field); value != nil {
+			c.checkRamp(value, field, value.Value)
+		}
+	}
+	if _, morphology := mappingValue(node, "morphology"); morphology != nil {
+		if _, notch := mappingValue(morphology, "notch"); notch != nil {
+			if v, err := strconv.ParseFloat(notch.Value, 64); err == nil && (v <= 0 || v >= 1) {
+				c.add(notch, SeverityError, "invalid notch %s: expected a fraction of the beat between 0 and 1", notch.Value)
+			}
+		}
+	}
+}
+
+// BlockSignals are the numeric sampled signals that can be batched into blocks
+var BlockSignals = []string{"accel.xyz_mps2", "eda.us", "gyro.xyz_rps", "ppg.raw", "temp.skin_c"}
+
+// checkWaveform validates the block and waveform fields of a signal declaration
+func (c *checker) checkWaveform(name string, node *yaml.Node) {
+	if key, value := mappingValue(node, "block"); value != nil {
+		if !containsString(BlockSignals, name) {
+			c.add(key, SeverityError, "block output is not supported for %s (supported: %s)", name, strings.Join(BlockSignals, ", "))
+		} else if n, err := strconv.Atoi(value.Value); err == nil && n < 0 {
+			c.add(value, SeverityError, "invalid block %d: expected a sample count", n)
+		}
+	}
+	if key, _ := mappingValue(node, "morphology"); key != nil && name != "ppg.raw" {
+		c.add(key, SeverityError, "morphology is only supported for ppg.raw")
+	}
+	if name != "ppg.raw" {
+		return
+	}
+	if _, value := mappingValue(node, "rate"); value != nil {
+		if interval, err := ParseRate(value.Value); err == nil && (interval < 10*time.Millisecond || interval > 40*time.Millisecond) {
+			c.add(value, SeverityError, "invalid rate %q for ppg.raw (expected 25hz to 100hz)", value.Value)
+		}
+	}
+}
+
+// checkMarkov validates the state chain of a signal declaration, override or effect
+func (c *checker) checkMarkov(name string, node *yaml.Node) {
+	key, value := mappingValue(node, "markov")
+	if value == nil {
+		return
+	}
+	if !containsString(DiscreteSignals, name) {
+		c.add(key, SeverityError, "markov is only supported for discrete signals (%s)", strings.Join(DiscreteSignals, ", "))
+		return
+	}
+	var m Markov
+	if err := value.Decode(&m); err != nil {
+		return // reported by the typed decode
+	}
+	if err := m.Validate(); err != nil {
+		c.add(value, SeverityError, "invalid markov chain for %s: %v", name, err)
+	}
+}
+
+func (c *checker) checkSignals(node *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		c.checkSignalName(key, key.Value)
+		c.checkSignalConfig(value)
+		c.checkWaveform(key.Value, value)
+		c.checkMarkov(key.Value, value)
+	}
+}
+
+// phaseSpan is the combined duration of a list of phases
+type phaseSpan struct {
+	total     time.Duration
+	unlimited bool
+	ok        bool // false if any duration could not be determined
+}
+
+// checkTemplates validates each phase template, returning the span of every
+// template whose durations parsed
+func (c *checker) checkTemplates(node *yaml.Node, s *Scenario) map[string]phaseSpan {
+	spans := make(map[string]phaseSpan, len(s.Templates))
+	if node == nil || node.Kind != yaml.MappingNode {
+		return spans
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		phases := s.Templates[key.Value]
+		if len(phases) == 0 {
+			c.add(key, SeverityError, "template %q has no phases", key.Value)
+			continue
+		}
+		if span := c.checkPhaseList(value, phases, s, nil, key.Value); span.ok {
+			spans[key.Value] = span
+		}
+	}
+	return spans
+}
+
+// checkPhases validates phase durations, template references and overrides
+func (c *checker) checkPhases(node *yaml.Node, s *Scenario, templates map[string]phaseSpan) phaseSpan {
+	return c.checkPhaseList(node, s.Phases, s, templates, "")
+}
+
+// checkPhaseList validates a list of phases. template is the name of the template
+// being checked, or empty for the scenario's own phases; templates may not reference
+// other templates.
+func (c *checker) checkPhaseList(node *yaml.Node, phases []Phase, s *Scenario, templates map[string]phaseSpan, template string) phaseSpan {
+	span := phaseSpan{ok: true}
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return span
+	}
+
+	label := func(i int, phase Phase) string {
+		name := phase.Name
+		if name == "" {
+			name = phase.Template
+		}
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		if template != "" {
+			return fmt.Sprintf("template %q phase %q", template, name)
+		}
+		return fmt.Sprintf("phase %q", name)
+	}
+
+	for i, item := range node.Content {
+		if i >= len(phases) {
+			break
+		}
+		phase := phases[i]
+		_, durationNode := mappingValue(item, "duration")
+		if durationNode == nil {
+			durationNode = item
+		}
+
+		var d time.Duration
+		var phaseUnlimited, parsed bool
+		switch {
+		case phase.Template != "" && template != "":
+			c.add(item, SeverityError, "%s: templates cannot reference other templates", label(i, phase))
+			span.ok = false
+			continue
+		case phase.Template != "":
+			ref, known := templates[phase.Template]
+			_, declared := s.Templates[phase.Template]
+			switch {
+			case !declared && s.Extends == "" && len(s.Includes) == 0:
+				c.add(item, SeverityError, "%s: unknown template %q", label(i, phase), phase.Template)
+			case declared && phase.Duration != "" && len(s.Templates[phase.Template]) > 1:
+				c.add(durationNode, SeverityError, "%s: duration can only be set when the template has a single phase", label(i, phase))
+			}
+			if phase.Duration != "" {
+				d, phaseUnlimited, parsed = c.checkDuration(durationNode, phase.Duration, "phase duration")
+			} else {
+				d, phaseUnlimited, parsed = ref.total, ref.unlimited, known
+			}
+		default:
+			if phase.Name == "" {
+				c.add(item, SeverityError, "phase %d: name is required", i+1)
+			}
+			d, phaseUnlimited, parsed = c.checkDuration(durationNode, phase.Duration, "phase duration")
+		}
+
+		if phase.Repeat < 0 {
+			_, repeatNode := mappingValue(item, "repeat")
+			c.add(repeatNode, SeverityError, "%s: repeat must be positive", label(i, phase))
+		} else if phase.Repeat > 1 {
+			d *= time.Duration(phase.Repeat)
+		}
+
+		switch {
+		case !parsed:
+			span.ok = false
+		case phaseUnlimited:
+			if i < len(phases)-1 || phase.Repeat > 1 {
+				c.add(durationNode, SeverityError, "%s: only the last phase may be unlimited", label(i, phase))
+			}
+			span.unlimited = true
+		default:
+			span.total += d
+		}
+
+		_, faultsNode := mappingValue(item, "faults")
+		c.checkFaults(faultsNode, phase.Faults)
+
+		if phase.SleepStage != "" && !containsString(SleepStages, phase.SleepStage) {
+			_, stageNode := mappingValue(item, "sleep_stage")
+			c.add(stageNode, SeverityError, "%s: unknown sleep_stage %q (expected %s)", label(i, phase), phase.SleepStage, strings.Join(SleepStages, ", "))
+		}
+
+		_, overridesNode := mappingValue(item, "overrides")
+		if overridesNode == nil || overridesNode.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(overridesNode.Content); j += 2 {
+			key, value := overridesNode.Content[j], overridesNode.Content[j+1]
+			// Signals may be declared by the parent scenario; the Registry checks those when resolving
+			if c.checkSignalName(key, key.Value) && s.Extends == "" {
+				if _, declared := s.Signals[key.Value]; !declared {
+					c.add(key, SeverityError, "%s overrides signal %q which is not declared in signals", label(i, phase), key.Value)
+				}
+			}
+			c.checkSignalConfig(value)
+			c.checkMarkov(key.Value, value)
+		}
+	}
+
+	return span
+}
+
+// checkFaults validates the faults declared on a scenario or phase
+func (c *checker) checkFaults(node *yaml.Node, faults []FaultConfig) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, item := range node.Content {
+		if i >= len(faults) {
+			break
+		}
+		if err := faults[i].Validate(c.validator.knownSignals); err != nil {
+			c.add(item, SeverityError, "fault %d: %v", i+1, err)
+		}
+	}
+}
+
+func (c *checker) checkSources(node *yaml.Node, sources []SourceConfig) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	seen := make(map[string]bool, len(sources))
+	for i, item := range node.Content {
+		if i >= len(sources) {
+			break
+		}
+		source := sources[i]
+		if err := source.Validate(c.validator.knownSignals); err != nil {
+			c.add(item, SeverityError, "source %d: %v", i+1, err)
+			continue
+		}
+		if seen[source.ID] {
+			c.add(item, SeverityError, "source %s: duplicate id", source.ID)
+		}
+		seen[source.ID] = true
+	}
+}
+
+// checkEvents validates event schedules and effects. Fixed offsets past a finite
+// scenario duration never fire and are reported as warnings.
+func (c *checker) checkEvents(node *yaml.Node, s *Scenario, duration time.Duration, finite bool) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	seen := make(map[string]bool, len(s.Events))
+	for i, item := range node.Content {
+		if i >= len(s.Events) {
+			break
+		}
+		event := s.Events[i]
+		if event.Name == "" {
+			c.add(item, SeverityError, "event %d: name is required", i+1)
+		} else if seen[event.Name] {
+			c.add(item, SeverityError, "event %q: duplicate name", event.Name)
+		}
+		seen[event.Name] = true
+
+		if len(event.At) == 0 && event.Every == "" {
+			c.add(item, SeverityError, "event %q: at or every is required", event.Name)
+		}
+		if _, atNode := mappingValue(item, "at"); atNode != nil && atNode.Kind == yaml.SequenceNode {
+			for j, offsetNode := range atNode.Content {
+				if j >= len(event.At) {
+					break
+				}
+				d, err := time.ParseDuration(event.At[j])
+				switch {
+				case err != nil || d < 0:
+					c.add(offsetNode, SeverityError, "event %q: invalid at %q (expected a duration like 2m)", event.Name, event.At[j])
+				case finite && d >= duration:
+					c.add(offsetNode, SeverityWarning, "event %q: at %s is past the scenario duration %s", event.Name, event.At[j], s.Duration)
+				}
+			}
+		}
+		if _, everyNode := mappingValue(item, "every"); everyNode != nil {
+			if d, err := time.ParseDuration(event.Every); err != nil || d <= 0 {
+				c.add(everyNode, SeverityError, "event %q: invalid every %q (expected a positive duration like 2m)", event.Name, event.Every)
+			}
+		}
+		_, durationNode := mappingValue(item, "duration")
+		if durationNode == nil {
+			durationNode = item
+		}
+		if d, err := time.ParseDuration(event.Duration); err != nil || d <= 0 {
+			c.add(durationNode, SeverityError, "event %q: invalid duration %q (expected a positive duration like 5s)", event.Name, event.Duration)
+		}
+
+		_, effectsNode := mappingValue(item, "effects")
+		if effectsNode == nil || effectsNode.Kind != yaml.MappingNode || len(effectsNode.Content) == 0 {
+			c.add(item, SeverityError, "event %q: at least one effect is required", event.Name)
+			continue
+		}
+		for j := 0; j+1 < len(effectsNode.Content); j += 2 {
+			key, value := effectsNode.Content[j], effectsNode.Content[j+1]
+			if c.checkSignalName(key, key.Value) && s.Extends == "" {
+				if _, declared := s.Signals[key.Value]; !declared {
+					c.add(key, SeverityError, "event %q affects signal %q which is not declared in signals", event.Name, key.Value)
+				}
+			}
+			c.checkSignalConfig(value)
+			c.checkMarkov(key.Value, value)
+		}
+	}
+}
+
+// checkCorrelations validates correlation rules and the signals they reference
+func (c *checker) checkCorrelations(node *yaml.Node, s *Scenario) {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	for i, item := range node.Content {
+		if i >= len(s.Correlations) {
+			break
+		}
+		rule := s.Correlations[i]
+		if err := rule.Validate(); err != nil {
+			c.add(item, SeverityError, "correlation %s: %v", rule.Label(), err)
+		}
+		for _, field := range []string{"source", "target"} {
+			key, value := mappingValue(item, field)
+			if value == nil || value.Value == "" {
+				continue
+			}
+			if c.checkSignalName(value, value.Value) && s.Extends == "" {
+				if _, declared := s.Signals[value.Value]; !declared {
+					c.add(key, SeverityError, "correlation %s: %s %q is not declared in signals", rule.Label(), field, value.Value)
+				}
+			}
+		}
+	}
+}
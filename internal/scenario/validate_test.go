@@ -0,0 +1,279 @@
+package scenario
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testKnownSignals = []string{"accel.xyz_mps2", "app.activity", "eda.us", "ppg.hr_bpm", "ppg.raw"}
+
+func TestValidateValidScenario(t *testing.T) {
+	data := `
+name: ok
+duration: 1m
+default_rate: 50hz
+signals:
+  ppg.hr_bpm:
+    baseline: 70
+    noise: 2
+    rate: 1hz
+phases:
+  - name: rest
+    duration: 30s
+  - name: spike
+    duration: 30s
+    overrides:
+      ppg.hr_bpm:
+        add: 20
+        ramp: 10s
+`
+	scenario, issues := NewValidator(testKnownSignals).Validate("ok.yaml", []byte(data))
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues, got %v", issues)
+	}
+	if scenario == nil || scenario.Name != "ok" {
+		t.Fatalf("expected decoded scenario, got %+v", scenario)
+	}
+}
+
+func TestValidateIssues(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		line     int
+		severity string
+		contains string
+	}{
+		{
+			name:     "unknown field",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm:\n    basline: 70\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `unknown field "basline"`,
+		},
+		{
+			name:     "unknown signal",
+			data:     "name: x\nsignals:\n  ppg.hr_bmp:\n    baseline: 70\n",
+			line:     3,
+			severity: SeverityError,
+			contains: `unknown signal "ppg.hr_bmp"`,
+		},
+		{
+			name:     "invalid rate",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm:\n    rate: 50 hz\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `invalid rate "50 hz"`,
+		},
+		{
+			name:     "invalid duration",
+			data:     "name: x\nduration: 5 minutes\n",
+			line:     2,
+			severity: SeverityError,
+			contains: `invalid duration`,
+		},
+		{
+			name:     "non-numeric baseline",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm:\n    baseline: high\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `invalid baseline`,
+		},
+		{
+			name:     "block on a derived signal",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm:\n    block: 25\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `block output is not supported for ppg.hr_bpm`,
+		},
+		{
+			name:     "ppg.raw rate out of range",
+			data:     "name: x\nsignals:\n  ppg.raw:\n    rate: 250hz\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `expected 25hz to 100hz`,
+		},
+		{
+			name:     "notch outside the beat",
+			data:     "name: x\nsignals:\n  ppg.raw:\n    morphology:\n      notch: 1.5\n",
+			line:     5,
+			severity: SeverityError,
+			contains: `invalid notch`,
+		},
+		{
+			name:     "undeclared override",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm: {baseline: 70}\nphases:\n  - name: a\n    overrides:\n      eda.us: {add: 1}\n",
+			line:     7,
+			severity: SeverityError,
+			contains: `not declared in signals`,
+		},
+		{
+			name:     "unlimited phase before end",
+			data:     "name: x\nphases:\n  - name: a\n    duration: unlimited\n  - name: b\n    duration: 1m\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `only the last phase may be unlimited`,
+		},
+		{
+			name:     "phase sum mismatch",
+			data:     "name: x\nduration: 2m\nphases:\n  - name: a\n    duration: 1m\n",
+			line:     4,
+			severity: SeverityWarning,
+			contains: `phase durations sum to 1m0s`,
+		},
+		{
+			name:     "unknown template",
+			data:     "name: x\nphases:\n  - template: intervals\n",
+			line:     3,
+			severity: SeverityError,
+			contains: `unknown template "intervals"`,
+		},
+		{
+			name:     "repeated template sum",
+			data:     "name: x\nduration: 10m\ntemplates:\n  hiit:\n    - {name: on, duration: 1m}\n    - {name: off, duration: 1m}\nphases:\n  - template: hiit\n    repeat: 4\n",
+			line:     8,
+			severity: SeverityWarning,
+			contains: `phase durations sum to 8m0s`,
+		},
+		{
+			name:     "nested template",
+			data:     "name: x\ntemplates:\n  a:\n    - {name: one, duration: 1m}\n  b:\n    - template: a\n",
+			line:     6,
+			severity: SeverityError,
+			contains: `templates cannot reference other templates`,
+		},
+		{
+			name:     "event without schedule",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm: {baseline: 70}\nevents:\n  - name: blip\n    duration: 5s\n    effects:\n      ppg.hr_bpm: {add: 10}\n",
+			line:     5,
+			severity: SeverityError,
+			contains: `at or every is required`,
+		},
+		{
+			name:     "event offset past end",
+			data:     "name: x\nduration: 1m\nsignals:\n  ppg.hr_bpm: {baseline: 70}\nevents:\n  - name: blip\n    at: [2m]\n    duration: 5s\n    effects:\n      ppg.hr_bpm: {add: 10}\n",
+			line:     7,
+			severity: SeverityWarning,
+			contains: `past the scenario duration`,
+		},
+		{
+			name:     "event undeclared effect",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm: {baseline: 70}\nevents:\n  - name: blip\n    every: 1m\n    duration: 5s\n    effects:\n      eda.us: {add: 1}\n",
+			line:     9,
+			severity: SeverityError,
+			contains: `affects signal "eda.us" which is not declared`,
+		},
+		{
+			name:     "markov on a numeric signal",
+			data:     "name: x\nsignals:\n  eda.us:\n    markov:\n      dwell: {low: 1m}\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `markov is only supported for discrete signals`,
+		},
+		{
+			name:     "markov transition to an undeclared state",
+			data:     "name: x\nsignals:\n  app.activity:\n    markov:\n      dwell: {typing: 20s, scrolling: 40s}\n      transitions:\n        typing: {scrolling: 1}\n        scrolling: {reading: 1}\n",
+			line:     5,
+			severity: SeverityError,
+			contains: `scrolling transitions to "reading", which has no dwell`,
+		},
+		{
+			name:     "phase fault with unknown type",
+			data:     "name: x\nphases:\n  - name: a\n    duration: 1m\n    faults:\n      - type: melt\n",
+			line:     6,
+			severity: SeverityError,
+			contains: `fault 1: unknown type "melt"`,
+		},
+		{
+			name:     "fault episode without duration",
+			data:     "name: x\nfaults:\n  - type: dropout\n    every: 2m\n",
+			line:     3,
+			severity: SeverityError,
+			contains: `invalid duration ""`,
+		},
+		{
+			name:     "unknown sleep stage",
+			data:     "name: x\nphases:\n  - name: a\n    duration: 1m\n    sleep_stage: nap\n",
+			line:     5,
+			severity: SeverityError,
+			contains: `unknown sleep_stage "nap"`,
+		},
+		{
+			name:     "unknown correlation transform",
+			data:     "name: x\nsignals:\n  eda.us: {}\n  ppg.hr_bpm: {}\ncorrelations:\n  - source: eda.us\n    target: ppg.hr_bpm\n    transform: log\n",
+			line:     6,
+			severity: SeverityError,
+			contains: `unknown transform "log"`,
+		},
+		{
+			name:     "lag without delay",
+			data:     "name: x\nsignals:\n  eda.us: {}\n  ppg.hr_bpm: {}\ncorrelations:\n  - source: eda.us\n    target: ppg.hr_bpm\n    transform: lag\n",
+			line:     6,
+			severity: SeverityError,
+			contains: `invalid delay ""`,
+		},
+		{
+			name:     "correlation with undeclared source",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm: {}\ncorrelations:\n  - source: eda.us\n    target: ppg.hr_bpm\n    transform: linear\n",
+			line:     5,
+			severity: SeverityError,
+			contains: `not declared in signals`,
+		},
+		{
+			name:     "type error",
+			data:     "name: x\nsignals:\n  ppg.hr_bpm:\n    add: lots\n",
+			line:     4,
+			severity: SeverityError,
+			contains: `cannot unmarshal`,
+		},
+		{
+			name:     "syntax error",
+			data:     "name: [x\n",
+			line:     1,
+			severity: SeverityError,
+			contains: `did not find expected`,
+		},
+	}
+
+	for _, test := range tests {
+		_, issues := NewValidator(testKnownSignals).Validate("test.yaml", []byte(test.data))
+		if len(issues) != 1 {
+			t.Errorf("%s: expected 1 issue, got %v", test.name, issues)
+			continue
+		}
+		issue := issues[0]
+		if issue.Line != test.line {
+			t.Errorf("%s: expected line %d, got %d (%s)", test.name, test.line, issue.Line, issue.Message)
+		}
+		if issue.Severity != test.severity {
+			t.Errorf("%s: expected severity %s, got %s", test.name, test.severity, issue.Severity)
+		}
+		if !strings.Contains(issue.Message, test.contains) {
+			t.Errorf("%s: expected message containing %q, got %q", test.name, test.contains, issue.Message)
+		}
+	}
+}
+
+func TestLoadFromFileRejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.yaml")
+	writeFile(t, path, "name: bad\nsignals:\n  ppg.hr_bpm:\n    basline: 70\n")
+
+	registry := NewRegistry()
+	err := registry.LoadFromFile(path)
+	if err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if !strings.Contains(err.Error(), "bad.yaml:4:5") {
+		t.Errorf("expected error with line and column, got %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
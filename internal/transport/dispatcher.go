@@ -7,21 +7,21 @@ import (
 	"sync/atomic"
 )
 
-// Dispatcher copies payloads from one source to multiple subscribers.
+// Dispatcher copies records from one source to multiple subscribers.
 // When a subscriber's buffer is full, events are dropped to prevent blocking
 // the generator. Dropped events are logged and counted for monitoring.
 type Dispatcher struct {
-	source       <-chan []byte
-	subscribers  []chan []byte
+	source       <-chan Record
+	subscribers  []chan Record
 	bufferSize   int
 	mu           sync.Mutex
 	droppedTotal int64 // atomic counter for total dropped events
 }
 
-func NewDispatcher(source <-chan []byte, bufferSize int) *Dispatcher {
+func NewDispatcher(source <-chan Record, bufferSize int) *Dispatcher {
 	return &Dispatcher{
 		source:      source,
-		subscribers: make([]chan []byte, 0),
+		subscribers: make([]chan Record, 0),
 		bufferSize:  bufferSize,
 	}
 }
@@ -29,8 +29,8 @@ func NewDispatcher(source <-chan []byte, bufferSize int) *Dispatcher {
 // Subscribe returns a channel that receives copies of all source events.
 // Each subscriber gets its own buffered channel with the configured buffer size.
 // Subscribers should be added before calling Run() to ensure they receive all events.
-func (d *Dispatcher) Subscribe() <-chan []byte {
-	ch := make(chan []byte, d.bufferSize)
+func (d *Dispatcher) Subscribe() <-chan Record {
+	ch := make(chan Record, d.bufferSize)
 	d.mu.Lock()
 	d.subscribers = append(d.subscribers, ch)
 	d.mu.Unlock()
@@ -59,16 +59,16 @@ func (d *Dispatcher) Run(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case data, ok := <-d.source:
+		case record, ok := <-d.source:
 			if !ok {
 				return
 			}
-			d.dispatch(data, ctx)
+			d.dispatch(record, ctx)
 		}
 	}
 }
 
-func (d *Dispatcher) dispatch(data []byte, ctx context.Context) {
+func (d *Dispatcher) dispatch(record Record, ctx context.Context) {
 	d.mu.Lock()
 	subs := d.subscribers // Copy slice reference to minimize lock time
 	d.mu.Unlock()
@@ -76,7 +76,7 @@ func (d *Dispatcher) dispatch(data []byte, ctx context.Context) {
 	dropped := 0
 	for _, sub := range subs {
 		select {
-		case sub <- data:
+		case sub <- record:
 			// Successfully sent
 		case <-ctx.Done():
 			return
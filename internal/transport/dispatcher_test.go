@@ -8,7 +8,7 @@ import (
 )
 
 func TestDispatcher_SingleSubscriber(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 10)
 	subscriber := dispatcher.Subscribe()
 
@@ -18,7 +18,7 @@ func TestDispatcher_SingleSubscriber(t *testing.T) {
 	go dispatcher.Run(ctx)
 
 	for i := 0; i < 5; i++ {
-		source <- []byte(string(rune('A' + i)))
+		source <- Record{Data: []byte(string(rune('A' + i)))}
 	}
 	close(source)
 
@@ -35,7 +35,7 @@ func TestDispatcher_SingleSubscriber(t *testing.T) {
 }
 
 func TestDispatcher_MultipleSubscribers(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 10)
 
 	sub1 := dispatcher.Subscribe()
@@ -48,7 +48,7 @@ func TestDispatcher_MultipleSubscribers(t *testing.T) {
 
 	numPackets := 10
 	for i := 0; i < numPackets; i++ {
-		source <- []byte(string(rune('A' + i)))
+		source <- Record{Data: []byte(string(rune('A' + i)))}
 	}
 	close(source)
 
@@ -81,7 +81,7 @@ func TestDispatcher_MultipleSubscribers(t *testing.T) {
 }
 
 func TestDispatcher_SubscribersReceiveSameData(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 10)
 
 	sub1 := dispatcher.Subscribe()
@@ -98,18 +98,18 @@ func TestDispatcher_SubscribersReceiveSameData(t *testing.T) {
 		[]byte("packet-3"),
 	}
 	for _, d := range data {
-		source <- d
+		source <- Record{Data: d}
 	}
 	close(source)
 
 	time.Sleep(10 * time.Millisecond)
 
 	var received1, received2 [][]byte
-	for d := range sub1 {
-		received1 = append(received1, d)
+	for record := range sub1 {
+		received1 = append(received1, record.Data)
 	}
-	for d := range sub2 {
-		received2 = append(received2, d)
+	for record := range sub2 {
+		received2 = append(received2, record.Data)
 	}
 
 	for i, d := range data {
@@ -123,7 +123,7 @@ func TestDispatcher_SubscribersReceiveSameData(t *testing.T) {
 }
 
 func TestDispatcher_ContextCancellation(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 10)
 
 	sub := dispatcher.Subscribe()
@@ -136,7 +136,7 @@ func TestDispatcher_ContextCancellation(t *testing.T) {
 		close(done)
 	}()
 
-	source <- []byte("before-cancel")
+	source <- Record{Data: []byte("before-cancel")}
 	time.Sleep(5 * time.Millisecond)
 	cancel()
 
@@ -158,7 +158,7 @@ func TestDispatcher_ContextCancellation(t *testing.T) {
 }
 
 func TestDispatcher_SlowSubscriber(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 2) // Small buffer to trigger drops
 
 	fastSub := dispatcher.Subscribe()
@@ -192,7 +192,7 @@ func TestDispatcher_SlowSubscriber(t *testing.T) {
 
 	numPackets := 10
 	for i := 0; i < numPackets; i++ {
-		source <- []byte("data")
+		source <- Record{Data: []byte("data")}
 		time.Sleep(1 * time.Millisecond)
 	}
 	close(source)
@@ -215,7 +215,7 @@ func TestDispatcher_SlowSubscriber(t *testing.T) {
 }
 
 func TestDispatcher_GetSubscriberCount(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 10)
 
 	if dispatcher.GetSubscriberCount() != 0 {
@@ -245,7 +245,7 @@ func TestDispatcher_GetSubscriberCount(t *testing.T) {
 }
 
 func TestDispatcher_GetDroppedCount(t *testing.T) {
-	source := make(chan []byte, 10)
+	source := make(chan Record, 10)
 	dispatcher := NewDispatcher(source, 1) // Very small buffer to force drops
 
 	sub := dispatcher.Subscribe()
@@ -256,7 +256,7 @@ func TestDispatcher_GetDroppedCount(t *testing.T) {
 	go dispatcher.Run(ctx)
 
 	for i := 0; i < 10; i++ {
-		source <- []byte("data")
+		source <- Record{Data: []byte("data")}
 	}
 	close(source)
 
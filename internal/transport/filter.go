@@ -0,0 +1,203 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Record types a client can subscribe to
+const (
+	RecordEvent  = "event"  // raw per-signal HSI input events
+	RecordVendor = "vendor" // aggregated vendor payloads
+	RecordHSI    = "hsi"    // Flux HSI records
+)
+
+var recordTypes = []string{RecordEvent, RecordVendor, RecordHSI}
+
+// Filter selects the records a client receives; an empty filter receives
+// everything. Signal, source and side criteria only match raw events, so a
+// filter with any of them drops vendor payloads and HSI records unless Types
+// names them.
+type Filter struct {
+	Signals []string `json:"signals,omitempty"` // signal name globs, e.g. ppg.*
+	Sources []string `json:"sources,omitempty"` // source ID globs
+	Sides   []string `json:"sides,omitempty"`   // left or right
+	Types   []string `json:"types,omitempty"`   // event, vendor or hsi
+}
+
+// ParseFilter parses a comma-separated key=value filter such as
+// "signals=ppg.hr_bpm+ppg.hrv_*,types=event", with + separating list values
+func ParseFilter(spec string) (Filter, error) {
+	var f Filter
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return f, fmt.Errorf("invalid filter field %q (expected key=value)", part)
+		}
+		if err := f.set(strings.TrimSpace(key), strings.Split(value, "+")); err != nil {
+			return f, err
+		}
+	}
+	return f, f.Validate()
+}
+
+// FilterFromQuery reads a filter from URL query parameters such as
+// ?signals=ppg.hr_bpm,ppg.hrv_*&types=event; parameters may also be repeated
+func FilterFromQuery(query url.Values) (Filter, error) {
+	var f Filter
+	for key, values := range query {
+		var list []string
+		for _, value := range values {
+			list = append(list, strings.Split(value, ",")...)
+		}
+		if err := f.set(key, list); err != nil {
+			return f, err
+		}
+	}
+	return f, f.Validate()
+}
+
+func (f *Filter) set(key string, values []string) error {
+	var list *[]string
+	switch key {
+	case "signals":
+		list = &f.Signals
+	case "sources":
+		list = &f.Sources
+	case "sides":
+		list = &f.Sides
+	case "types":
+		list = &f.Types
+	default:
+		return fmt.Errorf("unknown filter field %q (expected signals, sources, sides or types)", key)
+	}
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			*list = append(*list, value)
+		}
+	}
+	return nil
+}
+
+// Validate checks the globs, sides and record types
+func (f Filter) Validate() error {
+	for _, pattern := range append(append([]string{}, f.Signals...), f.Sources...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, side := range f.Sides {
+		if side != "left" && side != "right" {
+			return fmt.Errorf("invalid side %q (expected left or right)", side)
+		}
+	}
+	for _, kind := range f.Types {
+		if !contains(recordTypes, kind) {
+			return fmt.Errorf("invalid type %q (expected %s)", kind, strings.Join(recordTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// Empty reports whether the filter lets every record through
+func (f Filter) Empty() bool {
+	return len(f.Signals) == 0 && len(f.Sources) == 0 && len(f.Sides) == 0 && len(f.Types) == 0
+}
+
+func (f Filter) String() string {
+	if f.Empty() {
+		return "all"
+	}
+	var parts []string
+	for _, field := range []struct {
+		key    string
+		values []string
+	}{{"signals", f.Signals}, {"sources", f.Sources}, {"sides", f.Sides}, {"types", f.Types}} {
+		if len(field.values) > 0 {
+			parts = append(parts, field.key+"="+strings.Join(field.values, "+"))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (f Filter) match(info recordInfo) bool {
+	if len(f.Types) > 0 && !contains(f.Types, info.kind) {
+		return false
+	}
+	if info.kind != RecordEvent {
+		return len(f.Types) > 0 || (len(f.Signals) == 0 && len(f.Sources) == 0 && len(f.Sides) == 0)
+	}
+	return matchAny(f.Signals, info.signal) && matchAny(f.Sources, info.source) &&
+		(len(f.Sides) == 0 || contains(f.Sides, info.side))
+}
+
+// matchAny reports whether name matches one of the globs, or there are none
+func matchAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// recordInfo is what filters see of a record
+type recordInfo struct {
+	kind   string
+	signal string
+	source string
+	side   string
+}
+
+// classifyRecord tells raw events, HSI records and vendor payloads apart.
+// Flux emits HSI as an array of records carrying hsi_version; anything that
+// is neither, including Apple Health XML, is a vendor payload.
+func classifyRecord(data []byte) recordInfo {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return recordInfo{kind: RecordHSI}
+	}
+
+	var probe struct {
+		SchemaVersion string `json:"schema_version"`
+		HSIVersion    string `json:"hsi_version"`
+		Source        struct {
+			ID   string `json:"id"`
+			Side string `json:"side"`
+		} `json:"source"`
+		Signal struct {
+			Name string `json:"name"`
+		} `json:"signal"`
+	}
+	if err := json.Unmarshal(trimmed, &probe); err != nil {
+		return recordInfo{kind: RecordVendor}
+	}
+	switch {
+	case probe.HSIVersion != "":
+		return recordInfo{kind: RecordHSI}
+	case probe.SchemaVersion != "" && probe.Signal.Name != "":
+		return recordInfo{kind: RecordEvent, signal: probe.Signal.Name, source: probe.Source.ID, side: probe.Source.Side}
+	default:
+		return recordInfo{kind: RecordVendor}
+	}
+}
@@ -0,0 +1,80 @@
+package transport
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const (
+	hrEvent    = `{"schema_version":"hsi.input.v1","event_id":"1","ts":"2025-01-01T00:00:00Z","source":{"type":"wearable","id":"watch-left","side":"left"},"signal":{"name":"ppg.hr_bpm","unit":"bpm","value":72,"quality":0.9}}`
+	accelEvent = `{"schema_version":"hsi.input.v1","event_id":"2","ts":"2025-01-01T00:00:00Z","source":{"type":"phone","id":"phone-01"},"signal":{"name":"accel.xyz_mps2","unit":"m/s2","value":[0,0,9.8],"quality":0.9}}`
+	whoopJSON  = `{"cycle":[{"id":1}],"recovery":[],"sleep":[]}`
+	hsiJSON    = `[{"hsi_version":"1.0.0","producer":{"name":"synheart-flux"}}]`
+	appleXML   = `<?xml version="1.0" encoding="UTF-8"?><HealthData/>`
+)
+
+func TestFilterMatch(t *testing.T) {
+	tests := []struct {
+		spec    string
+		matches []string
+	}{
+		{spec: "", matches: []string{hrEvent, accelEvent, whoopJSON, hsiJSON, appleXML}},
+		{spec: "signals=ppg.hr_bpm", matches: []string{hrEvent}},
+		{spec: "signals=ppg.*+accel.*", matches: []string{hrEvent, accelEvent}},
+		{spec: "sources=watch-*", matches: []string{hrEvent}},
+		{spec: "sides=right", matches: nil},
+		{spec: "types=vendor", matches: []string{whoopJSON, appleXML}},
+		{spec: "types=hsi", matches: []string{hsiJSON}},
+		{spec: "signals=ppg.hr_bpm,types=event+hsi", matches: []string{hrEvent, hsiJSON}},
+	}
+	records := []string{hrEvent, accelEvent, whoopJSON, hsiJSON, appleXML}
+
+	for _, test := range tests {
+		filter, err := ParseFilter(test.spec)
+		if err != nil {
+			t.Fatalf("%q: %v", test.spec, err)
+		}
+		var got []string
+		for _, record := range records {
+			m := outbound{Record: Record{Data: []byte(record)}}
+			if m.match(filter) {
+				got = append(got, record)
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(test.matches, "\n") {
+			t.Errorf("%q: expected %v, got %v", test.spec, test.matches, got)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := map[string]string{
+		"signals":            "expected key=value",
+		"signal=ppg.hr_bpm":  `unknown filter field "signal"`,
+		"signals=ppg.[":      `invalid pattern "ppg.["`,
+		"sides=middle":       `invalid side "middle"`,
+		"types=event+events": `invalid type "events"`,
+	}
+	for spec, want := range tests {
+		if _, err := ParseFilter(spec); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error containing %q, got %v", spec, want, err)
+		}
+	}
+}
+
+func TestFilterFromQuery(t *testing.T) {
+	query, _ := url.ParseQuery("signals=ppg.hr_bpm,ppg.hrv_*&signals=eda.us&sides=left&types=event")
+	filter, err := FilterFromQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filter.String(); got != "signals=ppg.hr_bpm+ppg.hrv_*+eda.us,sides=left,types=event" {
+		t.Errorf("unexpected filter %s", got)
+	}
+
+	query, _ = url.ParseQuery("types=raw")
+	if _, err := FilterFromQuery(query); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
@@ -0,0 +1,198 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/synheart/synheart-cli/internal/encoding"
+)
+
+// MQTTTopicRoot is the first level of every topic the publisher uses
+const MQTTTopicRoot = "synheart"
+
+// Session states published to the status topic
+const (
+	MQTTStatusRunning = "running" // published on connect
+	MQTTStatusEnded   = "ended"   // published when the session ends cleanly
+	MQTTStatusLost    = "lost"    // the will, published by the broker if the connection drops
+)
+
+// mqttTimeout bounds connecting, and publishing the final status on close
+const mqttTimeout = 5 * time.Second
+
+// MQTTConfig configures an MQTTPublisher
+type MQTTConfig struct {
+	Broker   string // broker URL, e.g. tcp://127.0.0.1:1883
+	ClientID string // default synheart-<run_id>
+	RunID    string // second level of every topic
+	QoS      byte   // 0, 1 or 2
+	Retain   bool   // publish records as retained last-value messages
+}
+
+// MQTTPublisher publishes records to an MQTT broker. Raw events go to
+// synheart/<run_id>/<source_id>/<signal>, vendor payloads to
+// synheart/<run_id>/vendor and Flux HSI records to synheart/<run_id>/hsi,
+// so subscribers select streams with topic wildcards. The session's state is
+// kept retained at synheart/<run_id>/status, with a will that reports it lost
+// if the publisher disappears.
+type MQTTPublisher struct {
+	config   MQTTConfig
+	client   mqtt.Client
+	encoding encoding.Format
+	mu       sync.RWMutex
+}
+
+// NewMQTTPublisher creates a publisher; call Connect before broadcasting
+func NewMQTTPublisher(config MQTTConfig) (*MQTTPublisher, error) {
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("invalid QoS %d (expected 0, 1 or 2)", config.QoS)
+	}
+	if config.RunID == "" {
+		return nil, fmt.Errorf("a run ID is required for MQTT topics")
+	}
+	if config.ClientID == "" {
+		config.ClientID = "synheart-" + config.RunID
+	}
+	return &MQTTPublisher{config: config, encoding: encoding.FormatJSON}, nil
+}
+
+// SetEncoding sets the encoding of raw events. Vendor payloads and HSI
+// records are always published as text.
+func (p *MQTTPublisher) SetEncoding(format encoding.Format) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.encoding = format
+}
+
+// StatusTopic returns the topic holding the session's state
+func (p *MQTTPublisher) StatusTopic() string {
+	return mqttTopic(p.config.RunID, "status")
+}
+
+// Connect connects to the broker and publishes the running status. The
+// client reconnects on its own if the connection drops later.
+func (p *MQTTPublisher) Connect() error {
+	opts := mqtt.NewClientOptions().
+		AddBroker(p.config.Broker).
+		SetClientID(p.config.ClientID).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetConnectTimeout(mqttTimeout).
+		SetBinaryWill(p.StatusTopic(), p.status(MQTTStatusLost), p.config.QoS, true).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			c.Publish(p.StatusTopic(), p.config.QoS, true, p.status(MQTTStatusRunning))
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Printf("MQTT connection lost: %v", err)
+		})
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(mqttTimeout) {
+		return fmt.Errorf("failed to connect to %s: timed out", p.config.Broker)
+	}
+	if err := token.Error(); err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", p.config.Broker, err)
+	}
+
+	p.mu.Lock()
+	p.client = client
+	p.mu.Unlock()
+	log.Printf("MQTT publisher connected to %s", p.config.Broker)
+	return nil
+}
+
+// Close publishes the ended status and disconnects. If the connection is
+// already down, the broker has published the lost status instead.
+func (p *MQTTPublisher) Close() error {
+	p.mu.Lock()
+	client := p.client
+	p.client = nil
+	p.mu.Unlock()
+	if client == nil {
+		return nil
+	}
+	if !client.IsConnectionOpen() {
+		client.Disconnect(0)
+		return nil
+	}
+
+	token := client.Publish(p.StatusTopic(), p.config.QoS, true, p.status(MQTTStatusEnded))
+	token.WaitTimeout(mqttTimeout)
+	client.Disconnect(250)
+	return token.Error()
+}
+
+// Broadcast publishes JSON data to its topic
+func (p *MQTTPublisher) Broadcast(data []byte) error {
+	return p.BroadcastRecord(Record{Data: data})
+}
+
+// BroadcastRecord publishes a record to its topic
+func (p *MQTTPublisher) BroadcastRecord(record Record) error {
+	p.mu.RLock()
+	client, format := p.client, p.encoding
+	p.mu.RUnlock()
+	if client == nil {
+		return fmt.Errorf("not connected")
+	}
+
+	m := outbound{Record: record}
+	payload, _ := m.encode(format)
+	client.Publish(p.topic(m.classify()), p.config.QoS, p.config.Retain, payload)
+	return nil
+}
+
+// BroadcastFromChannel reads data from a channel and publishes it
+func (p *MQTTPublisher) BroadcastFromChannel(ctx context.Context, dataStream <-chan Record) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case record, ok := <-dataStream:
+			if !ok {
+				return nil // Channel closed
+			}
+			if err := p.BroadcastRecord(record); err != nil {
+				log.Printf("MQTT publish error: %v", err)
+			}
+		}
+	}
+}
+
+// GetAddress returns the broker URL
+func (p *MQTTPublisher) GetAddress() string {
+	return p.config.Broker
+}
+
+func (p *MQTTPublisher) topic(info recordInfo) string {
+	if info.kind != RecordEvent {
+		return mqttTopic(p.config.RunID, info.kind)
+	}
+	return mqttTopic(p.config.RunID, info.source, info.signal)
+}
+
+func (p *MQTTPublisher) status(state string) []byte {
+	data, _ := json.Marshal(map[string]string{"run_id": p.config.RunID, "status": state})
+	return data
+}
+
+// mqttTopic joins topic levels under the root, replacing the characters MQTT
+// reserves in topic names
+func mqttTopic(levels ...string) string {
+	escape := strings.NewReplacer("/", "_", "+", "_", "#", "_")
+	parts := []string{MQTTTopicRoot}
+	for _, level := range levels {
+		if level == "" {
+			level = "unknown"
+		}
+		parts = append(parts, escape.Replace(level))
+	}
+	return strings.Join(parts, "/")
+}
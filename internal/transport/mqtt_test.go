@@ -0,0 +1,148 @@
+package transport
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/synheart/synheart-cli/internal/broker"
+	"github.com/synheart/synheart-cli/internal/encoding"
+)
+
+func startMQTT(t *testing.T, port int, config MQTTConfig) (*broker.Broker, *MQTTPublisher, mqtt.Client) {
+	t.Helper()
+	b := broker.NewBroker("127.0.0.1", port)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go b.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	config.Broker = b.GetAddress()
+	publisher, err := NewMQTTPublisher(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(b.GetAddress()).SetClientID("subscriber"))
+	if token := sub.Connect(); !token.WaitTimeout(time.Second) || token.Error() != nil {
+		t.Fatalf("failed to connect subscriber: %v", token.Error())
+	}
+	t.Cleanup(func() { sub.Disconnect(100) })
+	return b, publisher, sub
+}
+
+func collect(t *testing.T, c mqtt.Client, filters ...string) <-chan mqtt.Message {
+	t.Helper()
+	received := make(chan mqtt.Message, 16)
+	subscriptions := make(map[string]byte)
+	for _, filter := range filters {
+		subscriptions[filter] = 1
+	}
+	token := c.SubscribeMultiple(subscriptions, func(_ mqtt.Client, m mqtt.Message) { received <- m })
+	if !token.WaitTimeout(time.Second) || token.Error() != nil {
+		t.Fatalf("failed to subscribe: %v", token.Error())
+	}
+	return received
+}
+
+func next(t *testing.T, received <-chan mqtt.Message) mqtt.Message {
+	t.Helper()
+	select {
+	case m := <-received:
+		return m
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return nil
+	}
+}
+
+func TestMQTTPublisher_Topics(t *testing.T) {
+	_, publisher, sub := startMQTT(t, 19894, MQTTConfig{RunID: "run-1", QoS: 1})
+	defer publisher.Close()
+
+	status := collect(t, sub, "synheart/run-1/status")
+	if m := next(t, status); string(m.Payload()) != `{"run_id":"run-1","status":"running"}` || !m.Retained() {
+		t.Errorf("expected the retained running status, got %s", m.Payload())
+	}
+
+	hr := collect(t, sub, "synheart/+/+/ppg.hr_bpm")
+	records := collect(t, sub, "synheart/run-1/vendor", "synheart/run-1/hsi")
+
+	publisher.Broadcast([]byte(accelEvent))
+	publisher.Broadcast([]byte(hrEvent))
+	publisher.Broadcast([]byte(whoopJSON))
+	publisher.Broadcast([]byte(hsiJSON))
+
+	if m := next(t, hr); m.Topic() != "synheart/run-1/watch-left/ppg.hr_bpm" || string(m.Payload()) != hrEvent {
+		t.Errorf("expected the heart rate event, got %s %s", m.Topic(), m.Payload())
+	}
+	for _, want := range []struct{ topic, payload string }{
+		{"synheart/run-1/vendor", whoopJSON},
+		{"synheart/run-1/hsi", hsiJSON},
+	} {
+		if m := next(t, records); m.Topic() != want.topic || string(m.Payload()) != want.payload {
+			t.Errorf("expected %s, got %s %s", want.topic, m.Topic(), m.Payload())
+		}
+	}
+	select {
+	case m := <-hr:
+		t.Errorf("unexpected message on %s", m.Topic())
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// A clean close leaves the ended status retained
+	publisher.Close()
+	if m := next(t, status); string(m.Payload()) != `{"run_id":"run-1","status":"ended"}` {
+		t.Errorf("expected the ended status, got %s", m.Payload())
+	}
+}
+
+func TestMQTTPublisher_RetainAndEncoding(t *testing.T) {
+	_, publisher, sub := startMQTT(t, 19895, MQTTConfig{RunID: "run-2", Retain: true})
+	defer publisher.Close()
+	publisher.SetEncoding(encoding.FormatCBOR)
+
+	publisher.Broadcast([]byte(hrEvent))
+	time.Sleep(100 * time.Millisecond)
+
+	// A late subscriber gets the last value
+	m := next(t, collect(t, sub, "synheart/run-2/+/ppg.hr_bpm"))
+	if !m.Retained() {
+		t.Error("expected a retained message")
+	}
+	event, err := encoding.NewDecoder(encoding.FormatCBOR).Decode(m.Payload())
+	if err != nil || event.Signal.Name != "ppg.hr_bpm" {
+		t.Errorf("expected the CBOR heart rate event, got %+v (%v)", event, err)
+	}
+}
+
+func TestMQTTPublisher_Will(t *testing.T) {
+	b, publisher, sub := startMQTT(t, 19896, MQTTConfig{RunID: "run-3", QoS: 1})
+	defer publisher.Close()
+	status := collect(t, sub, "synheart/run-3/status")
+	next(t, status) // running
+
+	// Connecting with the publisher's client ID drops its connection, which
+	// makes the broker publish the will
+	other := mqtt.NewClient(mqtt.NewClientOptions().AddBroker(b.GetAddress()).SetClientID("synheart-run-3"))
+	if token := other.Connect(); !token.WaitTimeout(time.Second) || token.Error() != nil {
+		t.Fatal(token.Error())
+	}
+	defer other.Disconnect(100)
+	if m := next(t, status); string(m.Payload()) != `{"run_id":"run-3","status":"lost"}` {
+		t.Errorf("expected the lost status, got %s", m.Payload())
+	}
+}
+
+func TestMQTTTopic(t *testing.T) {
+	if got := mqttTopic("run", "phone/1", "a+b#"); got != "synheart/run/phone_1/a_b_" {
+		t.Errorf("unexpected topic %s", got)
+	}
+	if _, err := NewMQTTPublisher(MQTTConfig{RunID: "run", QoS: 3}); err == nil {
+		t.Error("expected an error for QoS 3")
+	}
+}
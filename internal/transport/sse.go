@@ -12,7 +12,7 @@ import (
 type SSEServer struct {
 	host    string
 	port    int
-	clients map[chan []byte]bool
+	clients map[chan []byte]Filter
 	mu      sync.RWMutex
 	server  *http.Server
 }
@@ -22,7 +22,7 @@ func NewSSEServer(host string, port int) *SSEServer {
 	return &SSEServer{
 		host:    host,
 		port:    port,
-		clients: make(map[chan []byte]bool),
+		clients: make(map[chan []byte]Filter),
 	}
 }
 
@@ -59,14 +59,21 @@ func (s *SSEServer) Start(ctx context.Context) error {
 func (s *SSEServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	fmt.Fprintf(w, "Synheart SSE Server\n\nEndpoint: http://%s:%d/hsi/sse\n", s.host, s.port)
+	fmt.Fprintf(w, "Filters: http://%s:%d/hsi/sse?signals=ppg.hr_bpm&types=event\n", s.host, s.port)
 }
 
+// handleSSE streams records to a client, filtered by its query parameters
 func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		http.Error(w, "SSE not supported", http.StatusInternalServerError)
 		return
 	}
+	filter, err := FilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid filter: %v", err), http.StatusBadRequest)
+		return
+	}
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -74,7 +81,7 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	clientChan := make(chan []byte, 100)
-	s.addClient(clientChan)
+	s.addClient(clientChan, filter)
 	defer s.removeClient(clientChan)
 
 	log.Printf("SSE client connected (total: %d)", s.GetClientCount())
@@ -93,9 +100,9 @@ func (s *SSEServer) handleSSE(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *SSEServer) addClient(ch chan []byte) {
+func (s *SSEServer) addClient(ch chan []byte, filter Filter) {
 	s.mu.Lock()
-	s.clients[ch] = true
+	s.clients[ch] = filter
 	s.mu.Unlock()
 }
 
@@ -109,8 +116,13 @@ func (s *SSEServer) removeClient(ch chan []byte) {
 	}
 }
 
-// Broadcast sends data to all connected clients
+// Broadcast sends JSON data to the connected clients whose filter matches it
 func (s *SSEServer) Broadcast(data []byte) error {
+	return s.BroadcastRecord(Record{Data: data})
+}
+
+// BroadcastRecord sends a record to the connected clients whose filter matches it
+func (s *SSEServer) BroadcastRecord(record Record) error {
 	if s.GetClientCount() == 0 {
 		return nil
 	}
@@ -118,9 +130,13 @@ func (s *SSEServer) Broadcast(data []byte) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for ch := range s.clients {
+	m := outbound{Record: record}
+	for ch, filter := range s.clients {
+		if !m.match(filter) {
+			continue
+		}
 		select {
-		case ch <- data:
+		case ch <- record.Data:
 		default:
 		}
 	}
@@ -128,16 +144,16 @@ func (s *SSEServer) Broadcast(data []byte) error {
 }
 
 // BroadcastFromChannel reads data from a channel and broadcasts it
-func (s *SSEServer) BroadcastFromChannel(ctx context.Context, dataStream <-chan []byte) error {
+func (s *SSEServer) BroadcastFromChannel(ctx context.Context, dataStream <-chan Record) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case data, ok := <-dataStream:
+		case record, ok := <-dataStream:
 			if !ok {
 				return nil
 			}
-			if err := s.Broadcast(data); err != nil {
+			if err := s.BroadcastRecord(record); err != nil {
 				log.Printf("Broadcast error: %v", err)
 			}
 		}
@@ -157,7 +173,7 @@ func (s *SSEServer) Shutdown() error {
 	for ch := range s.clients {
 		close(ch)
 	}
-	s.clients = make(map[chan []byte]bool)
+	s.clients = make(map[chan []byte]Filter)
 	s.mu.Unlock()
 
 	if s.server != nil {
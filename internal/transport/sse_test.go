@@ -157,3 +157,45 @@ func TestSSEServer_PortConflict(t *testing.T) {
 		t.Error("should fail fast")
 	}
 }
+
+func TestSSEServer_QueryFilter(t *testing.T) {
+	server := NewSSEServer("127.0.0.1", 19881)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	resp, err := http.Get("http://127.0.0.1:19881/hsi/sse?sides=middle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid filter, got %d", resp.StatusCode)
+	}
+
+	reqCtx, reqCancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer reqCancel()
+	req, _ := http.NewRequestWithContext(reqCtx, "GET", "http://127.0.0.1:19881/hsi/sse?signals=ppg.*&types=event", nil)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		server.Broadcast([]byte(whoopJSON))
+		server.Broadcast([]byte(accelEvent))
+		server.Broadcast([]byte(hrEvent))
+	}()
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	buf := make([]byte, 2048)
+	n, _ := resp.Body.Read(buf)
+	if got := string(buf[:n]); got != "data: "+hrEvent+"\n\n" {
+		t.Errorf("expected only the heart rate event, got: %s", got)
+	}
+}
@@ -5,28 +5,51 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
 )
 
-// UDPServer broadcasts events via UDP
+// udpClient is a registered address and the records it subscribed to
+type udpClient struct {
+	addr   *net.UDPAddr
+	filter Filter
+}
+
+// UDPServer broadcasts events via UDP, one record per datagram. With a binary
+// encoding, raw events are sent encoded, and their first byte identifies the
+// format: 0x0a for a protobuf hsi.Event (the schema_version tag), 0xa7 for
+// CBOR and 0x87 for MessagePack (a map of the envelope's seven fields).
+// Vendor payloads and HSI records stay JSON or XML text, starting with '{',
+// '[' or '<'.
 type UDPServer struct {
-	host    string
-	port    int
-	conn    *net.UDPConn
-	clients map[string]*net.UDPAddr
-	mu      sync.RWMutex
+	host     string
+	port     int
+	conn     *net.UDPConn
+	clients  map[string]*udpClient
+	encoding encoding.Format
+	mu       sync.RWMutex
 }
 
 // NewUDPServer creates a new UDP server
 func NewUDPServer(host string, port int) *UDPServer {
 	return &UDPServer{
-		host:    host,
-		port:    port,
-		clients: make(map[string]*net.UDPAddr),
+		host:     host,
+		port:     port,
+		clients:  make(map[string]*udpClient),
+		encoding: encoding.FormatJSON,
 	}
 }
 
+// SetEncoding sets the encoding of raw events
+func (s *UDPServer) SetEncoding(format encoding.Format) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encoding = format
+}
+
 // Start starts the UDP server
 func (s *UDPServer) Start(ctx context.Context) error {
 	addr, err := net.ResolveUDPAddr("udp", fmt.Sprintf("%s:%d", s.host, s.port))
@@ -67,30 +90,45 @@ func (s *UDPServer) readLoop(ctx context.Context) {
 	}
 }
 
+// handleMessage registers clients. "subscribe" receives everything and
+// "subscribe signals=ppg.hr_bpm,types=event" only the matching records; a
+// subscribe with an invalid filter is ignored.
 func (s *UDPServer) handleMessage(msg string, addr *net.UDPAddr) {
 	key := addr.String()
 
+	command, spec, _ := strings.Cut(strings.TrimSpace(msg), " ")
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	switch msg {
+	switch command {
 	case "subscribe":
-		s.clients[key] = addr
-		log.Printf("UDP client subscribed: %s (total: %d)", key, len(s.clients))
+		filter, err := ParseFilter(spec)
+		if err != nil {
+			log.Printf("UDP client %s sent an invalid filter: %v", key, err)
+			return
+		}
+		s.clients[key] = &udpClient{addr: addr, filter: filter}
+		log.Printf("UDP client subscribed: %s to %s (total: %d)", key, filter, len(s.clients))
 	case "unsubscribe":
 		delete(s.clients, key)
 		log.Printf("UDP client unsubscribed: %s (total: %d)", key, len(s.clients))
 	default:
 		// Any message registers client
 		if _, exists := s.clients[key]; !exists {
-			s.clients[key] = addr
+			s.clients[key] = &udpClient{addr: addr}
 			log.Printf("UDP client registered: %s (total: %d)", key, len(s.clients))
 		}
 	}
 }
 
-// Broadcast sends data to all registered clients
+// Broadcast sends JSON data to the registered clients whose filter matches it
 func (s *UDPServer) Broadcast(data []byte) error {
+	return s.BroadcastRecord(Record{Data: data})
+}
+
+// BroadcastRecord sends a record to the registered clients whose filter matches it
+func (s *UDPServer) BroadcastRecord(record Record) error {
 	if s.GetClientCount() == 0 {
 		return nil
 	}
@@ -98,23 +136,27 @@ func (s *UDPServer) Broadcast(data []byte) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	for _, addr := range s.clients {
-		s.conn.WriteToUDP(data, addr)
+	m := outbound{Record: record}
+	for _, client := range s.clients {
+		if m.match(client.filter) {
+			payload, _ := m.encode(s.encoding)
+			s.conn.WriteToUDP(payload, client.addr)
+		}
 	}
 	return nil
 }
 
 // BroadcastFromChannel reads data and broadcasts it
-func (s *UDPServer) BroadcastFromChannel(ctx context.Context, dataStream <-chan []byte) error {
+func (s *UDPServer) BroadcastFromChannel(ctx context.Context, dataStream <-chan Record) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case data, ok := <-dataStream:
+		case record, ok := <-dataStream:
 			if !ok {
 				return nil
 			}
-			s.Broadcast(data)
+			s.BroadcastRecord(record)
 		}
 	}
 }
@@ -6,6 +6,10 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/proto/hsi"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestUDPServer_Broadcast(t *testing.T) {
@@ -86,3 +90,83 @@ func TestUDPServer_Address(t *testing.T) {
 		t.Errorf("wrong address: %s", addr)
 	}
 }
+
+func TestUDPServer_SubscribeFilter(t *testing.T) {
+	server := NewUDPServer("127.0.0.1", 19880)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := net.Dial("udp", "127.0.0.1:19880")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Write([]byte("subscribe signals=ppg.hr_bpm"))
+	time.Sleep(100 * time.Millisecond)
+
+	server.Broadcast([]byte(accelEvent))
+	server.Broadcast([]byte(whoopJSON))
+	server.Broadcast([]byte(hrEvent))
+
+	buf := make([]byte, 2048)
+	client.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if string(buf[:n]) != hrEvent {
+		t.Errorf("expected only the heart rate event, got: %s", string(buf[:n]))
+	}
+
+	// An invalid filter leaves the subscription as it was
+	client.Write([]byte("subscribe types=raw"))
+	time.Sleep(100 * time.Millisecond)
+	if server.GetClientCount() != 1 {
+		t.Errorf("expected 1 client, got %d", server.GetClientCount())
+	}
+}
+
+func TestUDPServer_ProtobufDatagrams(t *testing.T) {
+	server := NewUDPServer("127.0.0.1", 19884)
+	server.SetEncoding(encoding.FormatProtobuf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	client, err := net.Dial("udp", "127.0.0.1:19884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Write([]byte("subscribe"))
+	time.Sleep(100 * time.Millisecond)
+
+	server.Broadcast([]byte(hrEvent))
+	server.Broadcast([]byte(hsiJSON))
+
+	buf := make([]byte, 2048)
+	client.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+	n, err := client.Read(buf)
+	if err != nil {
+		t.Fatalf("failed to receive: %v", err)
+	}
+	if buf[0] != 0x0a {
+		t.Errorf("expected a protobuf datagram starting with 0x0a, got %#x", buf[0])
+	}
+	var event hsi.Event
+	if err := proto.Unmarshal(buf[:n], &event); err != nil || event.Signal.GetValue().GetScalar() != 72 {
+		t.Errorf("expected the heart rate event, got %v (%v)", &event, err)
+	}
+
+	n, err = client.Read(buf)
+	if err != nil || string(buf[:n]) != hsiJSON {
+		t.Errorf("expected the HSI record as JSON, got %s (%v)", buf[:n], err)
+	}
+}
@@ -1,7 +1,9 @@
 package transport
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,42 +11,84 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/synheart/synheart-cli/internal/encoding"
 )
 
 var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool {
 		return true // Allow all origins for local development
 	},
+	Subprotocols: []string{SubprotocolJSON, SubprotocolProtobuf, SubprotocolCBOR, SubprotocolMsgPack},
+}
+
+// message is a queued WebSocket frame
+type message struct {
+	data   []byte
+	binary bool
 }
 
 type client struct {
-	conn *websocket.Conn
-	send chan []byte
+	conn     *websocket.Conn
+	send     chan message
+	encoding encoding.Format
+	filter   Filter // guarded by the server's mu
+}
+
+// subscribeMessage is sent by clients to replace their filter; a subscribe
+// without criteria receives everything again
+type subscribeMessage struct {
+	Type string `json:"type"` // "subscribe"
+	Filter
 }
 
 // WebSocketServer broadcasts events to WebSocket clients
 type WebSocketServer struct {
-	host    string
-	port    int
-	clients map[*client]bool
-	mu      sync.RWMutex
-	server  *http.Server
+	host     string
+	port     int
+	clients  map[*client]bool
+	handlers map[string]http.Handler
+	encoding encoding.Format
+	mu       sync.RWMutex
+	server   *http.Server
 }
 
 // NewWebSocketServer creates a new WebSocket server
 func NewWebSocketServer(host string, port int) *WebSocketServer {
 	return &WebSocketServer{
-		host:    host,
-		port:    port,
-		clients: make(map[*client]bool),
+		host:     host,
+		port:     port,
+		clients:  make(map[*client]bool),
+		handlers: make(map[string]http.Handler),
+		encoding: encoding.FormatJSON,
 	}
 }
 
+// SetEncoding sets the encoding of raw events for clients that don't negotiate
+// one with a subprotocol. Binary encodings are sent as binary frames.
+func (s *WebSocketServer) SetEncoding(format encoding.Format) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encoding = format
+}
+
+// Handle registers an additional HTTP handler served alongside the WebSocket endpoint.
+// Handlers must be registered before Start is called.
+func (s *WebSocketServer) Handle(pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[pattern] = handler
+}
+
 // Start starts the WebSocket server
 func (s *WebSocketServer) Start(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/hsi", s.handleWebSocket)
 	mux.HandleFunc("/", s.handleRoot)
+	s.mu.RLock()
+	for pattern, handler := range s.handlers {
+		mux.Handle(pattern, handler)
+	}
+	s.mu.RUnlock()
 
 	s.server = &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", s.host, s.port),
@@ -69,6 +113,8 @@ func (s *WebSocketServer) handleRoot(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	fmt.Fprintf(w, "Synheart Mock Data Server\n\n")
 	fmt.Fprintf(w, "WebSocket endpoint: ws://%s:%d/hsi\n", s.host, s.port)
+	fmt.Fprintf(w, "Subprotocols: %s (JSON text frames), %s, %s, %s (binary frames for raw events)\n", SubprotocolJSON, SubprotocolProtobuf, SubprotocolCBOR, SubprotocolMsgPack)
+	fmt.Fprintf(w, "Filters: ws://%s:%d/hsi?signals=ppg.hr_bpm&types=event, or send {\"type\":\"subscribe\",\"signals\":[\"ppg.*\"]}\n", s.host, s.port)
 	fmt.Fprintf(w, "Connected clients: %d\n", s.GetClientCount())
 }
 
@@ -79,36 +125,50 @@ func (s *WebSocketServer) writePump(c *client) {
 
 	for msg := range c.send {
 		msgType := websocket.TextMessage
+		if msg.binary {
+			msgType = websocket.BinaryMessage
+		}
 
 		// set a deadline If the network is too slow this will time out
 		// and clean up the connection instead of hanging forever
 		c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
 
-		if err := c.conn.WriteMessage(msgType, msg); err != nil {
+		if err := c.conn.WriteMessage(msgType, msg.data); err != nil {
 			return
 		}
 	}
 }
 
-// handleWebSocket handles WebSocket connections
+// handleWebSocket handles WebSocket connections. The query parameters set the
+// client's initial filter, and a subprotocol its encoding.
 func (s *WebSocketServer) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	filter, err := FilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid filter: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade connection: %v", err)
 		return
 	}
 
+	s.mu.Lock()
 	c := &client{
-		conn: conn,
-		send: make(chan []byte, 256),
+		conn:     conn,
+		send:     make(chan message, 256),
+		encoding: s.encoding,
+		filter:   filter,
+	}
+	if format, ok := subprotocols[conn.Subprotocol()]; ok {
+		c.encoding = format
 	}
-
-	s.mu.Lock()
 	s.clients[c] = true
 	clientCount := len(s.clients)
 	s.mu.Unlock()
 
-	log.Printf("Client connected from %s (total: %d)", r.RemoteAddr, clientCount)
+	log.Printf("Client connected from %s, %s encoding (total: %d)", r.RemoteAddr, c.encoding, clientCount)
 
 	go s.writePump(c)
 
@@ -125,17 +185,64 @@ func (s *WebSocketServer) handleWebSocket(w http.ResponseWriter, r *http.Request
 		log.Printf("Client disconnected (total: %d)", currentCount)
 	}()
 
-	// Keep connection alive and handle client messages
+	// Keep connection alive and handle subscribe messages
 	for {
-		_, _, err := conn.ReadMessage()
+		_, msg, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		s.handleMessage(c, msg)
 	}
 }
 
-// Broadcast sends data to all connected clients
+// handleMessage applies a subscribe message and acknowledges it with the
+// client's new filter, or replies with an error. Unknown fields are rejected,
+// like unknown query parameters, so a misspelt criterion never widens the filter.
+func (s *WebSocketServer) handleMessage(c *client, msg []byte) {
+	var sub subscribeMessage
+	decoder := json.NewDecoder(bytes.NewReader(msg))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&sub)
+	if err != nil {
+		err = fmt.Errorf("invalid message: %w", err)
+	}
+	if err == nil && sub.Type != "subscribe" {
+		err = fmt.Errorf("unknown message type %q (expected subscribe)", sub.Type)
+	}
+	if err == nil {
+		err = sub.Filter.Validate()
+	}
+
+	var reply []byte
+	if err != nil {
+		reply, _ = json.Marshal(map[string]string{"type": "error", "error": err.Error()})
+	} else {
+		reply, _ = json.Marshal(map[string]interface{}{"type": "subscribed", "filter": sub.Filter})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Shutdown may already have closed the send channel
+	if _, ok := s.clients[c]; !ok {
+		return
+	}
+	if err == nil {
+		c.filter = sub.Filter
+		log.Printf("Client subscribed to %s", c.filter)
+	}
+	select {
+	case c.send <- message{data: reply}:
+	default:
+	}
+}
+
+// Broadcast sends JSON data to the connected clients whose filter matches it
 func (s *WebSocketServer) Broadcast(data []byte) error {
+	return s.BroadcastRecord(Record{Data: data})
+}
+
+// BroadcastRecord sends a record to the connected clients whose filter matches it
+func (s *WebSocketServer) BroadcastRecord(record Record) error {
 	if s.GetClientCount() == 0 {
 		return nil
 	}
@@ -143,9 +250,14 @@ func (s *WebSocketServer) Broadcast(data []byte) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	m := outbound{Record: record}
 	for client := range s.clients {
+		if !m.match(client.filter) {
+			continue
+		}
+		payload, binary := m.encode(client.encoding)
 		select {
-		case client.send <- data:
+		case client.send <- message{data: payload, binary: binary}:
 		default:
 			log.Printf("Buffer overflow for client! Dropping real-time packet.")
 		}
@@ -155,16 +267,16 @@ func (s *WebSocketServer) Broadcast(data []byte) error {
 }
 
 // BroadcastFromChannel reads data from a channel and broadcasts it
-func (s *WebSocketServer) BroadcastFromChannel(ctx context.Context, dataStream <-chan []byte) error {
+func (s *WebSocketServer) BroadcastFromChannel(ctx context.Context, dataStream <-chan Record) error {
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case data, ok := <-dataStream:
+		case record, ok := <-dataStream:
 			if !ok {
 				return nil // Channel closed
 			}
-			if err := s.Broadcast(data); err != nil {
+			if err := s.BroadcastRecord(record); err != nil {
 				log.Printf("Broadcast error: %v", err)
 			}
 		}
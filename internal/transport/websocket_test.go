@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/proto/hsi"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWebSocketServer_Subscribe(t *testing.T) {
+	server := NewWebSocketServer("127.0.0.1", 19882)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:19882/hsi?types=bogus", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid filter, got %v", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:19882/hsi?types=vendor", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	time.Sleep(100 * time.Millisecond)
+
+	// The query filter applies until the client subscribes
+	server.Broadcast([]byte(hrEvent))
+	server.Broadcast([]byte(whoopJSON))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != whoopJSON {
+		t.Fatalf("expected the vendor payload, got %s (%v)", msg, err)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"subscribe","signals":["ppg.hr_bpm"],"sides":["left"]}`))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ack struct {
+		Type   string `json:"type"`
+		Filter Filter `json:"filter"`
+	}
+	if err := json.Unmarshal(msg, &ack); err != nil || ack.Type != "subscribed" || ack.Filter.String() != "signals=ppg.hr_bpm,sides=left" {
+		t.Fatalf("unexpected ack %s", msg)
+	}
+
+	server.Broadcast([]byte(whoopJSON))
+	server.Broadcast([]byte(accelEvent))
+	server.Broadcast([]byte(hrEvent))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != hrEvent {
+		t.Fatalf("expected the heart rate event, got %s (%v)", msg, err)
+	}
+
+	for _, bad := range []string{`{"type":"subscribe","types":["raw"]}`, `{"type":"subscribe","signal":["ppg.hr_bpm"]}`} {
+		conn.WriteMessage(websocket.TextMessage, []byte(bad))
+		if _, msg, err := conn.ReadMessage(); err != nil || !strings.Contains(string(msg), `"type":"error"`) {
+			t.Fatalf("%s: expected an error reply, got %s (%v)", bad, msg, err)
+		}
+	}
+
+	// The filter is unchanged by the rejected messages
+	server.Broadcast([]byte(accelEvent))
+	server.Broadcast([]byte(hrEvent))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != hrEvent {
+		t.Fatalf("expected the heart rate event, got %s (%v)", msg, err)
+	}
+}
+
+func TestWebSocketServer_Encoding(t *testing.T) {
+	server := NewWebSocketServer("127.0.0.1", 19883)
+	server.SetEncoding(encoding.FormatProtobuf)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	binary, _, err := websocket.DefaultDialer.Dial("ws://127.0.0.1:19883/hsi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer binary.Close()
+	dialer := websocket.Dialer{Subprotocols: []string{SubprotocolJSON}}
+	text, _, err := dialer.Dial("ws://127.0.0.1:19883/hsi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer text.Close()
+	if text.Subprotocol() != SubprotocolJSON {
+		t.Errorf("expected subprotocol %s, got %q", SubprotocolJSON, text.Subprotocol())
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	server.Broadcast([]byte(hrEvent))
+	server.Broadcast([]byte(whoopJSON))
+
+	binary.SetReadDeadline(time.Now().Add(time.Second))
+	kind, msg, err := binary.ReadMessage()
+	if err != nil || kind != websocket.BinaryMessage {
+		t.Fatalf("expected a binary frame, got %d (%v)", kind, err)
+	}
+	var event hsi.Event
+	if err := proto.Unmarshal(msg, &event); err != nil || event.Signal.GetName() != "ppg.hr_bpm" || event.Source.GetSide() != "left" {
+		t.Errorf("expected the heart rate event, got %v (%v)", &event, err)
+	}
+	if kind, msg, err := binary.ReadMessage(); err != nil || kind != websocket.TextMessage || string(msg) != whoopJSON {
+		t.Errorf("expected the vendor payload as text, got %d %s (%v)", kind, msg, err)
+	}
+
+	text.SetReadDeadline(time.Now().Add(time.Second))
+	if kind, msg, err := text.ReadMessage(); err != nil || kind != websocket.TextMessage || string(msg) != hrEvent {
+		t.Errorf("expected the JSON event, got %d %s (%v)", kind, msg, err)
+	}
+}
+
+func TestWebSocketServer_Subprotocols(t *testing.T) {
+	server := NewWebSocketServer("127.0.0.1", 19885)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	conns := map[encoding.Format]*websocket.Conn{}
+	for protocol, format := range subprotocols {
+		if format == encoding.FormatJSON {
+			continue
+		}
+		dialer := websocket.Dialer{Subprotocols: []string{protocol}}
+		conn, _, err := dialer.Dial("ws://127.0.0.1:19885/hsi", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if conn.Subprotocol() != protocol {
+			t.Errorf("expected subprotocol %s, got %q", protocol, conn.Subprotocol())
+		}
+		conns[format] = conn
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	server.Broadcast([]byte(hrEvent))
+
+	for format, conn := range conns {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		kind, msg, err := conn.ReadMessage()
+		if err != nil || kind != websocket.BinaryMessage {
+			t.Errorf("%s: expected a binary frame, got %d (%v)", format, kind, err)
+			continue
+		}
+		event, err := encoding.NewDecoder(format).Decode(msg)
+		if err != nil || event.Signal.Name != "ppg.hr_bpm" || event.Signal.Value != 72.0 {
+			t.Errorf("%s: expected the heart rate event, got %+v (%v)", format, event, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"github.com/synheart/synheart-cli/internal/encoding"
+	"github.com/synheart/synheart-cli/internal/models"
+)
+
+// WebSocket subprotocols a client can request to pick its encoding per
+// connection, overriding the server's
+const (
+	SubprotocolJSON     = "hsi.json"
+	SubprotocolProtobuf = "hsi.protobuf"
+	SubprotocolCBOR     = "hsi.cbor"
+	SubprotocolMsgPack  = "hsi.msgpack"
+)
+
+var subprotocols = map[string]encoding.Format{
+	SubprotocolJSON:     encoding.FormatJSON,
+	SubprotocolProtobuf: encoding.FormatProtobuf,
+	SubprotocolCBOR:     encoding.FormatCBOR,
+	SubprotocolMsgPack:  encoding.FormatMsgPack,
+}
+
+// Record is a record on its way to the transports: its JSON text and, for
+// raw events, the event itself, so binary formats are encoded from the event
+// rather than by parsing the text back
+type Record struct {
+	Data  []byte
+	Event *models.Event // nil for vendor payloads and HSI records
+}
+
+// outbound is a record being broadcast. Raw events are re-encoded for binary
+// clients, classified once and encoded at most once per format however many
+// clients receive them. Vendor payloads and HSI records have no binary schema
+// and are always sent as text.
+type outbound struct {
+	Record
+	info    *recordInfo
+	encoded map[encoding.Format][]byte
+}
+
+// match reports whether a client with the filter receives the record
+func (o *outbound) match(f Filter) bool {
+	if f.Empty() {
+		return true
+	}
+	return f.match(o.classify())
+}
+
+func (o *outbound) classify() recordInfo {
+	if o.info == nil {
+		var info recordInfo
+		if e := o.Event; e != nil {
+			info = recordInfo{kind: RecordEvent, signal: e.Signal.Name, source: e.Source.ID}
+			if e.Source.Side != nil {
+				info.side = *e.Source.Side
+			}
+		} else {
+			info = classifyRecord(o.Data)
+		}
+		o.info = &info
+	}
+	return *o.info
+}
+
+// encode returns the record in the format and whether it is binary. Records
+// that cannot be encoded in a binary format fall back to their JSON text.
+func (o *outbound) encode(format encoding.Format) ([]byte, bool) {
+	if !format.Binary() || o.classify().kind != RecordEvent {
+		return o.Data, false
+	}
+	data, ok := o.encoded[format]
+	if !ok {
+		if o.encoded == nil {
+			o.encoded = make(map[encoding.Format][]byte)
+		}
+		data, _ = o.encodeEvent(format) // nil on failure, remembered as such
+		o.encoded[format] = data
+	}
+	if data == nil {
+		return o.Data, false
+	}
+	return data, true
+}
+
+// encodeEvent encodes the record's event, decoding it from the JSON text
+// first for records that arrived without one, such as Broadcast's
+func (o *outbound) encodeEvent(format encoding.Format) ([]byte, error) {
+	if o.Event == nil {
+		event, err := encoding.NewJSONDecoder().Decode(o.Data)
+		if err != nil {
+			return nil, err
+		}
+		o.Event = &event
+	}
+	return encoding.NewEncoder(format).Encode(*o.Event)
+}
@@ -0,0 +1,39 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/synheart/synheart-cli/internal/encoding"
+)
+
+func TestOutboundUsesCarriedEvent(t *testing.T) {
+	event, err := encoding.NewJSONDecoder().Decode([]byte(hrEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event.Signal.Value = 80.0
+
+	// The event wins over the JSON text for classification and binary encoding
+	m := outbound{Record: Record{Data: []byte(accelEvent), Event: &event}}
+	if info := m.classify(); info.signal != "ppg.hr_bpm" || info.source != "watch-left" || info.side != "left" {
+		t.Errorf("expected the record classified from its event, got %+v", info)
+	}
+	data, binary := m.encode(encoding.FormatCBOR)
+	if !binary {
+		t.Fatal("expected a binary encoding")
+	}
+	decoded, err := encoding.NewDecoder(encoding.FormatCBOR).Decode(data)
+	if err != nil || decoded.Signal.Value != 80.0 {
+		t.Errorf("expected the carried event encoded, got %+v (%v)", decoded.Signal, err)
+	}
+	if data, binary := m.encode(encoding.FormatJSON); binary || string(data) != accelEvent {
+		t.Errorf("expected the JSON text as is, got %s", data)
+	}
+
+	// Records without an event are decoded from their text
+	m = outbound{Record: Record{Data: []byte(hrEvent)}}
+	data, _ = m.encode(encoding.FormatMsgPack)
+	if decoded, err := encoding.NewDecoder(encoding.FormatMsgPack).Decode(data); err != nil || decoded.EventID != "1" {
+		t.Errorf("expected the decoded heart rate event, got %+v (%v)", decoded, err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package scenarios embeds the built-in scenario definitions so released
+// binaries work without a scenarios directory on disk.
+package scenarios
+
+import "embed"
+
+// FS holds the built-in scenario YAML files
+//
+//go:embed *.yaml
+var FS embed.FS